@@ -0,0 +1,46 @@
+package attendance
+
+import "time"
+
+type RecordAttendanceRequest struct {
+	ClassTypeID *int32 `json:"class_type_id"`
+	SessionID   *int32 `json:"session_id"`
+	// AttendedAt defaults to now, it lets staff log a visit after the fact
+	AttendedAt *time.Time `json:"attended_at"`
+}
+
+type AttendanceResponse struct {
+	ID            int32     `json:"id"`
+	UserID        int32     `json:"user_id"`
+	SessionID     *int32    `json:"session_id"`
+	ClassTypeID   *int32    `json:"class_type_id"`
+	ClassTypeName *string   `json:"class_type_name,omitempty"`
+	Source        string    `json:"source"`
+	CheckedInAt   time.Time `json:"checked_in_at"`
+}
+
+type PeriodCountResponse struct {
+	// Start is the first day of the week or month, in the requested timezone
+	Start       string `json:"start"`
+	Attendances int64  `json:"attendances"`
+}
+
+type ClassTypeCountResponse struct {
+	ClassTypeID   *int32  `json:"class_type_id"`
+	ClassTypeName *string `json:"class_type_name"`
+	Attendances   int64   `json:"attendances"`
+}
+
+type AttendanceSummaryResponse struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Timezone string    `json:"timezone"`
+	Total    int       `json:"total"`
+	// Streaks are counted in consecutive weeks with at least one attendance
+	CurrentStreakWeeks int32                    `json:"current_streak_weeks"`
+	LongestStreakWeeks int32                    `json:"longest_streak_weeks"`
+	ByWeek             []PeriodCountResponse    `json:"by_week"`
+	ByMonth            []PeriodCountResponse    `json:"by_month"`
+	ByClassType        []ClassTypeCountResponse `json:"by_class_type"`
+	Attendances        []AttendanceResponse     `json:"attendances"`
+}
@@ -0,0 +1,194 @@
+package attendance
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/schedule"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const dateLayout = "2006-01-02"
+
+type AttendanceHandlers struct {
+	aService IAttendanceService
+}
+
+func NewAttendanceHandlers(aService IAttendanceService) *AttendanceHandlers {
+	return &AttendanceHandlers{
+		aService: aService,
+	}
+}
+
+func (h *AttendanceHandlers) GetMyAttendance(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writeAttendance(w, r, userID)
+}
+
+func (h *AttendanceHandlers) GetUserAttendance(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writeAttendance(w, r, userID)
+}
+
+func (h *AttendanceHandlers) RecordAttendance(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var recordRequest RecordAttendanceRequest
+	if err := validate.DecodeJSON(w, r, &recordRequest); err != nil {
+		return err
+	}
+
+	attendance, err := h.aService.RecordAttendance(r.Context(), NewAttendance{
+		UserID:      userID,
+		ClassTypeID: recordRequest.ClassTypeID,
+		SessionID:   recordRequest.SessionID,
+		AttendedAt:  recordRequest.AttendedAt,
+		RecordedBy:  staffID,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidAttendance) || errors.Is(err, ErrAttendanceInFuture) {
+			return apperror.BadRequest(err.Error())
+		}
+		if errors.Is(err, credits.ErrInsufficientCredits) {
+			return apperror.New(http.StatusConflict, "insufficient_credits", "Member has no credits left").Wrap(err)
+		}
+		return err
+	}
+
+	resp := AttendanceResponse{
+		ID:          attendance.ID,
+		UserID:      attendance.UserID,
+		SessionID:   fromInt4(attendance.SessionID),
+		ClassTypeID: fromInt4(attendance.ClassTypeID),
+		Source:      attendance.Source,
+		CheckedInAt: attendance.CheckedInAt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+// writeAttendance responds with a member's attendance summary for the
+// from/to/tz query parameters. The range defaults to the current month.
+func (h *AttendanceHandlers) writeAttendance(w http.ResponseWriter, r *http.Request, userID int32) error {
+	timezone := "UTC"
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		timezone = tz
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return apperror.BadRequest("Timezone is invalid")
+	}
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = schedule.ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("from must be an RFC 3339 time or a date")
+		}
+	}
+
+	to := from.AddDate(0, 1, 0)
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = schedule.ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("to must be an RFC 3339 time or a date")
+		}
+	}
+
+	summary, err := h.aService.GetAttendance(r.Context(), userID, from, to, timezone)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			return apperror.New(http.StatusBadRequest, "invalid_range", "to must be after from").Wrap(err)
+		}
+		return err
+	}
+
+	resp := AttendanceSummaryResponse{
+		From:               from,
+		To:                 to,
+		Timezone:           timezone,
+		Total:              len(summary.Log),
+		CurrentStreakWeeks: summary.CurrentStreak,
+		LongestStreakWeeks: summary.LongestStreak,
+		ByWeek:             make([]PeriodCountResponse, 0, len(summary.ByWeek)),
+		ByMonth:            make([]PeriodCountResponse, 0, len(summary.ByMonth)),
+		ByClassType:        make([]ClassTypeCountResponse, 0, len(summary.ByClassType)),
+		Attendances:        make([]AttendanceResponse, 0, len(summary.Log)),
+	}
+	for _, week := range summary.ByWeek {
+		resp.ByWeek = append(resp.ByWeek, PeriodCountResponse{
+			Start:       week.Week.Format(dateLayout),
+			Attendances: week.Attendances,
+		})
+	}
+	for _, month := range summary.ByMonth {
+		resp.ByMonth = append(resp.ByMonth, PeriodCountResponse{
+			Start:       month.Month.Format(dateLayout),
+			Attendances: month.Attendances,
+		})
+	}
+	for _, classType := range summary.ByClassType {
+		resp.ByClassType = append(resp.ByClassType, ClassTypeCountResponse{
+			ClassTypeID:   fromInt4(classType.ClassTypeID),
+			ClassTypeName: fromText(classType.ClassTypeName),
+			Attendances:   classType.Attendances,
+		})
+	}
+	for _, a := range summary.Log {
+		resp.Attendances = append(resp.Attendances, toAttendanceResponse(a))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func toAttendanceResponse(a repository.GetAttendanceLogRow) AttendanceResponse {
+	return AttendanceResponse{
+		ID:            a.ID,
+		UserID:        a.UserID,
+		SessionID:     fromInt4(a.SessionID),
+		ClassTypeID:   fromInt4(a.ClassTypeID),
+		ClassTypeName: fromText(a.ClassTypeName),
+		Source:        a.Source,
+		CheckedInAt:   a.CheckedInAt,
+	}
+}
+
+func fromInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func fromText(v pgtype.Text) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
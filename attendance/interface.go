@@ -0,0 +1,13 @@
+package attendance
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IAttendanceService interface {
+	GetAttendance(ctx context.Context, userID int32, from, to time.Time, timezone string) (*Summary, error)
+	RecordAttendance(ctx context.Context, attendance NewAttendance) (*repository.Attendance, error)
+}
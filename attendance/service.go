@@ -0,0 +1,177 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/checkins"
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NewAttendance is a class visit logged by staff.
+type NewAttendance struct {
+	UserID      int32
+	ClassTypeID *int32
+	SessionID   *int32
+	AttendedAt  *time.Time
+	RecordedBy  int32
+}
+
+// Summary is a member's attendance log for a date range together with its
+// aggregates. Streaks are computed over the whole history.
+type Summary struct {
+	Log           []repository.GetAttendanceLogRow
+	ByWeek        []repository.GetAttendanceCountsByWeekRow
+	ByMonth       []repository.GetAttendanceCountsByMonthRow
+	ByClassType   []repository.GetAttendanceCountsByClassTypeRow
+	CurrentStreak int32
+	LongestStreak int32
+}
+
+type AttendanceService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+}
+
+func NewAttendanceService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+) *AttendanceService {
+	return &AttendanceService{
+		db:         db,
+		repository: repository,
+	}
+}
+
+var (
+	ErrInvalidRange       = errors.New("time range is invalid")
+	ErrInvalidTimezone    = errors.New("timezone is invalid")
+	ErrInvalidAttendance  = errors.New("member, class type or session does not exist")
+	ErrAttendanceInFuture = errors.New("attendance can't be recorded in the future")
+)
+
+func (s *AttendanceService) GetAttendance(ctx context.Context, userID int32, from, to time.Time, timezone string) (*Summary, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+
+	var (
+		summary Summary
+		err     error
+	)
+
+	summary.Log, err = s.repository.GetAttendanceLog(ctx, repository.GetAttendanceLogParams{
+		UserID:   userID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance log: %w", err)
+	}
+
+	summary.ByWeek, err = s.repository.GetAttendanceCountsByWeek(ctx, repository.GetAttendanceCountsByWeekParams{
+		Timezone: timezone,
+		UserID:   userID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly attendance: %w", err)
+	}
+
+	summary.ByMonth, err = s.repository.GetAttendanceCountsByMonth(ctx, repository.GetAttendanceCountsByMonthParams{
+		Timezone: timezone,
+		UserID:   userID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly attendance: %w", err)
+	}
+
+	summary.ByClassType, err = s.repository.GetAttendanceCountsByClassType(ctx, repository.GetAttendanceCountsByClassTypeParams{
+		UserID:   userID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance per class type: %w", err)
+	}
+
+	streaks, err := s.repository.GetAttendanceStreaks(ctx, repository.GetAttendanceStreaksParams{
+		Timezone: timezone,
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance streaks: %w", err)
+	}
+	summary.CurrentStreak = streaks.CurrentStreak
+	summary.LongestStreak = streaks.LongestStreak
+
+	return &summary, nil
+}
+
+// RecordAttendance logs a class visit and takes a credit for it, unless the
+// member is on an unlimited membership. Visits without a credit to pay for
+// them are not recorded.
+func (s *AttendanceService) RecordAttendance(ctx context.Context, attendance NewAttendance) (*repository.Attendance, error) {
+	var attendedAt pgtype.Timestamptz
+	if attendance.AttendedAt != nil {
+		if attendance.AttendedAt.After(time.Now()) {
+			return nil, ErrAttendanceInFuture
+		}
+		attendedAt = pgtype.Timestamptz{Time: *attendance.AttendedAt, Valid: true}
+	}
+
+	var created repository.Attendance
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		created, err = q.CreateAttendance(ctx, repository.CreateAttendanceParams{
+			UserID:      attendance.UserID,
+			SessionID:   toInt4(attendance.SessionID),
+			ClassTypeID: toInt4(attendance.ClassTypeID),
+			Source:      checkins.SourceManual,
+			RecordedBy:  pgtype.Int4{Int32: attendance.RecordedBy, Valid: true},
+			CheckedInAt: attendedAt,
+		})
+		if err != nil {
+			if repository.IsForeignKeyViolation(err) {
+				return ErrInvalidAttendance
+			}
+			return fmt.Errorf("failed to create attendance in db: %w", err)
+		}
+
+		if created.SessionID.Valid {
+			err = q.MarkBookingAttended(ctx, repository.MarkBookingAttendedParams{
+				SessionID:   created.SessionID.Int32,
+				UserID:      created.UserID,
+				CheckedInAt: pgtype.Timestamptz{Time: created.CheckedInAt, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to mark booking attended: %w", err)
+			}
+		}
+
+		_, err = credits.ConsumeForVisit(ctx, q, created)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func toInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
@@ -0,0 +1,22 @@
+package bookings
+
+import "time"
+
+type BookingResponse struct {
+	ID        int32  `json:"id"`
+	SessionID int32  `json:"session_id"`
+	UserID    int32  `json:"user_id"`
+	Status    string `json:"status"`
+	// WaitlistPosition is 1 for the next member to be promoted
+	WaitlistPosition *int64    `json:"waitlist_position,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type SessionBookingResponse struct {
+	ID         int32      `json:"id"`
+	UserID     int32      `json:"user_id"`
+	Email      string     `json:"email"`
+	Status     string     `json:"status"`
+	PromotedAt *time.Time `json:"promoted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,142 @@
+package bookings
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/penalties"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type BookingHandlers struct {
+	bService    IBookingService
+	smtpService smtp.ISMTPService
+}
+
+func NewBookingHandlers(
+	bService IBookingService,
+	smtpService smtp.ISMTPService,
+) *BookingHandlers {
+	return &BookingHandlers{
+		bService:    bService,
+		smtpService: smtpService,
+	}
+}
+
+func (h *BookingHandlers) Book(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Session ID is invalid")
+	}
+
+	result, err := h.bService.Book(r.Context(), sessionID, userID)
+	if err != nil {
+		return bookingError(err)
+	}
+
+	resp := BookingResponse{
+		ID:        result.Booking.ID,
+		SessionID: result.Booking.SessionID,
+		UserID:    result.Booking.UserID,
+		Status:    result.Booking.Status,
+		CreatedAt: result.Booking.CreatedAt,
+	}
+	if result.Booking.Status == BookingStatusWaitlisted {
+		resp.WaitlistPosition = &result.WaitlistPosition
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+func (h *BookingHandlers) CancelBooking(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Session ID is invalid")
+	}
+
+	cancellation, err := h.bService.CancelBooking(r.Context(), sessionID, userID)
+	if err != nil {
+		return bookingError(err)
+	}
+
+	if cancellation.Penalty != nil {
+		penalties.Notify(r.Context(), h.smtpService, *cancellation.Penalty)
+	}
+
+	if promotion := cancellation.Promotion; promotion != nil {
+		if err := h.smtpService.SendWaitlistPromotionEmail(r.Context(), promotion.Email, promotion.ClassName, promotion.StartsAt); err != nil {
+			// The promotion stands, the member will still see it in the app
+			logging.FromContext(r.Context()).Error("Failed to send waitlist promotion email", slog.Any("error", err))
+		}
+	}
+
+	users.WriteSuccess(w, "Booking cancelled", http.StatusOK)
+	return nil
+}
+
+func (h *BookingHandlers) GetSessionBookings(w http.ResponseWriter, r *http.Request) error {
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Session ID is invalid")
+	}
+
+	bookings, err := h.bService.GetSessionBookings(r.Context(), sessionID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]SessionBookingResponse, 0, len(bookings))
+	for _, b := range bookings {
+		booking := SessionBookingResponse{
+			ID:        b.ID,
+			UserID:    b.UserID,
+			Email:     b.Email,
+			Status:    b.Status,
+			CreatedAt: b.CreatedAt,
+		}
+		if b.PromotedAt.Valid {
+			booking.PromotedAt = &b.PromotedAt.Time
+		}
+		resp = append(resp, booking)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func bookingError(err error) error {
+	switch {
+	case errors.Is(err, ErrSessionDoesntExist):
+		return apperror.New(http.StatusNotFound, "session_doesnt_exist", "Session does not exist").Wrap(err)
+	case errors.Is(err, ErrBookingDoesntExist):
+		return apperror.New(http.StatusNotFound, "booking_doesnt_exist", "Booking does not exist").Wrap(err)
+	case errors.Is(err, ErrSessionCancelled):
+		return apperror.New(http.StatusConflict, "session_cancelled", "Session is cancelled").Wrap(err)
+	case errors.Is(err, ErrSessionStarted):
+		return apperror.New(http.StatusConflict, "session_started", "Session has already started").Wrap(err)
+	case errors.Is(err, ErrBookingClosed):
+		return apperror.New(http.StatusConflict, "booking_closed", "Booking can no longer be cancelled").Wrap(err)
+	case errors.Is(err, ErrAlreadyBooked):
+		return apperror.New(http.StatusConflict, "already_booked", "Session is already booked").Wrap(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,13 @@
+package bookings
+
+import (
+	"context"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IBookingService interface {
+	Book(ctx context.Context, sessionID, userID int32) (*BookingResult, error)
+	CancelBooking(ctx context.Context, sessionID, userID int32) (*Cancellation, error)
+	GetSessionBookings(ctx context.Context, sessionID int32) ([]repository.GetSessionBookingsRow, error)
+}
@@ -0,0 +1,270 @@
+package bookings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/penalties"
+)
+
+const (
+	BookingStatusBooked     = "booked"
+	BookingStatusWaitlisted = "waitlisted"
+	BookingStatusCancelled  = "cancelled"
+	BookingStatusAttended   = "attended"
+	BookingStatusNoShow     = "no_show"
+)
+
+// BookingResult is the outcome of a booking request. Members that didn't get a
+// spot are put on the waitlist.
+type BookingResult struct {
+	Booking          repository.Booking
+	WaitlistPosition int64
+}
+
+// Promotion describes a waitlisted member who got the spot freed by a
+// cancellation.
+type Promotion struct {
+	Booking   repository.Booking
+	Email     string
+	ClassName string
+	// StartsAt is in the timezone of the class schedule
+	StartsAt time.Time
+}
+
+// Cancellation is the outcome of cancelling a booking. Both fields are
+// optional and describe who needs to be emailed.
+type Cancellation struct {
+	// Promotion is the waitlisted member who got the freed spot
+	Promotion *Promotion
+	// Penalty is set when the booking was cancelled inside the class type's
+	// cancellation window
+	Penalty *penalties.Notice
+}
+
+type BookingService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+}
+
+func NewBookingService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+) *BookingService {
+	return &BookingService{
+		db:         db,
+		repository: repository,
+	}
+}
+
+var (
+	ErrSessionDoesntExist = errors.New("session does not exist")
+	ErrSessionCancelled   = errors.New("session is cancelled")
+	ErrSessionStarted     = errors.New("session has already started")
+	ErrAlreadyBooked      = errors.New("session is already booked")
+	ErrBookingDoesntExist = errors.New("booking does not exist")
+	ErrBookingClosed      = errors.New("booking was already checked in or marked as a no-show")
+)
+
+// Book reserves a spot in a session, or a place on its waitlist when the
+// session is full. The session row is locked for the duration of the
+// transaction so concurrent requests can't overbook it.
+func (s *BookingService) Book(ctx context.Context, sessionID, userID int32) (*BookingResult, error) {
+	var result BookingResult
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		session, err := lockSession(ctx, q, sessionID)
+		if err != nil {
+			return err
+		}
+		if !session.StartsAt.After(time.Now()) {
+			return ErrSessionStarted
+		}
+
+		_, err = q.GetActiveBooking(ctx, repository.GetActiveBookingParams{
+			SessionID: sessionID,
+			UserID:    userID,
+		})
+		if err == nil {
+			return ErrAlreadyBooked
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get booking: %w", err)
+		}
+
+		booked, err := q.CountBookedInSession(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to count bookings: %w", err)
+		}
+
+		status := BookingStatusBooked
+		if booked >= int64(session.Capacity) {
+			status = BookingStatusWaitlisted
+		}
+
+		result.Booking, err = q.CreateBooking(ctx, repository.CreateBookingParams{
+			SessionID: sessionID,
+			UserID:    userID,
+			Status:    status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create booking in db: %w", err)
+		}
+
+		if status == BookingStatusWaitlisted {
+			result.WaitlistPosition, err = q.GetWaitlistPosition(ctx, repository.GetWaitlistPositionParams{
+				SessionID: sessionID,
+				CreatedAt: result.Booking.CreatedAt,
+				ID:        result.Booking.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get waitlist position: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// CancelBooking cancels a member's booking or waitlist entry. Cancelling a
+// spot inside the class type's cancellation window is penalized. If it freed a
+// spot, the first member on the waitlist is promoted and returned so they can
+// be notified.
+func (s *BookingService) CancelBooking(ctx context.Context, sessionID, userID int32) (*Cancellation, error) {
+	var cancellation Cancellation
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		session, err := lockSession(ctx, q, sessionID)
+		if err != nil {
+			return err
+		}
+
+		booking, err := q.GetActiveBooking(ctx, repository.GetActiveBookingParams{
+			SessionID: sessionID,
+			UserID:    userID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBookingDoesntExist
+			}
+			return fmt.Errorf("failed to get booking: %w", err)
+		}
+		if booking.Status == BookingStatusAttended || booking.Status == BookingStatusNoShow {
+			return ErrBookingClosed
+		}
+
+		window := time.Duration(session.CancellationWindowMinutes) * time.Minute
+		late := booking.Status == BookingStatusBooked && window > 0 && time.Until(session.StartsAt) < window
+
+		if _, err := q.CancelBooking(ctx, repository.CancelBookingParams{
+			ID:               booking.ID,
+			LateCancellation: late,
+		}); err != nil {
+			return fmt.Errorf("failed to cancel booking in db: %w", err)
+		}
+
+		if late {
+			cancellation.Penalty, err = penalizeLateCancellation(ctx, q, session, booking)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Nobody can use a spot once the class has started
+		if booking.Status != BookingStatusBooked || !session.StartsAt.After(time.Now()) {
+			return nil
+		}
+
+		promoted, err := q.PromoteNextWaitlisted(ctx, sessionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("failed to promote waitlisted booking: %w", err)
+		}
+
+		user, err := q.GetUserByID(ctx, promoted.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get promoted user: %w", err)
+		}
+
+		cancellation.Promotion = &Promotion{
+			Booking:   promoted,
+			Email:     user.Email,
+			ClassName: session.ClassTypeName,
+			StartsAt:  localStart(session),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cancellation, nil
+}
+
+func (s *BookingService) GetSessionBookings(ctx context.Context, sessionID int32) ([]repository.GetSessionBookingsRow, error) {
+	return s.repository.GetSessionBookings(ctx, sessionID)
+}
+
+func penalizeLateCancellation(
+	ctx context.Context,
+	q *repository.Queries,
+	session repository.GetClassSessionForUpdateRow,
+	booking repository.Booking,
+) (*penalties.Notice, error) {
+	penalty, err := penalties.Penalize(ctx, q, penalties.Offense{
+		UserID:    booking.UserID,
+		BookingID: booking.ID,
+		Reason:    penalties.ReasonLateCancellation,
+		Policy:    penalties.Policy(session.LateCancelPenalty),
+		FeeCents:  session.PenaltyFeeCents,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := q.GetUserByID(ctx, booking.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &penalties.Notice{
+		Email:     user.Email,
+		ClassName: session.ClassTypeName,
+		StartsAt:  localStart(session),
+		Reason:    penalties.ReasonLateCancellation,
+		Penalties: []repository.Penalty{penalty},
+	}, nil
+}
+
+// localStart returns when a session starts in its schedule's timezone.
+func localStart(session repository.GetClassSessionForUpdateRow) time.Time {
+	if loc, err := time.LoadLocation(session.Timezone); err == nil {
+		return session.StartsAt.In(loc)
+	}
+	return session.StartsAt
+}
+
+func lockSession(ctx context.Context, q *repository.Queries, sessionID int32) (repository.GetClassSessionForUpdateRow, error) {
+	session, err := q.GetClassSessionForUpdate(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return session, ErrSessionDoesntExist
+		}
+		return session, fmt.Errorf("failed to lock session: %w", err)
+	}
+	if session.CancelledAt.Valid {
+		return session, ErrSessionCancelled
+	}
+	return session, nil
+}
@@ -0,0 +1,33 @@
+package checkins
+
+import "time"
+
+type CheckinCodeResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CheckinRequest struct {
+	Code string `json:"code"`
+	// SessionID optionally ties the check-in to a class session
+	SessionID *int32 `json:"session_id"`
+}
+
+type CheckinResponse struct {
+	Member           MemberResponse `json:"member"`
+	CheckedIn        bool           `json:"checked_in"`
+	AlreadyCheckedIn bool           `json:"already_checked_in"`
+	// Reason explains why the member was not checked in
+	Reason       string     `json:"reason,omitempty"`
+	AttendanceID *int32     `json:"attendance_id,omitempty"`
+	CheckedInAt  *time.Time `json:"checked_in_at,omitempty"`
+}
+
+type MemberResponse struct {
+	UserID                int32      `json:"user_id"`
+	Email                 string     `json:"email"`
+	PhotoURL              *string    `json:"photo_url"`
+	Status                string     `json:"status"`
+	SubscriptionEndsAt    *time.Time `json:"subscription_ends_at"`
+	HasActiveSubscription bool       `json:"has_active_subscription"`
+}
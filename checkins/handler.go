@@ -0,0 +1,104 @@
+package checkins
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type CheckinHandlers struct {
+	cService ICheckinService
+}
+
+func NewCheckinHandlers(cService ICheckinService) *CheckinHandlers {
+	return &CheckinHandlers{
+		cService: cService,
+	}
+}
+
+func (h *CheckinHandlers) GetCheckinCode(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	code, expiresAt, err := h.cService.GenerateCode(userID)
+	if err != nil {
+		return err
+	}
+
+	// The code rotates, clients must not cache it past its expiry
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, CheckinCodeResponse{Code: code, ExpiresAt: expiresAt}, http.StatusOK)
+	return nil
+}
+
+func (h *CheckinHandlers) CheckIn(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var checkinRequest CheckinRequest
+	if err := validate.DecodeJSON(w, r, &checkinRequest); err != nil {
+		return err
+	}
+
+	result, err := h.cService.CheckIn(r.Context(), checkinRequest.Code, checkinRequest.SessionID, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCode):
+			return apperror.New(http.StatusBadRequest, "invalid_code", "Check-in code is invalid").Wrap(err)
+		case errors.Is(err, ErrCodeExpired):
+			return apperror.New(http.StatusBadRequest, "code_expired", "Check-in code is expired, please refresh it").Wrap(err)
+		case errors.Is(err, ErrMemberDoesntExist):
+			return apperror.New(http.StatusNotFound, "member_doesnt_exist", "Member does not exist").Wrap(err)
+		case errors.Is(err, ErrSessionDoesntExist):
+			return apperror.New(http.StatusNotFound, "session_doesnt_exist", "Session does not exist").Wrap(err)
+		default:
+			return err
+		}
+	}
+
+	resp := CheckinResponse{
+		Member: MemberResponse{
+			UserID:                result.User.ID,
+			Email:                 result.User.Email,
+			Status:                result.User.Status,
+			HasActiveSubscription: result.Subscription != nil,
+		},
+		CheckedIn:        result.CheckedIn,
+		AlreadyCheckedIn: result.AlreadyCheckedIn,
+	}
+	if result.User.PhotoUrl.Valid {
+		resp.Member.PhotoURL = &result.User.PhotoUrl.String
+	}
+	if result.Subscription != nil {
+		resp.Member.SubscriptionEndsAt = &result.Subscription.EndsAt
+	}
+	if result.Attendance != nil {
+		resp.AttendanceID = &result.Attendance.ID
+		resp.CheckedInAt = &result.Attendance.CheckedInAt
+	}
+
+	statusCode := http.StatusCreated
+	if result.AlreadyCheckedIn {
+		statusCode = http.StatusOK
+	}
+	if result.Reason != nil {
+		logging.FromContext(r.Context()).Warn("Member not in good standing tried to check in", slog.Int("user_id", int(result.User.ID)), slog.Any("reason", result.Reason))
+		resp.Reason = result.Reason.Error()
+		statusCode = http.StatusForbidden
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, statusCode)
+	return nil
+}
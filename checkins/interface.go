@@ -0,0 +1,11 @@
+package checkins
+
+import (
+	"context"
+	"time"
+)
+
+type ICheckinService interface {
+	GenerateCode(userID int32) (code string, expiresAt time.Time, err error)
+	CheckIn(ctx context.Context, code string, sessionID *int32, staffID int32) (*CheckinResult, error)
+}
@@ -0,0 +1,245 @@
+package checkins
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/memberships"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// codeWindow is how often the check-in code rotates. A code is accepted
+	// during its own window and the one after it, to allow for clock drift
+	// and slow scans.
+	codeWindow = 30 * time.Second
+	codePrefix = "B66"
+	// duplicateCheckinWindow is how long after checking in a second scan is
+	// reported as a duplicate instead of recording a new attendance.
+	duplicateCheckinWindow = 2 * time.Hour
+
+	SourceQR     = "qr"
+	SourceManual = "manual"
+)
+
+// CheckinResult is what the kiosk shows after scanning a code. Members that
+// are not in good standing, or have no credits left to pay for the class, are
+// returned with CheckedIn false and a Reason.
+type CheckinResult struct {
+	User             repository.User
+	Subscription     *repository.Subscription
+	CheckedIn        bool
+	AlreadyCheckedIn bool
+	Reason           error
+	Attendance       *repository.Attendance
+}
+
+type CheckinService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+	secret     []byte
+}
+
+func NewCheckinService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+	secret string,
+) *CheckinService {
+	return &CheckinService{
+		db:         db,
+		repository: repository,
+		secret:     []byte(secret),
+	}
+}
+
+var (
+	ErrInvalidCode         = errors.New("check-in code is invalid")
+	ErrCodeExpired         = errors.New("check-in code is expired")
+	ErrMemberDoesntExist   = errors.New("member does not exist")
+	ErrAccountNotActive    = errors.New("account is not active")
+	ErrNoActiveMembership  = errors.New("member has no active membership")
+	ErrSessionDoesntExist  = errors.New("session does not exist")
+	ErrCheckinNotAvailable = errors.New("check-in codes are not configured")
+)
+
+// GenerateCode returns the signed check-in code for the current window and
+// the time it stops being accepted.
+func (s *CheckinService) GenerateCode(userID int32) (string, time.Time, error) {
+	if len(s.secret) == 0 {
+		return "", time.Time{}, ErrCheckinNotAvailable
+	}
+
+	window := time.Now().Unix() / int64(codeWindow.Seconds())
+	expiresAt := time.Unix((window+2)*int64(codeWindow.Seconds()), 0)
+	return s.sign(userID, window), expiresAt, nil
+}
+
+func (s *CheckinService) CheckIn(ctx context.Context, code string, sessionID *int32, staffID int32) (*CheckinResult, error) {
+	userID, err := s.verify(code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberDoesntExist
+		}
+		return nil, err
+	}
+
+	result := &CheckinResult{User: user}
+
+	subscription, err := s.repository.GetCurrentSubscription(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get current subscription: %w", err)
+	}
+	if err == nil {
+		result.Subscription = &subscription
+	}
+
+	// Members not in good standing are turned away at the door
+	if users.UserStatus(user.Status) != users.UserStatusActive {
+		result.Reason = fmt.Errorf("%w: %s", ErrAccountNotActive, user.Status)
+		return result, nil
+	}
+	if result.Subscription == nil {
+		result.Reason = ErrNoActiveMembership
+		freeze, err := s.repository.GetActiveMembershipFreeze(ctx, userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get active freeze: %w", err)
+		}
+		if err == nil {
+			result.Reason = fmt.Errorf("%w until %s", memberships.ErrMembershipFrozen, freeze.EndsAt.Format(time.DateOnly))
+		}
+		return result, nil
+	}
+
+	latest, err := s.repository.GetLatestAttendance(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get latest attendance: %w", err)
+	}
+	if err == nil && time.Since(latest.CheckedInAt) < duplicateCheckinWindow && sameSession(latest.SessionID, sessionID) {
+		result.CheckedIn = true
+		result.AlreadyCheckedIn = true
+		result.Attendance = &latest
+		return result, nil
+	}
+
+	var attendance repository.Attendance
+	err = repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		attendance, err = q.CreateAttendance(ctx, repository.CreateAttendanceParams{
+			UserID:     userID,
+			SessionID:  toInt4(sessionID),
+			Source:     SourceQR,
+			RecordedBy: pgtype.Int4{Int32: staffID, Valid: true},
+		})
+		if err != nil {
+			if repository.IsForeignKeyViolation(err) {
+				return ErrSessionDoesntExist
+			}
+			return fmt.Errorf("failed to create attendance in db: %w", err)
+		}
+
+		if attendance.SessionID.Valid {
+			err = q.MarkBookingAttended(ctx, repository.MarkBookingAttendedParams{
+				SessionID:   attendance.SessionID.Int32,
+				UserID:      attendance.UserID,
+				CheckedInAt: pgtype.Timestamptz{Time: attendance.CheckedInAt, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to mark booking attended: %w", err)
+			}
+		}
+
+		_, err = credits.ConsumeForVisit(ctx, q, attendance)
+		return err
+	})
+	if errors.Is(err, credits.ErrInsufficientCredits) {
+		result.Reason = err
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.CheckedIn = true
+	result.Attendance = &attendance
+	return result, nil
+}
+
+// sign builds a code of the form B66.<userID>.<window>.<signature>.
+func (s *CheckinService) sign(userID int32, window int64) string {
+	payload := fmt.Sprintf("%s.%d.%d", codePrefix, userID, window)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// verify checks a code's signature and window and returns the user it was
+// issued to.
+func (s *CheckinService) verify(code string) (int32, error) {
+	if len(s.secret) == 0 {
+		return 0, ErrCheckinNotAvailable
+	}
+
+	parts := strings.Split(code, ".")
+	if len(parts) != 4 || parts[0] != codePrefix {
+		return 0, ErrInvalidCode
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return 0, ErrInvalidCode
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal(signature, s.mac(payload)) {
+		return 0, ErrInvalidCode
+	}
+
+	userID, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return 0, ErrInvalidCode
+	}
+	window, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCode
+	}
+
+	current := time.Now().Unix() / int64(codeWindow.Seconds())
+	if window > current || current-window > 1 {
+		return 0, ErrCodeExpired
+	}
+
+	return int32(userID), nil
+}
+
+func (s *CheckinService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func sameSession(recorded pgtype.Int4, requested *int32) bool {
+	if requested == nil {
+		return !recorded.Valid
+	}
+	return recorded.Valid && recorded.Int32 == *requested
+}
+
+func toInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
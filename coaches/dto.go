@@ -0,0 +1,99 @@
+package coaches
+
+import "time"
+
+type ProfileRequest struct {
+	Bio            string   `json:"bio"`
+	Specialties    []string `json:"specialties"`
+	Certifications []string `json:"certifications"`
+	// Timezone is the IANA timezone of the availability windows, UTC if omitted
+	Timezone string `json:"timezone"`
+}
+
+type CoachResponse struct {
+	ID             int32    `json:"id"`
+	Email          string   `json:"email"`
+	Bio            string   `json:"bio"`
+	Specialties    []string `json:"specialties"`
+	Certifications []string `json:"certifications"`
+	Timezone       string   `json:"timezone"`
+}
+
+type WindowRequest struct {
+	// Weekday is the lowercase English day name, e.g. monday
+	Weekday string `json:"weekday"`
+	// Start and End are wall-clock times, e.g. 18:00
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type WeeklyAvailabilityRequest struct {
+	Windows []WindowRequest `json:"windows"`
+}
+
+type WindowResponse struct {
+	Weekday string `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+type ExceptionRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type ExceptionResponse struct {
+	ID       int32     `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type SlotResponse struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type AvailabilityResponse struct {
+	CoachID         int32          `json:"coach_id"`
+	From            time.Time      `json:"from"`
+	To              time.Time      `json:"to"`
+	DurationMinutes int            `json:"duration_minutes"`
+	Slots           []SlotResponse `json:"slots"`
+}
+
+type SessionRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Note     string    `json:"note"`
+}
+
+type DeclineRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RescheduleRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type ProposalResponse struct {
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	ProposedBy int32     `json:"proposed_by"`
+}
+
+type SessionResponse struct {
+	ID            int32             `json:"id"`
+	CoachID       int32             `json:"coach_id"`
+	MemberID      int32             `json:"member_id"`
+	Status        string            `json:"status"`
+	StartsAt      time.Time         `json:"starts_at"`
+	EndsAt        time.Time         `json:"ends_at"`
+	Note          string            `json:"note"`
+	DeclineReason string            `json:"decline_reason,omitempty"`
+	Reschedule    *ProposalResponse `json:"reschedule,omitempty"`
+	CancelledBy   *int32            `json:"cancelled_by,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
@@ -0,0 +1,604 @@
+package coaches
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/schedule"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+const (
+	defaultAvailabilityRange = 7 * 24 * time.Hour
+	clockLayout              = "15:04"
+)
+
+type CoachHandlers struct {
+	cService    ICoachService
+	smtpService smtp.ISMTPService
+}
+
+func NewCoachHandlers(
+	cService ICoachService,
+	smtpService smtp.ISMTPService,
+) *CoachHandlers {
+	return &CoachHandlers{
+		cService:    cService,
+		smtpService: smtpService,
+	}
+}
+
+func (h *CoachHandlers) GetCoaches(w http.ResponseWriter, r *http.Request) error {
+	coaches, err := h.cService.GetCoaches(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]CoachResponse, 0, len(coaches))
+	for _, coach := range coaches {
+		resp = append(resp, CoachResponse{
+			ID:             coach.UserID,
+			Email:          coach.Email,
+			Bio:            coach.Bio,
+			Specialties:    coach.Specialties,
+			Certifications: coach.Certifications,
+			Timezone:       coach.Timezone,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) GetCoach(w http.ResponseWriter, r *http.Request) error {
+	coachID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Coach ID is invalid")
+	}
+
+	coach, err := h.cService.GetCoach(r.Context(), coachID)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, CoachResponse{
+		ID:             coach.UserID,
+		Email:          coach.Email,
+		Bio:            coach.Bio,
+		Specialties:    coach.Specialties,
+		Certifications: coach.Certifications,
+		Timezone:       coach.Timezone,
+	}, http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) UpdateMyProfile(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var profileRequest ProfileRequest
+	if err := validate.DecodeJSON(w, r, &profileRequest); err != nil {
+		return err
+	}
+
+	profile, err := h.cService.UpdateProfile(r.Context(), coachID, Profile{
+		Bio:            profileRequest.Bio,
+		Specialties:    profileRequest.Specialties,
+		Certifications: profileRequest.Certifications,
+		Timezone:       profileRequest.Timezone,
+	})
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, CoachResponse{
+		ID:             profile.UserID,
+		Bio:            profile.Bio,
+		Specialties:    profile.Specialties,
+		Certifications: profile.Certifications,
+		Timezone:       profile.Timezone,
+	}, http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) GetMyWeeklyAvailability(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	availability, err := h.cService.GetWeeklyAvailability(r.Context(), coachID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toWindowResponses(availability), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) SetMyWeeklyAvailability(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var availabilityRequest WeeklyAvailabilityRequest
+	if err := validate.DecodeJSON(w, r, &availabilityRequest); err != nil {
+		return err
+	}
+
+	windows := make([]Window, 0, len(availabilityRequest.Windows))
+	for i, req := range availabilityRequest.Windows {
+		window, err := parseWindow(req)
+		if err != nil {
+			return apperror.BadRequest(fmt.Sprintf("Window %d is invalid: %s", i, err))
+		}
+		windows = append(windows, window)
+	}
+
+	availability, err := h.cService.SetWeeklyAvailability(r.Context(), coachID, windows)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toWindowResponses(availability), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) AddMyException(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var exceptionRequest ExceptionRequest
+	if err := validate.DecodeJSON(w, r, &exceptionRequest); err != nil {
+		return err
+	}
+
+	exception, err := h.cService.AddException(r.Context(), coachID, exceptionRequest.StartsAt, exceptionRequest.EndsAt, exceptionRequest.Reason)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, ExceptionResponse{
+		ID:       exception.ID,
+		StartsAt: exception.StartsAt,
+		EndsAt:   exception.EndsAt,
+		Reason:   exception.Reason,
+	}, http.StatusCreated)
+	return nil
+}
+
+func (h *CoachHandlers) RemoveMyException(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	exceptionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Exception ID is invalid")
+	}
+
+	if err := h.cService.RemoveException(r.Context(), coachID, exceptionID); err != nil {
+		return coachError(err)
+	}
+
+	users.WriteSuccess(w, "Availability exception removed", http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) GetAvailability(w http.ResponseWriter, r *http.Request) error {
+	coachID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Coach ID is invalid")
+	}
+
+	query := r.URL.Query()
+
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return apperror.BadRequest("Timezone is invalid")
+		}
+	}
+
+	from := time.Now()
+	if v := query.Get("from"); v != "" {
+		if from, err = schedule.ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("from must be an RFC 3339 time or a date")
+		}
+	}
+
+	to := from.Add(defaultAvailabilityRange)
+	if v := query.Get("to"); v != "" {
+		if to, err = schedule.ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("to must be an RFC 3339 time or a date")
+		}
+	}
+
+	duration := DefaultSlotDuration
+	if v := query.Get("duration"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil {
+			return apperror.BadRequest("duration must be a number of minutes")
+		}
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	slots, err := h.cService.GetAvailability(r.Context(), coachID, from, to, duration)
+	if err != nil {
+		return coachError(err)
+	}
+
+	resp := AvailabilityResponse{
+		CoachID:         coachID,
+		From:            from.In(loc),
+		To:              to.In(loc),
+		DurationMinutes: int(duration.Minutes()),
+		Slots:           make([]SlotResponse, 0, len(slots)),
+	}
+	for _, slot := range slots {
+		resp.Slots = append(resp.Slots, SlotResponse{
+			StartsAt: slot.StartsAt.In(loc),
+			EndsAt:   slot.EndsAt.In(loc),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) RequestSession(w http.ResponseWriter, r *http.Request) error {
+	memberID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	coachID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Coach ID is invalid")
+	}
+
+	var sessionRequest SessionRequest
+	if err := validate.DecodeJSON(w, r, &sessionRequest); err != nil {
+		return err
+	}
+
+	session, err := h.cService.RequestSession(r.Context(), coachID, memberID, sessionRequest.StartsAt, sessionRequest.EndsAt, sessionRequest.Note)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusCreated)
+	return nil
+}
+
+func (h *CoachHandlers) GetMySessions(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessions, err := h.cService.GetSessions(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, toSessionResponse(session))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) AcceptSession(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	session, err := h.cService.AcceptSession(r.Context(), sessionID, coachID)
+	if err != nil {
+		return coachError(err)
+	}
+
+	h.notifyConfirmed(r.Context(), *session)
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) DeclineSession(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	var declineRequest DeclineRequest
+	if err := validate.DecodeJSON(w, r, &declineRequest); err != nil {
+		return err
+	}
+
+	session, err := h.cService.DeclineSession(r.Context(), sessionID, coachID, declineRequest.Reason)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) CancelSession(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	session, err := h.cService.CancelSession(r.Context(), sessionID, userID)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) ProposeReschedule(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	var rescheduleRequest RescheduleRequest
+	if err := validate.DecodeJSON(w, r, &rescheduleRequest); err != nil {
+		return err
+	}
+
+	session, err := h.cService.ProposeReschedule(r.Context(), sessionID, userID, rescheduleRequest.StartsAt, rescheduleRequest.EndsAt)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) AcceptReschedule(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	session, err := h.cService.AcceptReschedule(r.Context(), sessionID, userID)
+	if err != nil {
+		return coachError(err)
+	}
+
+	h.notifyConfirmed(r.Context(), *session)
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+func (h *CoachHandlers) DeclineReschedule(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Training session ID is invalid")
+	}
+
+	session, err := h.cService.DeclineReschedule(r.Context(), sessionID, userID)
+	if err != nil {
+		return coachError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSessionResponse(*session), http.StatusOK)
+	return nil
+}
+
+// notifyConfirmed emails both sides about a confirmed session. The session is
+// confirmed already, so failures are only logged.
+func (h *CoachHandlers) notifyConfirmed(ctx context.Context, session repository.TrainingSession) {
+	participants, err := h.cService.GetSessionParticipants(ctx, session.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to get training session participants", slog.Any("error", err))
+		return
+	}
+
+	NotifyConfirmed(ctx, h.smtpService, Participants{
+		MemberEmail: participants.MemberEmail,
+		CoachEmail:  participants.CoachEmail,
+		Timezone:    participants.Timezone,
+	}, session.StartsAt, session.EndsAt)
+}
+
+func coachError(err error) error {
+	switch {
+	case errors.Is(err, ErrCoachDoesntExist):
+		return apperror.New(http.StatusNotFound, "coach_doesnt_exist", "Coach does not exist").Wrap(err)
+	case errors.Is(err, ErrExceptionDoesntExist):
+		return apperror.New(http.StatusNotFound, "exception_doesnt_exist", "Availability exception does not exist").Wrap(err)
+	case errors.Is(err, ErrSessionDoesntExist):
+		return apperror.New(http.StatusNotFound, "session_doesnt_exist", "Training session does not exist").Wrap(err)
+	case errors.Is(err, ErrProfileRequired):
+		return apperror.New(http.StatusConflict, "profile_required", err.Error()).Wrap(err)
+	case errors.Is(err, ErrSlotUnavailable):
+		return apperror.New(http.StatusConflict, "slot_unavailable", err.Error()).Wrap(err)
+	case errors.Is(err, ErrSlotTaken):
+		return apperror.New(http.StatusConflict, "slot_taken", err.Error()).Wrap(err)
+	case errors.Is(err, ErrSessionNotPending):
+		return apperror.New(http.StatusConflict, "session_not_pending", err.Error()).Wrap(err)
+	case errors.Is(err, ErrSessionClosed):
+		return apperror.New(http.StatusConflict, "session_closed", err.Error()).Wrap(err)
+	case errors.Is(err, ErrNoReschedule):
+		return apperror.New(http.StatusConflict, "no_reschedule", err.Error()).Wrap(err)
+	case errors.Is(err, ErrOwnReschedule):
+		return apperror.New(http.StatusForbidden, "own_reschedule", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidTimezone):
+		return apperror.New(http.StatusBadRequest, "invalid_timezone", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidWindow):
+		return apperror.New(http.StatusBadRequest, "invalid_window", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidException):
+		return apperror.New(http.StatusBadRequest, "invalid_exception", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidRange):
+		return apperror.New(http.StatusBadRequest, "invalid_range", err.Error()).Wrap(err)
+	case errors.Is(err, ErrRangeTooLarge):
+		return apperror.New(http.StatusBadRequest, "range_too_large", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidDuration):
+		return apperror.New(http.StatusBadRequest, "invalid_duration", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidSession):
+		return apperror.New(http.StatusBadRequest, "invalid_session", err.Error()).Wrap(err)
+	case errors.Is(err, ErrOwnSession):
+		return apperror.New(http.StatusBadRequest, "own_session", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func parseWindow(req WindowRequest) (Window, error) {
+	var window Window
+
+	weekday, ok := weekdays[strings.ToLower(req.Weekday)]
+	if !ok {
+		return window, fmt.Errorf("unknown weekday %q", req.Weekday)
+	}
+	window.Weekday = weekday
+
+	var err error
+	if window.Start, err = parseClock(req.Start); err != nil {
+		return window, err
+	}
+	if window.End, err = parseClock(req.End); err != nil {
+		return window, err
+	}
+
+	return window, nil
+}
+
+// parseClock parses a wall-clock time such as 18:30 into an offset from
+// midnight. 24:00 is accepted as the end of the day.
+func parseClock(v string) (time.Duration, error) {
+	if v == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse(clockLayout, v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", v)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatClock(microseconds int64) string {
+	d := time.Duration(microseconds) * time.Microsecond
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func toWindowResponses(availability []repository.CoachAvailability) []WindowResponse {
+	resp := make([]WindowResponse, 0, len(availability))
+	for _, a := range availability {
+		resp = append(resp, WindowResponse{
+			Weekday: strings.ToLower(time.Weekday(a.Weekday).String()),
+			Start:   formatClock(a.StartTime.Microseconds),
+			End:     formatClock(a.EndTime.Microseconds),
+		})
+	}
+	return resp
+}
+
+func toSessionResponse(session repository.TrainingSession) SessionResponse {
+	resp := SessionResponse{
+		ID:            session.ID,
+		CoachID:       session.CoachID,
+		MemberID:      session.MemberID,
+		Status:        session.Status,
+		StartsAt:      session.StartsAt,
+		EndsAt:        session.EndsAt,
+		Note:          session.Note,
+		DeclineReason: session.DeclineReason,
+		CreatedAt:     session.CreatedAt,
+	}
+	if session.ProposedStartsAt.Valid {
+		resp.Reschedule = &ProposalResponse{
+			StartsAt:   session.ProposedStartsAt.Time,
+			EndsAt:     session.ProposedEndsAt.Time,
+			ProposedBy: session.ProposedBy.Int32,
+		}
+	}
+	if session.CancelledBy.Valid {
+		resp.CancelledBy = &session.CancelledBy.Int32
+	}
+	return resp
+}
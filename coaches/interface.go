@@ -0,0 +1,29 @@
+package coaches
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type ICoachService interface {
+	GetCoaches(ctx context.Context) ([]repository.GetCoachesRow, error)
+	GetCoach(ctx context.Context, coachID int32) (*repository.GetCoachRow, error)
+	UpdateProfile(ctx context.Context, coachID int32, profile Profile) (*repository.CoachProfile, error)
+	GetWeeklyAvailability(ctx context.Context, coachID int32) ([]repository.CoachAvailability, error)
+	SetWeeklyAvailability(ctx context.Context, coachID int32, windows []Window) ([]repository.CoachAvailability, error)
+	AddException(ctx context.Context, coachID int32, startsAt, endsAt time.Time, reason string) (*repository.CoachAvailabilityException, error)
+	RemoveException(ctx context.Context, coachID, exceptionID int32) error
+	GetAvailability(ctx context.Context, coachID int32, from, to time.Time, duration time.Duration) ([]Slot, error)
+	RequestSession(ctx context.Context, coachID, memberID int32, startsAt, endsAt time.Time, note string) (*repository.TrainingSession, error)
+	GetSessions(ctx context.Context, userID int32) ([]repository.TrainingSession, error)
+	AcceptSession(ctx context.Context, sessionID, coachID int32) (*repository.TrainingSession, error)
+	DeclineSession(ctx context.Context, sessionID, coachID int32, reason string) (*repository.TrainingSession, error)
+	CancelSession(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error)
+	ProposeReschedule(ctx context.Context, sessionID, userID int32, startsAt, endsAt time.Time) (*repository.TrainingSession, error)
+	AcceptReschedule(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error)
+	DeclineReschedule(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error)
+	GetSessionParticipants(ctx context.Context, sessionID int32) (*repository.GetTrainingSessionParticipantsRow, error)
+	ClaimReminders(ctx context.Context, lead time.Duration) ([]repository.ClaimTrainingSessionRemindersRow, error)
+}
@@ -0,0 +1,44 @@
+package coaches
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/smtp"
+)
+
+// ReminderLead is how long before a confirmed training session the reminder
+// goes out.
+const ReminderLead = 24 * time.Hour
+
+// ReminderJob emails reminders for upcoming training sessions.
+type ReminderJob struct {
+	cService    ICoachService
+	smtpService smtp.ISMTPService
+}
+
+func NewReminderJob(
+	cService ICoachService,
+	smtpService smtp.ISMTPService,
+) *ReminderJob {
+	return &ReminderJob{
+		cService:    cService,
+		smtpService: smtpService,
+	}
+}
+
+func (j *ReminderJob) Run(ctx context.Context) error {
+	reminders, err := j.cService.ClaimReminders(ctx, ReminderLead)
+	if err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		NotifyReminder(ctx, j.smtpService, Participants{
+			MemberEmail: reminder.MemberEmail,
+			CoachEmail:  reminder.CoachEmail,
+			Timezone:    reminder.Timezone,
+		}, reminder.StartsAt, reminder.EndsAt)
+	}
+	return nil
+}
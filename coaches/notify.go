@@ -0,0 +1,51 @@
+package coaches
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/smtp"
+)
+
+// Participants are the member and coach of a training session. Times are
+// shown in the coach's timezone.
+type Participants struct {
+	MemberEmail string
+	CoachEmail  string
+	Timezone    string
+}
+
+// NotifyConfirmed emails both sides that a session is confirmed. Failures are
+// logged, the session stands either way.
+func NotifyConfirmed(ctx context.Context, smtpService smtp.ISMTPService, participants Participants, startsAt, endsAt time.Time) {
+	startsAt, endsAt = inTimezone(participants.Timezone, startsAt, endsAt)
+
+	if err := smtpService.SendTrainingConfirmationEmail(ctx, participants.MemberEmail, participants.CoachEmail, startsAt, endsAt); err != nil {
+		logging.FromContext(ctx).Error("Failed to send training confirmation email", slog.Any("error", err))
+	}
+	if err := smtpService.SendTrainingConfirmationEmail(ctx, participants.CoachEmail, participants.MemberEmail, startsAt, endsAt); err != nil {
+		logging.FromContext(ctx).Error("Failed to send training confirmation email", slog.Any("error", err))
+	}
+}
+
+// NotifyReminder reminds both sides of an upcoming session.
+func NotifyReminder(ctx context.Context, smtpService smtp.ISMTPService, participants Participants, startsAt, endsAt time.Time) {
+	startsAt, endsAt = inTimezone(participants.Timezone, startsAt, endsAt)
+
+	if err := smtpService.SendTrainingReminderEmail(ctx, participants.MemberEmail, participants.CoachEmail, startsAt, endsAt); err != nil {
+		logging.FromContext(ctx).Error("Failed to send training reminder email", slog.Any("error", err))
+	}
+	if err := smtpService.SendTrainingReminderEmail(ctx, participants.CoachEmail, participants.MemberEmail, startsAt, endsAt); err != nil {
+		logging.FromContext(ctx).Error("Failed to send training reminder email", slog.Any("error", err))
+	}
+}
+
+func inTimezone(timezone string, startsAt, endsAt time.Time) (time.Time, time.Time) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return startsAt, endsAt
+	}
+	return startsAt.In(loc), endsAt.In(loc)
+}
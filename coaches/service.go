@@ -0,0 +1,306 @@
+package coaches
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxAvailabilityRange caps how far ahead open slots can be listed at once
+	maxAvailabilityRange = 31 * 24 * time.Hour
+	// slotStep is how often a slot can start within an availability window
+	slotStep = 30 * time.Minute
+
+	DefaultSlotDuration = time.Hour
+)
+
+// Profile holds the fields a coach fills in about themselves.
+type Profile struct {
+	Bio            string
+	Specialties    []string
+	Certifications []string
+	Timezone       string
+}
+
+// Window is a weekly availability window, in the coach's timezone.
+type Window struct {
+	Weekday time.Weekday
+	// Start and End are offsets from midnight
+	Start time.Duration
+	End   time.Duration
+}
+
+// Slot is an open time a member can book with a coach.
+type Slot struct {
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+type CoachService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+}
+
+func NewCoachService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+) *CoachService {
+	return &CoachService{
+		db:         db,
+		repository: repository,
+	}
+}
+
+var (
+	ErrCoachDoesntExist     = errors.New("coach does not exist")
+	ErrProfileRequired      = errors.New("coach profile must be set up first")
+	ErrInvalidTimezone      = errors.New("timezone is invalid")
+	ErrInvalidWindow        = errors.New("availability window is invalid")
+	ErrInvalidException     = errors.New("availability exception is invalid")
+	ErrExceptionDoesntExist = errors.New("availability exception does not exist")
+	ErrInvalidRange         = errors.New("time range is invalid")
+	ErrRangeTooLarge        = errors.New("time range is too large")
+	ErrInvalidDuration      = errors.New("slot duration is invalid")
+)
+
+func (s *CoachService) GetCoaches(ctx context.Context) ([]repository.GetCoachesRow, error) {
+	return s.repository.GetCoaches(ctx)
+}
+
+func (s *CoachService) GetCoach(ctx context.Context, coachID int32) (*repository.GetCoachRow, error) {
+	coach, err := s.repository.GetCoach(ctx, coachID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCoachDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get coach: %w", err)
+	}
+	return &coach, nil
+}
+
+func (s *CoachService) UpdateProfile(ctx context.Context, coachID int32, profile Profile) (*repository.CoachProfile, error) {
+	if profile.Timezone == "" {
+		profile.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(profile.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+	if profile.Specialties == nil {
+		profile.Specialties = []string{}
+	}
+	if profile.Certifications == nil {
+		profile.Certifications = []string{}
+	}
+
+	updated, err := s.repository.UpsertCoachProfile(ctx, repository.UpsertCoachProfileParams{
+		UserID:         coachID,
+		Bio:            profile.Bio,
+		Specialties:    profile.Specialties,
+		Certifications: profile.Certifications,
+		Timezone:       profile.Timezone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert coach profile in db: %w", err)
+	}
+
+	return &updated, nil
+}
+
+func (s *CoachService) GetWeeklyAvailability(ctx context.Context, coachID int32) ([]repository.CoachAvailability, error) {
+	return s.repository.GetCoachAvailability(ctx, coachID)
+}
+
+// SetWeeklyAvailability replaces a coach's weekly availability windows.
+func (s *CoachService) SetWeeklyAvailability(ctx context.Context, coachID int32, windows []Window) ([]repository.CoachAvailability, error) {
+	params := repository.CreateCoachAvailabilityParams{CoachID: coachID}
+	for i, window := range windows {
+		if window.Weekday < time.Sunday || window.Weekday > time.Saturday ||
+			window.Start < 0 || window.End > 24*time.Hour || window.End <= window.Start {
+			return nil, fmt.Errorf("%w: window %d", ErrInvalidWindow, i)
+		}
+		for j, other := range windows[:i] {
+			if other.Weekday == window.Weekday && other.Start < window.End && window.Start < other.End {
+				return nil, fmt.Errorf("%w: windows %d and %d overlap", ErrInvalidWindow, j, i)
+			}
+		}
+
+		params.Weekdays = append(params.Weekdays, int16(window.Weekday))
+		params.StartTimes = append(params.StartTimes, toTime(window.Start))
+		params.EndTimes = append(params.EndTimes, toTime(window.End))
+	}
+
+	var availability []repository.CoachAvailability
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		if err := q.DeleteCoachAvailability(ctx, coachID); err != nil {
+			return fmt.Errorf("failed to delete coach availability in db: %w", err)
+		}
+
+		if len(windows) == 0 {
+			return nil
+		}
+
+		var err error
+		availability, err = q.CreateCoachAvailability(ctx, params)
+		if err != nil {
+			if repository.IsForeignKeyViolation(err) {
+				return ErrProfileRequired
+			}
+			return fmt.Errorf("failed to create coach availability in db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return availability, nil
+}
+
+// AddException blocks out time a coach isn't available.
+func (s *CoachService) AddException(ctx context.Context, coachID int32, startsAt, endsAt time.Time, reason string) (*repository.CoachAvailabilityException, error) {
+	if !endsAt.After(startsAt) {
+		return nil, ErrInvalidException
+	}
+
+	exception, err := s.repository.CreateCoachAvailabilityException(ctx, repository.CreateCoachAvailabilityExceptionParams{
+		CoachID:  coachID,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Reason:   reason,
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, ErrProfileRequired
+		}
+		return nil, fmt.Errorf("failed to create availability exception in db: %w", err)
+	}
+
+	return &exception, nil
+}
+
+func (s *CoachService) RemoveException(ctx context.Context, coachID, exceptionID int32) error {
+	deleted, err := s.repository.DeleteCoachAvailabilityException(ctx, repository.DeleteCoachAvailabilityExceptionParams{
+		ID:      exceptionID,
+		CoachID: coachID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete availability exception in db: %w", err)
+	}
+	if deleted == 0 {
+		return ErrExceptionDoesntExist
+	}
+	return nil
+}
+
+// GetAvailability returns a coach's open slots between from and to: their
+// weekly windows minus blocked-out time, the classes they teach and their
+// training sessions. Slots in the past are left out.
+func (s *CoachService) GetAvailability(ctx context.Context, coachID int32, from, to time.Time, duration time.Duration) ([]Slot, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	if to.Sub(from) > maxAvailabilityRange {
+		return nil, ErrRangeTooLarge
+	}
+	if duration <= 0 || duration > 24*time.Hour {
+		return nil, ErrInvalidDuration
+	}
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+
+	coach, err := s.GetCoach(ctx, coachID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(coach.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+
+	free, err := s.freeIntervals(ctx, coachID, loc, from, to, 0)
+	if err != nil {
+		return nil, err
+	}
+	return slotsIn(free, duration, slotStep), nil
+}
+
+// freeIntervals returns the times between from and to a coach is available and
+// not busy. The training session with ID ignoreSessionID doesn't count as busy,
+// so a session can be moved to a time overlapping itself.
+func (s *CoachService) freeIntervals(
+	ctx context.Context,
+	coachID int32,
+	loc *time.Location,
+	from, to time.Time,
+	ignoreSessionID int32,
+) ([]interval, error) {
+	windows, err := s.repository.GetCoachAvailability(ctx, coachID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coach availability: %w", err)
+	}
+
+	busy, err := s.busyIntervals(ctx, coachID, from, to, ignoreSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return subtract(weeklyIntervals(windows, loc, from, to), busy), nil
+}
+
+// busyIntervals returns the times a coach is blocked out, teaching a class or
+// giving a training session.
+func (s *CoachService) busyIntervals(ctx context.Context, coachID int32, from, to time.Time, ignoreSessionID int32) ([]interval, error) {
+	var busy []interval
+
+	exceptions, err := s.repository.GetCoachAvailabilityExceptionsBetween(ctx, repository.GetCoachAvailabilityExceptionsBetweenParams{
+		CoachID:  coachID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability exceptions: %w", err)
+	}
+	for _, e := range exceptions {
+		busy = append(busy, interval{start: e.StartsAt, end: e.EndsAt})
+	}
+
+	sessions, err := s.repository.GetCoachClassSessionsBetween(ctx, repository.GetCoachClassSessionsBetweenParams{
+		CoachID:  pgtype.Int4{Int32: coachID, Valid: true},
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coach classes: %w", err)
+	}
+	for _, session := range sessions {
+		busy = append(busy, interval{start: session.StartsAt, end: session.EndsAt})
+	}
+
+	trainings, err := s.repository.GetCoachTrainingSessionsBetween(ctx, repository.GetCoachTrainingSessionsBetweenParams{
+		CoachID:  coachID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coach training sessions: %w", err)
+	}
+	for _, training := range trainings {
+		if training.ID == ignoreSessionID {
+			continue
+		}
+		busy = append(busy, interval{start: training.StartsAt, end: training.EndsAt})
+	}
+
+	return busy, nil
+}
+
+func toTime(offset time.Duration) pgtype.Time {
+	return pgtype.Time{Microseconds: offset.Microseconds(), Valid: true}
+}
@@ -0,0 +1,110 @@
+package coaches
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+// interval is a half-open time range [start, end).
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// weeklyIntervals expands weekly availability windows into concrete
+// intervals between from and to. Windows are wall-clock times in loc, so they
+// stay put across DST changes.
+func weeklyIntervals(windows []repository.CoachAvailability, loc *time.Location, from, to time.Time) []interval {
+	var intervals []interval
+
+	first := from.In(loc)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, window := range windows {
+			if time.Weekday(window.Weekday) != day.Weekday() {
+				continue
+			}
+
+			start := atTimeOfDay(day, window.StartTime.Microseconds)
+			end := atTimeOfDay(day, window.EndTime.Microseconds)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if start.Before(end) {
+				intervals = append(intervals, interval{start: start, end: end})
+			}
+		}
+	}
+
+	return intervals
+}
+
+// subtract removes the busy intervals from the free ones.
+func subtract(free, busy []interval) []interval {
+	sort.Slice(busy, func(i, j int) bool { return busy[i].start.Before(busy[j].start) })
+
+	var result []interval
+	for _, f := range free {
+		current := []interval{f}
+		for _, b := range busy {
+			var next []interval
+			for _, c := range current {
+				if !b.start.Before(c.end) || !b.end.After(c.start) {
+					next = append(next, c)
+					continue
+				}
+				if c.start.Before(b.start) {
+					next = append(next, interval{start: c.start, end: b.start})
+				}
+				if b.end.Before(c.end) {
+					next = append(next, interval{start: b.end, end: c.end})
+				}
+			}
+			current = next
+		}
+		result = append(result, current...)
+	}
+
+	return result
+}
+
+// slotsIn cuts free intervals into slots of the given duration, starting every
+// step from the beginning of each interval.
+func slotsIn(free []interval, duration, step time.Duration) []Slot {
+	var slots []Slot
+	for _, f := range free {
+		for start := f.start; !start.Add(duration).After(f.end); start = start.Add(step) {
+			slots = append(slots, Slot{StartsAt: start, EndsAt: start.Add(duration)})
+		}
+	}
+	return slots
+}
+
+// covers reports whether the free intervals cover [start, end) without gaps.
+func covers(free []interval, start, end time.Time) bool {
+	sort.Slice(free, func(i, j int) bool { return free[i].start.Before(free[j].start) })
+
+	reached := start
+	for _, f := range free {
+		if f.start.After(reached) {
+			break
+		}
+		if f.end.After(reached) {
+			reached = f.end
+		}
+		if !reached.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+func atTimeOfDay(day time.Time, microseconds int64) time.Time {
+	d := time.Duration(microseconds) * time.Microsecond
+	return time.Date(day.Year(), day.Month(), day.Day(), int(d.Hours()), int(d.Minutes())%60, 0, 0, day.Location())
+}
@@ -0,0 +1,290 @@
+package coaches
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// clock returns the time on Monday 2 March 2026 in UTC.
+func clock(hour, minute int) time.Time {
+	return time.Date(2026, 3, 2, hour, minute, 0, 0, time.UTC)
+}
+
+func window(weekday time.Weekday, startHour, endHour int) repository.CoachAvailability {
+	microseconds := func(hour int) pgtype.Time {
+		return pgtype.Time{Microseconds: int64(time.Duration(hour) * time.Hour / time.Microsecond), Valid: true}
+	}
+	return repository.CoachAvailability{
+		Weekday:   int16(weekday),
+		StartTime: microseconds(startHour),
+		EndTime:   microseconds(endHour),
+	}
+}
+
+func checkIntervals(t *testing.T, got, want []interval) {
+	t.Helper()
+	equal := slices.EqualFunc(got, want, func(a, b interval) bool {
+		return a.start.Equal(b.start) && a.end.Equal(b.end)
+	})
+	if !equal {
+		t.Errorf("got intervals %v, want %v", got, want)
+	}
+}
+
+func TestWeeklyIntervals(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, madrid)
+	}
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		windows  []repository.CoachAvailability
+		from, to time.Time
+		want     []interval
+	}{
+		{
+			name:    "every matching weekday",
+			windows: []repository.CoachAvailability{window(time.Monday, 9, 12)},
+			from:    local(time.March, 2, 0, 0),
+			to:      local(time.March, 16, 0, 0),
+			want: []interval{
+				{start: local(time.March, 2, 9, 0), end: local(time.March, 2, 12, 0)},
+				{start: local(time.March, 9, 9, 0), end: local(time.March, 9, 12, 0)},
+			},
+		},
+		{
+			name:    "several windows a day",
+			windows: []repository.CoachAvailability{window(time.Monday, 9, 12), window(time.Monday, 14, 16)},
+			from:    local(time.March, 2, 0, 0),
+			to:      local(time.March, 3, 0, 0),
+			want: []interval{
+				{start: local(time.March, 2, 9, 0), end: local(time.March, 2, 12, 0)},
+				{start: local(time.March, 2, 14, 0), end: local(time.March, 2, 16, 0)},
+			},
+		},
+		{
+			// Clocks go forward on Sunday 29 March
+			name:    "start of summer time",
+			windows: []repository.CoachAvailability{window(time.Sunday, 18, 20)},
+			from:    local(time.March, 22, 0, 0),
+			to:      local(time.March, 30, 0, 0),
+			want: []interval{
+				{start: utc(time.March, 22, 17), end: utc(time.March, 22, 19)},
+				{start: utc(time.March, 29, 16), end: utc(time.March, 29, 18)},
+			},
+		},
+		{
+			// Clocks go back on Sunday 25 October
+			name:    "end of summer time",
+			windows: []repository.CoachAvailability{window(time.Sunday, 18, 20)},
+			from:    local(time.October, 18, 0, 0),
+			to:      local(time.October, 26, 0, 0),
+			want: []interval{
+				{start: utc(time.October, 18, 16), end: utc(time.October, 18, 18)},
+				{start: utc(time.October, 25, 17), end: utc(time.October, 25, 19)},
+			},
+		},
+		{
+			name:    "window spanning the clock change",
+			windows: []repository.CoachAvailability{window(time.Sunday, 0, 6)},
+			from:    local(time.March, 29, 0, 0),
+			to:      local(time.March, 30, 0, 0),
+			want:    []interval{{start: utc(time.March, 28, 23), end: utc(time.March, 29, 4)}},
+		},
+		{
+			name:    "clamped to the range",
+			windows: []repository.CoachAvailability{window(time.Monday, 9, 12)},
+			from:    local(time.March, 2, 10, 15),
+			to:      local(time.March, 2, 11, 0),
+			want:    []interval{{start: local(time.March, 2, 10, 15), end: local(time.March, 2, 11, 0)}},
+		},
+		{
+			name:    "range ending as the window starts",
+			windows: []repository.CoachAvailability{window(time.Monday, 9, 12)},
+			from:    local(time.March, 2, 0, 0),
+			to:      local(time.March, 2, 9, 0),
+		},
+		{
+			// 23:30 UTC on Sunday is already Monday in Madrid
+			name:    "range given in another timezone",
+			windows: []repository.CoachAvailability{window(time.Monday, 9, 10), window(time.Sunday, 9, 10)},
+			from:    utc(time.March, 1, 23).Add(30 * time.Minute),
+			to:      utc(time.March, 2, 12),
+			want:    []interval{{start: local(time.March, 2, 9, 0), end: local(time.March, 2, 10, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkIntervals(t, weeklyIntervals(tt.windows, madrid, tt.from, tt.to), tt.want)
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	tests := []struct {
+		name string
+		free []interval
+		busy []interval
+		want []interval
+	}{
+		{
+			name: "nothing busy",
+			free: []interval{{start: clock(9, 0), end: clock(11, 0)}},
+			want: []interval{{start: clock(9, 0), end: clock(11, 0)}},
+		},
+		{
+			name: "busy in the middle",
+			free: []interval{{start: clock(9, 0), end: clock(12, 0)}},
+			busy: []interval{{start: clock(10, 0), end: clock(11, 0)}},
+			want: []interval{{start: clock(9, 0), end: clock(10, 0)}, {start: clock(11, 0), end: clock(12, 0)}},
+		},
+		{
+			name: "busy across several windows",
+			free: []interval{{start: clock(9, 0), end: clock(11, 0)}, {start: clock(14, 0), end: clock(16, 0)}},
+			busy: []interval{{start: clock(10, 0), end: clock(15, 0)}},
+			want: []interval{{start: clock(9, 0), end: clock(10, 0)}, {start: clock(15, 0), end: clock(16, 0)}},
+		},
+		{
+			name: "busy touching the window",
+			free: []interval{{start: clock(9, 0), end: clock(11, 0)}},
+			busy: []interval{{start: clock(8, 0), end: clock(9, 0)}, {start: clock(11, 0), end: clock(12, 0)}},
+			want: []interval{{start: clock(9, 0), end: clock(11, 0)}},
+		},
+		{
+			name: "busy the whole window",
+			free: []interval{{start: clock(9, 0), end: clock(11, 0)}},
+			busy: []interval{{start: clock(8, 0), end: clock(12, 0)}},
+		},
+		{
+			name: "overlapping busy intervals out of order",
+			free: []interval{{start: clock(9, 0), end: clock(12, 0)}},
+			busy: []interval{{start: clock(10, 0), end: clock(11, 0)}, {start: clock(9, 30), end: clock(10, 30)}},
+			want: []interval{{start: clock(9, 0), end: clock(9, 30)}, {start: clock(11, 0), end: clock(12, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkIntervals(t, subtract(tt.free, tt.busy), tt.want)
+		})
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		name       string
+		free       []interval
+		start, end time.Time
+		want       bool
+	}{
+		{
+			name:  "inside a window",
+			free:  []interval{{start: clock(9, 0), end: clock(12, 0)}},
+			start: clock(10, 0),
+			end:   clock(11, 0),
+			want:  true,
+		},
+		{
+			name:  "exactly a window",
+			free:  []interval{{start: clock(9, 0), end: clock(10, 0)}},
+			start: clock(9, 0),
+			end:   clock(10, 0),
+			want:  true,
+		},
+		{
+			name:  "across touching windows out of order",
+			free:  []interval{{start: clock(10, 0), end: clock(11, 0)}, {start: clock(9, 0), end: clock(10, 0)}},
+			start: clock(9, 30),
+			end:   clock(10, 30),
+			want:  true,
+		},
+		{
+			name:  "across a gap",
+			free:  []interval{{start: clock(9, 0), end: clock(10, 0)}, {start: clock(10, 15), end: clock(11, 0)}},
+			start: clock(9, 30),
+			end:   clock(10, 30),
+		},
+		{
+			name:  "starting before the window",
+			free:  []interval{{start: clock(9, 0), end: clock(12, 0)}},
+			start: clock(8, 30),
+			end:   clock(9, 30),
+		},
+		{
+			name:  "ending after the window",
+			free:  []interval{{start: clock(9, 0), end: clock(12, 0)}},
+			start: clock(11, 30),
+			end:   clock(12, 30),
+		},
+		{name: "no windows", start: clock(9, 0), end: clock(10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := covers(tt.free, tt.start, tt.end); got != tt.want {
+				t.Errorf("covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlotsIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		free     []interval
+		duration time.Duration
+		want     []time.Time
+	}{
+		{
+			name:     "every step that fits",
+			free:     []interval{{start: clock(9, 0), end: clock(10, 30)}},
+			duration: time.Hour,
+			want:     []time.Time{clock(9, 0), clock(9, 30)},
+		},
+		{
+			name:     "window shorter than a slot",
+			free:     []interval{{start: clock(9, 0), end: clock(9, 45)}},
+			duration: time.Hour,
+		},
+		{
+			// A range clamped to now starts mid-window
+			name:     "window starting off the hour",
+			free:     []interval{{start: clock(10, 15), end: clock(12, 0)}},
+			duration: time.Hour,
+			want:     []time.Time{clock(10, 15), clock(10, 45)},
+		},
+		{
+			name:     "several windows",
+			free:     []interval{{start: clock(9, 0), end: clock(10, 0)}, {start: clock(11, 0), end: clock(12, 0)}},
+			duration: time.Hour,
+			want:     []time.Time{clock(9, 0), clock(11, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var starts []time.Time
+			for _, slot := range slotsIn(tt.free, tt.duration, slotStep) {
+				if got := slot.EndsAt.Sub(slot.StartsAt); got != tt.duration {
+					t.Errorf("slot at %v lasts %v, want %v", slot.StartsAt, got, tt.duration)
+				}
+				starts = append(starts, slot.StartsAt)
+			}
+			if !slices.EqualFunc(starts, tt.want, time.Time.Equal) {
+				t.Errorf("slotsIn() starts = %v, want %v", starts, tt.want)
+			}
+		})
+	}
+}
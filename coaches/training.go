@@ -0,0 +1,366 @@
+package coaches
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	minSessionLength = 30 * time.Minute
+	maxSessionLength = 3 * time.Hour
+
+	SessionStatusRequested = "requested"
+	SessionStatusConfirmed = "confirmed"
+	SessionStatusDeclined  = "declined"
+	SessionStatusCancelled = "cancelled"
+)
+
+var (
+	ErrSessionDoesntExist = errors.New("training session does not exist")
+	ErrInvalidSession     = errors.New("training session is invalid")
+	ErrOwnSession         = errors.New("coaches can't book training sessions with themselves")
+	ErrSlotUnavailable    = errors.New("coach is not available at that time")
+	ErrSlotTaken          = errors.New("coach already has a training session at that time")
+	ErrSessionNotPending  = errors.New("training session is not awaiting an answer")
+	ErrSessionClosed      = errors.New("training session is declined, cancelled or over")
+	ErrNoReschedule       = errors.New("training session has no reschedule request")
+	ErrOwnReschedule      = errors.New("reschedule requests must be answered by the other side")
+)
+
+// RequestSession asks a coach for a training session. The time must be open
+// in the coach's availability. The slot is held until the coach answers.
+func (s *CoachService) RequestSession(
+	ctx context.Context,
+	coachID, memberID int32,
+	startsAt, endsAt time.Time,
+	note string,
+) (*repository.TrainingSession, error) {
+	if coachID == memberID {
+		return nil, ErrOwnSession
+	}
+	if err := validateSessionTime(startsAt, endsAt); err != nil {
+		return nil, err
+	}
+	if err := s.checkAvailable(ctx, coachID, startsAt, endsAt, 0); err != nil {
+		return nil, err
+	}
+
+	session, err := s.repository.CreateTrainingSession(ctx, repository.CreateTrainingSessionParams{
+		CoachID:  coachID,
+		MemberID: memberID,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Note:     note,
+	})
+	if err != nil {
+		if repository.IsExclusionViolation(err) {
+			return nil, ErrSlotTaken
+		}
+		return nil, fmt.Errorf("failed to create training session in db: %w", err)
+	}
+
+	return &session, nil
+}
+
+func (s *CoachService) GetSessions(ctx context.Context, userID int32) ([]repository.TrainingSession, error) {
+	return s.repository.GetTrainingSessionsByUserID(ctx, userID)
+}
+
+// AcceptSession confirms a requested session.
+func (s *CoachService) AcceptSession(ctx context.Context, sessionID, coachID int32) (*repository.TrainingSession, error) {
+	return s.answerRequest(ctx, sessionID, coachID, SessionStatusConfirmed, "")
+}
+
+// DeclineSession turns down a requested session and frees the slot.
+func (s *CoachService) DeclineSession(ctx context.Context, sessionID, coachID int32, reason string) (*repository.TrainingSession, error) {
+	return s.answerRequest(ctx, sessionID, coachID, SessionStatusDeclined, reason)
+}
+
+func (s *CoachService) answerRequest(ctx context.Context, sessionID, coachID int32, status, reason string) (*repository.TrainingSession, error) {
+	var session repository.TrainingSession
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		session, err = s.lockSession(ctx, q, sessionID, coachID)
+		if err != nil {
+			return err
+		}
+		// Only the coach answers requests
+		if session.CoachID != coachID {
+			return ErrSessionDoesntExist
+		}
+		if session.Status != SessionStatusRequested {
+			return ErrSessionNotPending
+		}
+		if status == SessionStatusConfirmed && !session.StartsAt.After(time.Now()) {
+			return ErrSessionClosed
+		}
+
+		session, err = q.SetTrainingSessionStatus(ctx, repository.SetTrainingSessionStatusParams{
+			ID:            sessionID,
+			Status:        status,
+			DeclineReason: reason,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to answer training session in db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// CancelSession cancels a requested or confirmed session. Either side can
+// cancel.
+func (s *CoachService) CancelSession(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error) {
+	var session repository.TrainingSession
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		session, err = s.lockSession(ctx, q, sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if !isOpen(session) {
+			return ErrSessionClosed
+		}
+
+		session, err = q.SetTrainingSessionStatus(ctx, repository.SetTrainingSessionStatusParams{
+			ID:          sessionID,
+			Status:      SessionStatusCancelled,
+			CancelledBy: pgtype.Int4{Int32: userID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to cancel training session in db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ProposeReschedule asks the other side to move a session to a new time. A
+// member can only propose times open in the coach's availability, a coach can
+// propose any time they aren't already busy with another session. A new
+// proposal replaces the previous one.
+func (s *CoachService) ProposeReschedule(
+	ctx context.Context,
+	sessionID, userID int32,
+	startsAt, endsAt time.Time,
+) (*repository.TrainingSession, error) {
+	if err := validateSessionTime(startsAt, endsAt); err != nil {
+		return nil, err
+	}
+
+	var session repository.TrainingSession
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		session, err = s.lockSession(ctx, q, sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if !isOpen(session) {
+			return ErrSessionClosed
+		}
+
+		if session.MemberID == userID {
+			if err := s.checkAvailable(ctx, session.CoachID, startsAt, endsAt, session.ID); err != nil {
+				return err
+			}
+		}
+
+		session, err = q.ProposeTrainingSessionTime(ctx, repository.ProposeTrainingSessionTimeParams{
+			ID:               sessionID,
+			ProposedStartsAt: pgtype.Timestamptz{Time: startsAt, Valid: true},
+			ProposedEndsAt:   pgtype.Timestamptz{Time: endsAt, Valid: true},
+			ProposedBy:       pgtype.Int4{Int32: userID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to propose training session time in db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// AcceptReschedule moves a session to the time the other side proposed. A
+// session the coach hadn't answered yet is confirmed by the move, since both
+// sides have now agreed on the time.
+func (s *CoachService) AcceptReschedule(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error) {
+	var session repository.TrainingSession
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		session, err = s.lockPendingReschedule(ctx, q, sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if !session.ProposedStartsAt.Time.After(time.Now()) {
+			return ErrSessionClosed
+		}
+
+		session, err = q.MoveTrainingSession(ctx, sessionID)
+		if err != nil {
+			if repository.IsExclusionViolation(err) {
+				return ErrSlotTaken
+			}
+			return fmt.Errorf("failed to move training session in db: %w", err)
+		}
+
+		if session.Status == SessionStatusRequested {
+			session, err = q.SetTrainingSessionStatus(ctx, repository.SetTrainingSessionStatusParams{
+				ID:     sessionID,
+				Status: SessionStatusConfirmed,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to confirm training session in db: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// DeclineReschedule turns down a proposed time. The session stays at its
+// current time.
+func (s *CoachService) DeclineReschedule(ctx context.Context, sessionID, userID int32) (*repository.TrainingSession, error) {
+	var session repository.TrainingSession
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		if _, err = s.lockPendingReschedule(ctx, q, sessionID, userID); err != nil {
+			return err
+		}
+
+		session, err = q.ClearTrainingSessionProposal(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to decline reschedule in db: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (s *CoachService) GetSessionParticipants(ctx context.Context, sessionID int32) (*repository.GetTrainingSessionParticipantsRow, error) {
+	participants, err := s.repository.GetTrainingSessionParticipants(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get training session participants: %w", err)
+	}
+	return &participants, nil
+}
+
+// ClaimReminders returns confirmed sessions starting within lead that haven't
+// been reminded about yet, and marks them as reminded.
+func (s *CoachService) ClaimReminders(ctx context.Context, lead time.Duration) ([]repository.ClaimTrainingSessionRemindersRow, error) {
+	reminders, err := s.repository.ClaimTrainingSessionReminders(ctx, time.Now().Add(lead))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim training session reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// lockSession locks a session for the member or coach taking part in it.
+// Sessions of other users are reported as missing.
+func (s *CoachService) lockSession(ctx context.Context, q *repository.Queries, sessionID, userID int32) (repository.TrainingSession, error) {
+	session, err := q.GetTrainingSessionForUpdate(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return session, ErrSessionDoesntExist
+		}
+		return session, fmt.Errorf("failed to get training session: %w", err)
+	}
+	if session.MemberID != userID && session.CoachID != userID {
+		return session, ErrSessionDoesntExist
+	}
+	return session, nil
+}
+
+// lockPendingReschedule locks a session with a reschedule request the user
+// can answer.
+func (s *CoachService) lockPendingReschedule(ctx context.Context, q *repository.Queries, sessionID, userID int32) (repository.TrainingSession, error) {
+	session, err := s.lockSession(ctx, q, sessionID, userID)
+	if err != nil {
+		return session, err
+	}
+	if !isOpen(session) {
+		return session, ErrSessionClosed
+	}
+	if !session.ProposedStartsAt.Valid {
+		return session, ErrNoReschedule
+	}
+	if session.ProposedBy.Valid && session.ProposedBy.Int32 == userID {
+		return session, ErrOwnReschedule
+	}
+	return session, nil
+}
+
+// checkAvailable checks that [startsAt, endsAt) is open in the coach's
+// availability.
+func (s *CoachService) checkAvailable(ctx context.Context, coachID int32, startsAt, endsAt time.Time, ignoreSessionID int32) error {
+	coach, err := s.GetCoach(ctx, coachID)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(coach.Timezone)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+
+	free, err := s.freeIntervals(ctx, coachID, loc, startsAt, endsAt, ignoreSessionID)
+	if err != nil {
+		return err
+	}
+	if !covers(free, startsAt, endsAt) {
+		return ErrSlotUnavailable
+	}
+	return nil
+}
+
+func validateSessionTime(startsAt, endsAt time.Time) error {
+	if !startsAt.After(time.Now()) {
+		return fmt.Errorf("%w: it must start in the future", ErrInvalidSession)
+	}
+	length := endsAt.Sub(startsAt)
+	if length < minSessionLength || length > maxSessionLength {
+		return fmt.Errorf(
+			"%w: it must last between %d and %d minutes",
+			ErrInvalidSession, int(minSessionLength.Minutes()), int(maxSessionLength.Minutes()),
+		)
+	}
+	return nil
+}
+
+// isOpen reports whether a session is still requested or confirmed and hasn't
+// started yet.
+func isOpen(session repository.TrainingSession) bool {
+	return (session.Status == SessionStatusRequested || session.Status == SessionStatusConfirmed) &&
+		session.StartsAt.After(time.Now())
+}
@@ -0,0 +1,36 @@
+package credits
+
+import "time"
+
+type AddCreditsRequest struct {
+	// Kind is purchase or adjustment
+	Kind string `json:"kind"`
+	// Amount is the number of credits, adjustments may be negative
+	Amount int32 `json:"amount"`
+	// ExpiresAt is when credits added expire, omitted means never
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason"`
+}
+
+type RefundRequest struct {
+	Reason string `json:"reason"`
+}
+
+type LedgerEntryResponse struct {
+	ID             int32      `json:"id"`
+	Kind           string     `json:"kind"`
+	Delta          int32      `json:"delta"`
+	LotID          *int32     `json:"lot_id"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	SubscriptionID *int32     `json:"subscription_id"`
+	AttendanceID   *int32     `json:"attendance_id"`
+	RefundOf       *int32     `json:"refund_of"`
+	Reason         string     `json:"reason"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type LedgerResponse struct {
+	UserID  int32                 `json:"user_id"`
+	Balance int32                 `json:"balance"`
+	Entries []LedgerEntryResponse `json:"entries"`
+}
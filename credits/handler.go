@@ -0,0 +1,174 @@
+package credits
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreditHandlers struct {
+	cService ICreditService
+}
+
+func NewCreditHandlers(cService ICreditService) *CreditHandlers {
+	return &CreditHandlers{
+		cService: cService,
+	}
+}
+
+func (h *CreditHandlers) GetMyCredits(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writeLedger(w, r, userID)
+}
+
+func (h *CreditHandlers) GetUserCredits(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writeLedger(w, r, userID)
+}
+
+func (h *CreditHandlers) AddCredits(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var addRequest AddCreditsRequest
+	if err := validate.DecodeJSON(w, r, &addRequest); err != nil {
+		return err
+	}
+
+	var entries []repository.CreditLedger
+	switch EntryKind(addRequest.Kind) {
+	case EntryKindPurchase:
+		entries, err = h.cService.Purchase(r.Context(), userID, addRequest.Amount, addRequest.ExpiresAt, addRequest.Reason, staffID)
+	case EntryKindAdjustment:
+		entries, err = h.cService.Adjust(r.Context(), userID, addRequest.Amount, addRequest.ExpiresAt, addRequest.Reason, staffID)
+	default:
+		return apperror.BadRequest("kind must be purchase or adjustment")
+	}
+	if err != nil {
+		return creditError(err)
+	}
+
+	resp := make([]LedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, toLedgerEntryResponse(entry))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+func (h *CreditHandlers) Refund(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	entryID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Entry ID is invalid")
+	}
+
+	var refundRequest RefundRequest
+	if err := validate.DecodeJSON(w, r, &refundRequest); err != nil {
+		return err
+	}
+
+	refund, err := h.cService.Refund(r.Context(), entryID, refundRequest.Reason, staffID)
+	if err != nil {
+		return creditError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toLedgerEntryResponse(*refund), http.StatusCreated)
+	return nil
+}
+
+func (h *CreditHandlers) writeLedger(w http.ResponseWriter, r *http.Request, userID int32) error {
+	ledger, err := h.cService.GetLedger(r.Context(), userID)
+	if err != nil {
+		return creditError(err)
+	}
+
+	resp := LedgerResponse{
+		UserID:  userID,
+		Balance: ledger.Balance,
+		Entries: make([]LedgerEntryResponse, 0, len(ledger.Entries)),
+	}
+	for _, entry := range ledger.Entries {
+		resp.Entries = append(resp.Entries, toLedgerEntryResponse(entry))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func creditError(err error) error {
+	switch {
+	case errors.Is(err, ErrMemberDoesntExist):
+		return apperror.New(http.StatusNotFound, "member_doesnt_exist", "Member does not exist").Wrap(err)
+	case errors.Is(err, ErrEntryDoesntExist):
+		return apperror.New(http.StatusNotFound, "entry_doesnt_exist", "Ledger entry does not exist").Wrap(err)
+	case errors.Is(err, ErrInvalidAmount):
+		return apperror.New(http.StatusBadRequest, "invalid_amount", err.Error()).Wrap(err)
+	case errors.Is(err, ErrReasonRequired):
+		return apperror.New(http.StatusBadRequest, "reason_required", err.Error()).Wrap(err)
+	case errors.Is(err, ErrCreditsInPast):
+		return apperror.New(http.StatusBadRequest, "credits_in_past", err.Error()).Wrap(err)
+	case errors.Is(err, ErrEntryNotRefundable):
+		return apperror.New(http.StatusBadRequest, "entry_not_refundable", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInsufficientCredits):
+		return apperror.New(http.StatusConflict, "insufficient_credits", err.Error()).Wrap(err)
+	case errors.Is(err, ErrAlreadyRefunded):
+		return apperror.New(http.StatusConflict, "already_refunded", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func toLedgerEntryResponse(entry repository.CreditLedger) LedgerEntryResponse {
+	resp := LedgerEntryResponse{
+		ID:             entry.ID,
+		Kind:           entry.Kind,
+		Delta:          entry.Delta,
+		LotID:          fromInt4(entry.LotID),
+		SubscriptionID: fromInt4(entry.SubscriptionID),
+		AttendanceID:   fromInt4(entry.AttendanceID),
+		RefundOf:       fromInt4(entry.RefundOf),
+		Reason:         entry.Reason,
+		CreatedAt:      entry.CreatedAt,
+	}
+	if entry.ExpiresAt.Valid {
+		resp.ExpiresAt = &entry.ExpiresAt.Time
+	}
+	return resp
+}
+
+func fromInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
@@ -0,0 +1,15 @@
+package credits
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type ICreditService interface {
+	GetLedger(ctx context.Context, userID int32) (*Ledger, error)
+	Purchase(ctx context.Context, userID, amount int32, expiresAt *time.Time, reason string, staffID int32) ([]repository.CreditLedger, error)
+	Adjust(ctx context.Context, userID, delta int32, expiresAt *time.Time, reason string, staffID int32) ([]repository.CreditLedger, error)
+	Refund(ctx context.Context, entryID int32, reason string, staffID int32) (*repository.CreditLedger, error)
+}
@@ -0,0 +1,233 @@
+package credits
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The functions in this file write to the ledger and must run inside a
+// transaction. They lock the member's users row first so concurrent writes
+// to the same ledger are serialized.
+
+// Grant is a lot of credits added to a member's ledger.
+type Grant struct {
+	UserID int32
+	Kind   EntryKind
+	Amount int32
+	// ExpiresAt is when unused credits of the lot expire, nil means never
+	ExpiresAt      *time.Time
+	SubscriptionID *int32
+	Reason         string
+	CreatedBy      *int32
+}
+
+// AddCredits adds a lot of credits to a member's ledger.
+func AddCredits(ctx context.Context, q *repository.Queries, grant Grant) (repository.CreditLedger, error) {
+	if err := lockLedger(ctx, q, grant.UserID); err != nil {
+		return repository.CreditLedger{}, err
+	}
+
+	entry, err := q.CreateCreditLedgerEntry(ctx, repository.CreateCreditLedgerEntryParams{
+		UserID:         grant.UserID,
+		Kind:           string(grant.Kind),
+		Delta:          grant.Amount,
+		ExpiresAt:      toTimestamptz(grant.ExpiresAt),
+		SubscriptionID: toInt4(grant.SubscriptionID),
+		Reason:         grant.Reason,
+		CreatedBy:      toInt4(grant.CreatedBy),
+	})
+	if err != nil {
+		return entry, fmt.Errorf("failed to create credit ledger entry in db: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ConsumeForVisit takes one credit from a member for a recorded class visit.
+// Members on an unlimited membership don't use credits, nil is returned for
+// them.
+func ConsumeForVisit(ctx context.Context, q *repository.Queries, attendance repository.Attendance) (*repository.CreditLedger, error) {
+	unlimited, err := q.HasUnlimitedMembership(ctx, attendance.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if unlimited {
+		return nil, nil
+	}
+
+	entries, err := deduct(ctx, q, attendance.UserID, 1, repository.CreateCreditLedgerEntryParams{
+		Kind:         string(EntryKindConsumption),
+		AttendanceID: pgtype.Int4{Int32: attendance.ID, Valid: true},
+		Reason:       "class visit",
+		CreatedBy:    attendance.RecordedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+// TakePenaltyCredit takes one credit from a member as a penalty.
+// ErrInsufficientCredits is returned when the member has none left.
+func TakePenaltyCredit(ctx context.Context, q *repository.Queries, userID int32, reason string) (*repository.CreditLedger, error) {
+	entries, err := deduct(ctx, q, userID, 1, repository.CreateCreditLedgerEntryParams{
+		Kind:   string(EntryKindPenalty),
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+// RefundEntry gives back the credits taken by a class visit or a penalty.
+// They return to the lot they were taken from, so they keep its expiry.
+func RefundEntry(ctx context.Context, q *repository.Queries, entryID int32, reason string, staffID int32) (*repository.CreditLedger, error) {
+	entry, err := q.GetCreditLedgerEntryByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEntryDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get ledger entry: %w", err)
+	}
+	if kind := EntryKind(entry.Kind); kind != EntryKindConsumption && kind != EntryKindPenalty {
+		return nil, ErrEntryNotRefundable
+	}
+
+	if err := lockLedger(ctx, q, entry.UserID); err != nil {
+		return nil, err
+	}
+
+	refund, err := q.CreateCreditLedgerEntry(ctx, repository.CreateCreditLedgerEntryParams{
+		UserID:       entry.UserID,
+		Kind:         string(EntryKindRefund),
+		Delta:        -entry.Delta,
+		LotID:        entry.LotID,
+		AttendanceID: entry.AttendanceID,
+		RefundOf:     pgtype.Int4{Int32: entry.ID, Valid: true},
+		Reason:       reason,
+		CreatedBy:    pgtype.Int4{Int32: staffID, Valid: true},
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrAlreadyRefunded
+		}
+		return nil, fmt.Errorf("failed to create credit ledger entry in db: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// deduct takes amount credits from a member's lots, soonest to expire first.
+// A deduction spanning several lots is written as one entry per lot, each
+// based on the template.
+func deduct(
+	ctx context.Context,
+	q *repository.Queries,
+	userID, amount int32,
+	template repository.CreateCreditLedgerEntryParams,
+) ([]repository.CreditLedger, error) {
+	if err := lockLedger(ctx, q, userID); err != nil {
+		return nil, err
+	}
+	if err := expireCredits(ctx, q, userID); err != nil {
+		return nil, err
+	}
+
+	lots, err := q.GetOpenCreditLots(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit lots: %w", err)
+	}
+
+	var available int32
+	for _, lot := range lots {
+		available += lot.Remaining
+	}
+	if available < amount {
+		return nil, ErrInsufficientCredits
+	}
+
+	var entries []repository.CreditLedger
+	for _, lot := range lots {
+		if amount == 0 {
+			break
+		}
+
+		take := min(lot.Remaining, amount)
+		amount -= take
+
+		params := template
+		params.UserID = userID
+		params.Delta = -take
+		params.LotID = pgtype.Int4{Int32: lot.ID, Valid: true}
+		entry, err := q.CreateCreditLedgerEntry(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create credit ledger entry in db: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// expireCredits writes off the unused credits of a member's expired lots.
+func expireCredits(ctx context.Context, q *repository.Queries, userID int32) error {
+	lots, err := q.GetOpenCreditLots(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get credit lots: %w", err)
+	}
+
+	now := time.Now()
+	for _, lot := range lots {
+		// Lots are ordered by expiry, the rest haven't expired either
+		if !lot.ExpiresAt.Valid || lot.ExpiresAt.Time.After(now) {
+			break
+		}
+
+		_, err := q.CreateCreditLedgerEntry(ctx, repository.CreateCreditLedgerEntryParams{
+			UserID:    userID,
+			Kind:      string(EntryKindExpiration),
+			Delta:     -lot.Remaining,
+			LotID:     pgtype.Int4{Int32: lot.ID, Valid: true},
+			ExpiresAt: lot.ExpiresAt,
+			Reason:    "credits expired",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create credit ledger entry in db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func lockLedger(ctx context.Context, q *repository.Queries, userID int32) error {
+	if _, err := q.LockUserForUpdate(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberDoesntExist
+		}
+		return fmt.Errorf("failed to lock credit ledger: %w", err)
+	}
+	return nil
+}
+
+func toInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func toTimestamptz(v *time.Time) pgtype.Timestamptz {
+	if v == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *v, Valid: true}
+}
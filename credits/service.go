@@ -0,0 +1,181 @@
+package credits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type EntryKind string
+
+const (
+	EntryKindPurchase    EntryKind = "purchase"
+	EntryKindConsumption EntryKind = "consumption"
+	EntryKindRefund      EntryKind = "refund"
+	EntryKindAdjustment  EntryKind = "adjustment"
+	EntryKindExpiration  EntryKind = "expiration"
+	EntryKindPenalty     EntryKind = "penalty"
+)
+
+// Ledger is a member's credit balance together with its history, newest
+// entry first.
+type Ledger struct {
+	Balance int32
+	Entries []repository.CreditLedger
+}
+
+type CreditService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+}
+
+func NewCreditService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+) *CreditService {
+	return &CreditService{
+		db:         db,
+		repository: repository,
+	}
+}
+
+var (
+	ErrMemberDoesntExist   = errors.New("member does not exist")
+	ErrInsufficientCredits = errors.New("member has no credits left")
+	ErrInvalidAmount       = errors.New("amount is invalid")
+	ErrReasonRequired      = errors.New("a reason is required")
+	ErrCreditsInPast       = errors.New("credits can't expire in the past")
+	ErrEntryDoesntExist    = errors.New("ledger entry does not exist")
+	ErrEntryNotRefundable  = errors.New("only class visits and penalties can be refunded")
+	ErrAlreadyRefunded     = errors.New("ledger entry is already refunded")
+)
+
+// GetLedger returns a member's balance and history. Expired credits are
+// written off first so the balance only counts usable credits.
+func (s *CreditService) GetLedger(ctx context.Context, userID int32) (*Ledger, error) {
+	var ledger Ledger
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		if err := lockLedger(ctx, q, userID); err != nil {
+			return err
+		}
+		if err := expireCredits(ctx, q, userID); err != nil {
+			return err
+		}
+
+		var err error
+		ledger.Balance, err = q.GetCreditBalance(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get credit balance: %w", err)
+		}
+
+		ledger.Entries, err = q.GetCreditLedger(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get credit ledger: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ledger, nil
+}
+
+// Purchase adds credits a member bought outside of a membership plan.
+func (s *CreditService) Purchase(ctx context.Context, userID, amount int32, expiresAt *time.Time, reason string, staffID int32) ([]repository.CreditLedger, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrCreditsInPast
+	}
+
+	return s.add(ctx, Grant{
+		UserID:    userID,
+		Kind:      EntryKindPurchase,
+		Amount:    amount,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+		CreatedBy: &staffID,
+	})
+}
+
+// Adjust corrects a member's balance by delta credits. Credits taken away
+// come out of the lots that expire soonest, credits given are a new lot.
+func (s *CreditService) Adjust(ctx context.Context, userID, delta int32, expiresAt *time.Time, reason string, staffID int32) ([]repository.CreditLedger, error) {
+	if delta == 0 {
+		return nil, ErrInvalidAmount
+	}
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	if delta > 0 {
+		if expiresAt != nil && !expiresAt.After(time.Now()) {
+			return nil, ErrCreditsInPast
+		}
+		return s.add(ctx, Grant{
+			UserID:    userID,
+			Kind:      EntryKindAdjustment,
+			Amount:    delta,
+			ExpiresAt: expiresAt,
+			Reason:    reason,
+			CreatedBy: &staffID,
+		})
+	}
+
+	var entries []repository.CreditLedger
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		entries, err = deduct(ctx, q, userID, -delta, repository.CreateCreditLedgerEntryParams{
+			Kind:      string(EntryKindAdjustment),
+			Reason:    reason,
+			CreatedBy: pgtype.Int4{Int32: staffID, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Refund gives back the credit taken by a class visit or a penalty.
+func (s *CreditService) Refund(ctx context.Context, entryID int32, reason string, staffID int32) (*repository.CreditLedger, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+
+	var refund *repository.CreditLedger
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		refund, err = RefundEntry(ctx, q, entryID, reason, staffID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (s *CreditService) add(ctx context.Context, grant Grant) ([]repository.CreditLedger, error) {
+	var entry repository.CreditLedger
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		entry, err = AddCredits(ctx, q, grant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []repository.CreditLedger{entry}, nil
+}
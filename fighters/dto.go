@@ -0,0 +1,81 @@
+package fighters
+
+import "time"
+
+type ProfileRequest struct {
+	DisplayName string `json:"display_name"`
+	WeightClass string `json:"weight_class"`
+	HeightCm    int32  `json:"height_cm"`
+	ReachCm     int32  `json:"reach_cm"`
+	Stance      string `json:"stance"`
+	Level       string `json:"level"`
+	// Experience is beginner, intermediate or advanced at sparring
+	Experience string `json:"experience"`
+	// Public lists the profile in the public fighter registry
+	Public bool `json:"public"`
+	// ShowMeasurements and ShowBouts default to true
+	ShowMeasurements *bool `json:"show_measurements"`
+	ShowBouts        *bool `json:"show_bouts"`
+}
+
+type BoutRequest struct {
+	Opponent string `json:"opponent"`
+	Event    string `json:"event"`
+	// Date is formatted as 2006-01-02
+	Date   string `json:"date"`
+	Level  string `json:"level"`
+	Result string `json:"result"`
+	Method string `json:"method"`
+	Round  int32  `json:"round"`
+	Notes  string `json:"notes"`
+}
+
+type RecordResponse struct {
+	Wins       int32  `json:"wins"`
+	Losses     int32  `json:"losses"`
+	Draws      int32  `json:"draws"`
+	NoContests int32  `json:"no_contests"`
+	KOWins     int32  `json:"ko_wins"`
+	Summary    string `json:"summary"`
+}
+
+type RecordsResponse struct {
+	Total   RecordResponse `json:"total"`
+	Amateur RecordResponse `json:"amateur"`
+	Pro     RecordResponse `json:"pro"`
+}
+
+type BoutResponse struct {
+	ID       int32  `json:"id"`
+	Opponent string `json:"opponent"`
+	Event    string `json:"event"`
+	Date     string `json:"date"`
+	Level    string `json:"level"`
+	Result   string `json:"result"`
+	Method   string `json:"method"`
+	Round    *int32 `json:"round,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type FighterResponse struct {
+	ID          int32           `json:"id"`
+	DisplayName string          `json:"display_name"`
+	WeightClass string          `json:"weight_class"`
+	HeightCm    *int32          `json:"height_cm,omitempty"`
+	ReachCm     *int32          `json:"reach_cm,omitempty"`
+	Stance      string          `json:"stance"`
+	Level       string          `json:"level"`
+	Experience  string          `json:"experience"`
+	Record      RecordsResponse `json:"record"`
+	Bouts       []BoutResponse  `json:"bouts,omitempty"`
+}
+
+// PrivateFighterResponse is what the fighter and their coaches see, including
+// the privacy settings.
+type PrivateFighterResponse struct {
+	FighterResponse
+	Public           bool      `json:"public"`
+	ShowMeasurements bool      `json:"show_measurements"`
+	ShowBouts        bool      `json:"show_bouts"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,250 @@
+package fighters
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+const dateLayout = "2006-01-02"
+
+type FighterHandlers struct {
+	fService IFighterService
+}
+
+func NewFighterHandlers(fService IFighterService) *FighterHandlers {
+	return &FighterHandlers{
+		fService: fService,
+	}
+}
+
+func (h *FighterHandlers) GetFighters(w http.ResponseWriter, r *http.Request) error {
+	weightClass := r.URL.Query().Get("weight_class")
+	if weightClass != "" && !IsWeightClass(weightClass) {
+		return apperror.BadRequest("Weight class is invalid")
+	}
+
+	fighters, err := h.fService.GetPublicFighters(r.Context(), weightClass)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]FighterResponse, 0, len(fighters))
+	for _, fighter := range fighters {
+		resp = append(resp, toFighterResponse(fighter))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *FighterHandlers) GetFighter(w http.ResponseWriter, r *http.Request) error {
+	fighterID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Fighter ID is invalid")
+	}
+
+	fighter, err := h.fService.GetPublicFighter(r.Context(), fighterID)
+	if err != nil {
+		return fighterError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toFighterResponse(*fighter), http.StatusOK)
+	return nil
+}
+
+func (h *FighterHandlers) GetMyProfile(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writePrivateProfile(w, r, userID)
+}
+
+func (h *FighterHandlers) GetUserProfile(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writePrivateProfile(w, r, userID)
+}
+
+func (h *FighterHandlers) UpdateMyProfile(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var profileRequest ProfileRequest
+	if err := validate.DecodeJSON(w, r, &profileRequest); err != nil {
+		return err
+	}
+
+	profile := Profile{
+		DisplayName:      profileRequest.DisplayName,
+		WeightClass:      profileRequest.WeightClass,
+		HeightCm:         profileRequest.HeightCm,
+		ReachCm:          profileRequest.ReachCm,
+		Stance:           profileRequest.Stance,
+		Level:            profileRequest.Level,
+		Experience:       profileRequest.Experience,
+		Public:           profileRequest.Public,
+		ShowMeasurements: profileRequest.ShowMeasurements == nil || *profileRequest.ShowMeasurements,
+		ShowBouts:        profileRequest.ShowBouts == nil || *profileRequest.ShowBouts,
+	}
+	if _, err := h.fService.UpdateProfile(r.Context(), userID, profile); err != nil {
+		return fighterError(err)
+	}
+
+	return h.writePrivateProfile(w, r, userID)
+}
+
+func (h *FighterHandlers) RecordBout(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	fighterID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var boutRequest BoutRequest
+	if err := validate.DecodeJSON(w, r, &boutRequest); err != nil {
+		return err
+	}
+
+	date, err := time.Parse(dateLayout, boutRequest.Date)
+	if err != nil {
+		return apperror.BadRequest("Date must be formatted as YYYY-MM-DD")
+	}
+
+	bout, err := h.fService.RecordBout(r.Context(), fighterID, NewBout{
+		Opponent: boutRequest.Opponent,
+		Event:    boutRequest.Event,
+		Date:     date,
+		Level:    boutRequest.Level,
+		Result:   boutRequest.Result,
+		Method:   boutRequest.Method,
+		Round:    boutRequest.Round,
+		Notes:    boutRequest.Notes,
+	}, coachID)
+	if err != nil {
+		return fighterError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toBoutResponse(*bout), http.StatusCreated)
+	return nil
+}
+
+func (h *FighterHandlers) DeleteBout(w http.ResponseWriter, r *http.Request) error {
+	boutID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Bout ID is invalid")
+	}
+
+	if err := h.fService.DeleteBout(r.Context(), boutID); err != nil {
+		return fighterError(err)
+	}
+
+	users.WriteSuccess(w, "Bout deleted", http.StatusOK)
+	return nil
+}
+
+func (h *FighterHandlers) writePrivateProfile(w http.ResponseWriter, r *http.Request, userID int32) error {
+	fighter, err := h.fService.GetFighter(r.Context(), userID)
+	if err != nil {
+		return fighterError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, PrivateFighterResponse{
+		FighterResponse:  toFighterResponse(*fighter),
+		Public:           fighter.Profile.Public,
+		ShowMeasurements: fighter.Profile.ShowMeasurements,
+		ShowBouts:        fighter.Profile.ShowBouts,
+		UpdatedAt:        fighter.Profile.UpdatedAt,
+	}, http.StatusOK)
+	return nil
+}
+
+func fighterError(err error) error {
+	switch {
+	case errors.Is(err, ErrFighterDoesntExist):
+		return apperror.New(http.StatusNotFound, "fighter_doesnt_exist", "Fighter does not exist").Wrap(err)
+	case errors.Is(err, ErrBoutDoesntExist):
+		return apperror.New(http.StatusNotFound, "bout_doesnt_exist", "Bout does not exist").Wrap(err)
+	case errors.Is(err, ErrInvalidProfile):
+		return apperror.New(http.StatusBadRequest, "invalid_profile", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidBout):
+		return apperror.New(http.StatusBadRequest, "invalid_bout", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func toFighterResponse(fighter Fighter) FighterResponse {
+	resp := FighterResponse{
+		ID:          fighter.Profile.UserID,
+		DisplayName: fighter.Profile.DisplayName,
+		WeightClass: fighter.Profile.WeightClass,
+		Stance:      fighter.Profile.Stance,
+		Level:       fighter.Profile.Level,
+		Experience:  fighter.Profile.Experience,
+		Record: RecordsResponse{
+			Total:   toRecordResponse(fighter.Total()),
+			Amateur: toRecordResponse(fighter.Amateur),
+			Pro:     toRecordResponse(fighter.Pro),
+		},
+	}
+	if fighter.Profile.HeightCm.Valid {
+		resp.HeightCm = &fighter.Profile.HeightCm.Int32
+	}
+	if fighter.Profile.ReachCm.Valid {
+		resp.ReachCm = &fighter.Profile.ReachCm.Int32
+	}
+	for _, bout := range fighter.Bouts {
+		resp.Bouts = append(resp.Bouts, toBoutResponse(bout))
+	}
+	return resp
+}
+
+func toRecordResponse(record Record) RecordResponse {
+	return RecordResponse{
+		Wins:       record.Wins,
+		Losses:     record.Losses,
+		Draws:      record.Draws,
+		NoContests: record.NoContests,
+		KOWins:     record.KOWins,
+		Summary:    record.String(),
+	}
+}
+
+func toBoutResponse(bout repository.Bout) BoutResponse {
+	resp := BoutResponse{
+		ID:       bout.ID,
+		Opponent: bout.Opponent,
+		Event:    bout.Event,
+		Date:     bout.BoutDate.Format(dateLayout),
+		Level:    bout.Level,
+		Result:   bout.Result,
+		Method:   bout.Method,
+		Notes:    bout.Notes,
+	}
+	if bout.Round.Valid {
+		resp.Round = &bout.Round.Int32
+	}
+	return resp
+}
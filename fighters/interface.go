@@ -0,0 +1,16 @@
+package fighters
+
+import (
+	"context"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IFighterService interface {
+	GetFighter(ctx context.Context, fighterID int32) (*Fighter, error)
+	GetPublicFighter(ctx context.Context, fighterID int32) (*Fighter, error)
+	GetPublicFighters(ctx context.Context, weightClass string) ([]Fighter, error)
+	UpdateProfile(ctx context.Context, userID int32, profile Profile) (*repository.FighterProfile, error)
+	RecordBout(ctx context.Context, fighterID int32, bout NewBout, recordedBy int32) (*repository.Bout, error)
+	DeleteBout(ctx context.Context, boutID int32) error
+}
@@ -0,0 +1,430 @@
+package fighters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StanceOrthodox = "orthodox"
+	StanceSouthpaw = "southpaw"
+	StanceSwitch   = "switch"
+
+	LevelAmateur = "amateur"
+	LevelPro     = "pro"
+
+	ExperienceBeginner     = "beginner"
+	ExperienceIntermediate = "intermediate"
+	ExperienceAdvanced     = "advanced"
+
+	ResultWin       = "win"
+	ResultLoss      = "loss"
+	ResultDraw      = "draw"
+	ResultNoContest = "no_contest"
+
+	MethodDecision   = "decision"
+	MethodKO         = "ko"
+	MethodTKO        = "tko"
+	MethodRSC        = "rsc"
+	MethodDQ         = "dq"
+	MethodRetirement = "retirement"
+	MethodWalkover   = "walkover"
+
+	maxRounds = 12
+)
+
+// WeightClasses are the weight classes a fighter can compete in, lightest
+// first.
+var WeightClasses = []string{
+	"minimumweight", "light_flyweight", "flyweight", "super_flyweight",
+	"bantamweight", "super_bantamweight", "featherweight", "super_featherweight",
+	"lightweight", "super_lightweight", "welterweight", "super_welterweight",
+	"middleweight", "super_middleweight", "light_heavyweight", "cruiserweight",
+	"heavyweight",
+}
+
+// weightClassLimitsKg are the upper limits of the weight classes. Heavyweight
+// has none.
+var weightClassLimitsKg = map[string]float64{
+	"minimumweight":       47.6,
+	"light_flyweight":     49.0,
+	"flyweight":           50.8,
+	"super_flyweight":     52.2,
+	"bantamweight":        53.5,
+	"super_bantamweight":  55.3,
+	"featherweight":       57.2,
+	"super_featherweight": 59.0,
+	"lightweight":         61.2,
+	"super_lightweight":   63.5,
+	"welterweight":        66.7,
+	"super_welterweight":  69.9,
+	"middleweight":        72.6,
+	"super_middleweight":  76.2,
+	"light_heavyweight":   79.4,
+	"cruiserweight":       90.7,
+}
+
+// WeightClassLimitKg returns the upper limit of a weight class, false for
+// heavyweight and unknown classes.
+func WeightClassLimitKg(weightClass string) (float64, bool) {
+	limit, ok := weightClassLimitsKg[weightClass]
+	return limit, ok
+}
+
+// Profile holds the fields a fighter fills in about themselves. Zero
+// measurements are unknown.
+type Profile struct {
+	DisplayName      string
+	WeightClass      string
+	HeightCm         int32
+	ReachCm          int32
+	Stance           string
+	Level            string
+	Experience       string
+	Public           bool
+	ShowMeasurements bool
+	ShowBouts        bool
+}
+
+type NewBout struct {
+	Opponent string
+	Event    string
+	Date     time.Time
+	Level    string
+	Result   string
+	Method   string
+	// Round is the round the bout ended in, 0 if unknown
+	Round int32
+	Notes string
+}
+
+// Record is a fighter's win-loss-draw tally.
+type Record struct {
+	Wins       int32
+	Losses     int32
+	Draws      int32
+	NoContests int32
+	// KOWins are wins by KO, TKO or referee stopping the contest
+	KOWins int32
+}
+
+func (r Record) add(other Record) Record {
+	return Record{
+		Wins:       r.Wins + other.Wins,
+		Losses:     r.Losses + other.Losses,
+		Draws:      r.Draws + other.Draws,
+		NoContests: r.NoContests + other.NoContests,
+		KOWins:     r.KOWins + other.KOWins,
+	}
+}
+
+// String formats the record the usual way, e.g. 12-2-1.
+func (r Record) String() string {
+	return fmt.Sprintf("%d-%d-%d", r.Wins, r.Losses, r.Draws)
+}
+
+type Fighter struct {
+	Profile repository.FighterProfile
+	Amateur Record
+	Pro     Record
+	// Bouts are left out of fighter lists and of public profiles that hide
+	// them
+	Bouts []repository.Bout
+}
+
+func (f Fighter) Total() Record {
+	return f.Amateur.add(f.Pro)
+}
+
+type FighterService struct {
+	repository *repository.Queries
+}
+
+func NewFighterService(repository *repository.Queries) *FighterService {
+	return &FighterService{
+		repository: repository,
+	}
+}
+
+var (
+	ErrFighterDoesntExist = errors.New("fighter does not exist")
+	ErrBoutDoesntExist    = errors.New("bout does not exist")
+	ErrInvalidProfile     = errors.New("fighter profile is invalid")
+	ErrInvalidBout        = errors.New("bout is invalid")
+)
+
+// GetFighter returns a fighter's full profile and bouts, whatever their
+// privacy settings.
+func (s *FighterService) GetFighter(ctx context.Context, fighterID int32) (*Fighter, error) {
+	profile, err := s.repository.GetFighterProfile(ctx, fighterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFighterDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get fighter profile: %w", err)
+	}
+
+	return s.withBouts(ctx, profile)
+}
+
+// GetPublicFighter returns a publicly listed fighter, leaving out what they
+// chose to hide. Unlisted fighters are reported as missing.
+func (s *FighterService) GetPublicFighter(ctx context.Context, fighterID int32) (*Fighter, error) {
+	profile, err := s.repository.GetPublicFighterProfile(ctx, fighterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFighterDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get fighter profile: %w", err)
+	}
+
+	fighter, err := s.withBouts(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	redact(fighter)
+	return fighter, nil
+}
+
+// GetPublicFighters lists publicly listed fighters with their records, in an
+// optional weight class.
+func (s *FighterService) GetPublicFighters(ctx context.Context, weightClass string) ([]Fighter, error) {
+	profiles, err := s.repository.GetPublicFighterProfiles(ctx, pgtype.Text{String: weightClass, Valid: weightClass != ""})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter profiles: %w", err)
+	}
+
+	ids := make([]int32, 0, len(profiles))
+	for _, profile := range profiles {
+		ids = append(ids, profile.UserID)
+	}
+	records, err := s.getRecords(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	fighters := make([]Fighter, 0, len(profiles))
+	for _, profile := range profiles {
+		fighter := records[profile.UserID]
+		fighter.Profile = profile
+		redact(&fighter)
+		fighters = append(fighters, fighter)
+	}
+
+	return fighters, nil
+}
+
+func (s *FighterService) UpdateProfile(ctx context.Context, userID int32, profile Profile) (*repository.FighterProfile, error) {
+	if err := validateProfile(&profile); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repository.UpsertFighterProfile(ctx, repository.UpsertFighterProfileParams{
+		UserID:           userID,
+		DisplayName:      profile.DisplayName,
+		WeightClass:      profile.WeightClass,
+		HeightCm:         toInt4(profile.HeightCm),
+		ReachCm:          toInt4(profile.ReachCm),
+		Stance:           profile.Stance,
+		Level:            profile.Level,
+		Experience:       profile.Experience,
+		Public:           profile.Public,
+		ShowMeasurements: profile.ShowMeasurements,
+		ShowBouts:        profile.ShowBouts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert fighter profile in db: %w", err)
+	}
+
+	return &updated, nil
+}
+
+// RecordBout adds a bout to a fighter's record.
+func (s *FighterService) RecordBout(ctx context.Context, fighterID int32, bout NewBout, recordedBy int32) (*repository.Bout, error) {
+	if err := validateBout(bout); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repository.CreateBout(ctx, repository.CreateBoutParams{
+		FighterID:  fighterID,
+		Opponent:   bout.Opponent,
+		Event:      bout.Event,
+		BoutDate:   bout.Date,
+		Level:      bout.Level,
+		Result:     bout.Result,
+		Method:     bout.Method,
+		Round:      toInt4(bout.Round),
+		Notes:      bout.Notes,
+		RecordedBy: pgtype.Int4{Int32: recordedBy, Valid: true},
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, ErrFighterDoesntExist
+		}
+		return nil, fmt.Errorf("failed to create bout in db: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (s *FighterService) DeleteBout(ctx context.Context, boutID int32) error {
+	deleted, err := s.repository.DeleteBout(ctx, boutID)
+	if err != nil {
+		return fmt.Errorf("failed to delete bout in db: %w", err)
+	}
+	if deleted == 0 {
+		return ErrBoutDoesntExist
+	}
+	return nil
+}
+
+func (s *FighterService) withBouts(ctx context.Context, profile repository.FighterProfile) (*Fighter, error) {
+	bouts, err := s.repository.GetBoutsByFighterID(ctx, profile.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bouts: %w", err)
+	}
+
+	records, err := s.getRecords(ctx, []int32{profile.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	fighter := records[profile.UserID]
+	fighter.Profile = profile
+	fighter.Bouts = bouts
+	return &fighter, nil
+}
+
+// getRecords returns the amateur and pro records of the given fighters.
+func (s *FighterService) getRecords(ctx context.Context, fighterIDs []int32) (map[int32]Fighter, error) {
+	rows, err := s.repository.GetFighterRecords(ctx, fighterIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fighter records: %w", err)
+	}
+
+	records := make(map[int32]Fighter, len(fighterIDs))
+	for _, row := range rows {
+		fighter := records[row.FighterID]
+		record := Record{
+			Wins:       row.Wins,
+			Losses:     row.Losses,
+			Draws:      row.Draws,
+			NoContests: row.NoContests,
+			KOWins:     row.KoWins,
+		}
+		if row.Level == LevelPro {
+			fighter.Pro = record
+		} else {
+			fighter.Amateur = record
+		}
+		records[row.FighterID] = fighter
+	}
+
+	return records, nil
+}
+
+// redact strips what a fighter chose to keep off their public profile.
+func redact(fighter *Fighter) {
+	if !fighter.Profile.ShowMeasurements {
+		fighter.Profile.HeightCm = pgtype.Int4{}
+		fighter.Profile.ReachCm = pgtype.Int4{}
+	}
+	if !fighter.Profile.ShowBouts {
+		fighter.Bouts = nil
+	}
+}
+
+func validateProfile(profile *Profile) error {
+	if profile.DisplayName == "" {
+		return fmt.Errorf("%w: display name is required", ErrInvalidProfile)
+	}
+	if !IsWeightClass(profile.WeightClass) {
+		return fmt.Errorf("%w: unknown weight class %q", ErrInvalidProfile, profile.WeightClass)
+	}
+	if !validMeasurement(profile.HeightCm) || !validMeasurement(profile.ReachCm) {
+		return fmt.Errorf("%w: height and reach must be between 100 and 250 cm", ErrInvalidProfile)
+	}
+
+	if profile.Stance == "" {
+		profile.Stance = StanceOrthodox
+	}
+	switch profile.Stance {
+	case StanceOrthodox, StanceSouthpaw, StanceSwitch:
+	default:
+		return fmt.Errorf("%w: unknown stance %q", ErrInvalidProfile, profile.Stance)
+	}
+
+	if profile.Level == "" {
+		profile.Level = LevelAmateur
+	}
+	if profile.Level != LevelAmateur && profile.Level != LevelPro {
+		return fmt.Errorf("%w: level must be amateur or pro", ErrInvalidProfile)
+	}
+
+	if profile.Experience == "" {
+		profile.Experience = ExperienceBeginner
+	}
+	switch profile.Experience {
+	case ExperienceBeginner, ExperienceIntermediate, ExperienceAdvanced:
+	default:
+		return fmt.Errorf("%w: unknown experience %q", ErrInvalidProfile, profile.Experience)
+	}
+
+	return nil
+}
+
+func validateBout(bout NewBout) error {
+	if bout.Opponent == "" {
+		return fmt.Errorf("%w: opponent is required", ErrInvalidBout)
+	}
+	if bout.Date.IsZero() || bout.Date.After(time.Now()) {
+		return fmt.Errorf("%w: date must not be in the future", ErrInvalidBout)
+	}
+	if bout.Level != LevelAmateur && bout.Level != LevelPro {
+		return fmt.Errorf("%w: level must be amateur or pro", ErrInvalidBout)
+	}
+	if bout.Round < 0 || bout.Round > maxRounds {
+		return fmt.Errorf("%w: round must be between 1 and %d", ErrInvalidBout, maxRounds)
+	}
+
+	switch bout.Method {
+	case MethodDecision:
+		switch bout.Result {
+		case ResultWin, ResultLoss, ResultDraw, ResultNoContest:
+		default:
+			return fmt.Errorf("%w: unknown result %q", ErrInvalidBout, bout.Result)
+		}
+	case MethodKO, MethodTKO, MethodRSC, MethodDQ, MethodRetirement, MethodWalkover:
+		// Stoppages always have a winner
+		if bout.Result != ResultWin && bout.Result != ResultLoss {
+			return fmt.Errorf("%w: a %s must be a win or a loss", ErrInvalidBout, bout.Method)
+		}
+	default:
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidBout, bout.Method)
+	}
+
+	return nil
+}
+
+func IsWeightClass(weightClass string) bool {
+	for _, wc := range WeightClasses {
+		if wc == weightClass {
+			return true
+		}
+	}
+	return false
+}
+
+func validMeasurement(cm int32) bool {
+	return cm == 0 || (cm >= 100 && cm <= 250)
+}
+
+func toInt4(v int32) pgtype.Int4 {
+	return pgtype.Int4{Int32: v, Valid: v != 0}
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is a single readiness dependency.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Result is the outcome of a check as reported to probes. Why a check failed
+// is only logged, the report is served without authentication.
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type Report struct {
+	Status    string    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs readiness checks concurrently, each bounded by timeout. The
+// report is cached for ttl so frequent probes don't hammer the database or
+// the mail server.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	report *Report
+}
+
+func NewChecker(timeout, ttl time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		ttl:     ttl,
+	}
+}
+
+// Ready returns the cached report, running the checks again once it is older
+// than ttl. Concurrent callers wait for a single run.
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.report != nil && time.Since(c.report.CheckedAt) < c.ttl {
+		return *c.report
+	}
+
+	report := c.run(ctx)
+	c.report = &report
+	return report
+}
+
+func (c *Checker) run(ctx context.Context) Report {
+	report := Report{
+		Status:    StatusOK,
+		Checks:    make([]Result, len(c.checks)),
+		CheckedAt: time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = c.runCheck(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checker) runCheck(ctx context.Context, check Check) Result {
+	// Probes shouldn't be cut short because the first caller went away, the
+	// result is shared with everyone else.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := check.Run(ctx); err != nil {
+		logging.FromContext(ctx).Error("Readiness check failed",
+			slog.String("check", check.Name),
+			slog.Duration("duration", time.Since(start)),
+			slog.Any("error", err),
+		)
+		return Result{Name: check.Name, Status: StatusFail}
+	}
+	return Result{Name: check.Name, Status: StatusOK}
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrMigrationDirty    = errors.New("last migration failed and left the schema dirty")
+	ErrMigrationMismatch = errors.New("database schema version doesn't match the service")
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// DatabaseCheck pings the database.
+func DatabaseCheck(db Pinger) Check {
+	return Check{Name: "database", Run: db.Ping}
+}
+
+// MigrationCheck compares the version recorded by golang-migrate with the
+// newest migration the service was built with.
+func MigrationCheck(db Querier, want int64) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			var (
+				version int64
+				dirty   bool
+			)
+			err := db.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+			if err != nil {
+				return fmt.Errorf("failed to read schema version: %w", err)
+			}
+			if dirty {
+				return fmt.Errorf("%w: version %d", ErrMigrationDirty, version)
+			}
+			if version != want {
+				return fmt.Errorf("%w: database is at %d, expected %d", ErrMigrationMismatch, version, want)
+			}
+			return nil
+		},
+	}
+}
+
+// SMTPCheck checks that the mail server accepts connections.
+func SMTPCheck(smtp Pinger) Check {
+	return Check{Name: "smtp", Run: smtp.Ping}
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type HealthHandlers struct {
+	checker *Checker
+}
+
+func NewHealthHandlers(checker *Checker) *HealthHandlers {
+	return &HealthHandlers{
+		checker: checker,
+	}
+}
+
+// Healthz reports that the process is up. It doesn't look at dependencies so
+// an outage of the database doesn't get the service restarted.
+func (h *HealthHandlers) Healthz(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, map[string]string{"status": StatusOK}, http.StatusOK)
+	return nil
+}
+
+// Readyz reports whether the service can take traffic.
+func (h *HealthHandlers) Readyz(w http.ResponseWriter, r *http.Request) error {
+	report := h.checker.Ready(r.Context())
+
+	statusCode := http.StatusOK
+	if !report.OK() {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, report, statusCode)
+	return nil
+}
+
+func (h *HealthHandlers) Version(w http.ResponseWriter, r *http.Request) error {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return apperror.NotFound("Build info is not available")
+	}
+
+	resp := VersionResponse{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			resp.Revision = setting.Value
+		case "vcs.time":
+			resp.CommitTime = setting.Value
+		case "vcs.modified":
+			resp.Modified = setting.Value == "true"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
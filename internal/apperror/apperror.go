@@ -0,0 +1,92 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// FieldError describes a problem with a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error meant for the client. Code is a stable machine readable
+// identifier, Message is safe to show to users and Err is the cause, which is
+// logged but never sent.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+var (
+	ErrUnauthorized = New(http.StatusUnauthorized, "unauthorized", "Authentication is required")
+	ErrInternal     = New(http.StatusInternalServerError, "internal", "Something went wrong")
+)
+
+func New(status int, code, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, "bad_request", message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, "forbidden", message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, "not_found", message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, "conflict", message)
+}
+
+// InvalidBody reports a request body that couldn't be decoded.
+func InvalidBody(err error) *Error {
+	return New(http.StatusBadRequest, "invalid_body", "Request body is invalid").Wrap(err)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Code + ": " + e.Err.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields returns a copy of e with field errors attached.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	withFields := *e
+	withFields.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &withFields
+}
+
+// From returns the *Error in err's chain. Errors that aren't meant for the
+// client are reported as ErrInternal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,64 @@
+package apperror
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Code and Errors are
+// extension members.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// HandlerFunc is an http.HandlerFunc that returns its errors instead of
+// writing them.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler adapts fn to an http.HandlerFunc, writing any error it returns as a
+// problem.
+func Handler(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := fn(w, r); err != nil {
+			Write(w, r, err)
+		}
+	}
+}
+
+// Write logs err and responds with it as a problem. Server errors are logged
+// with their cause and answered with a generic message.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := From(err)
+
+	logger := logging.FromContext(r.Context())
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.Error("Request failed", slog.String("code", appErr.Code), slog.Any("error", err))
+	} else {
+		logger.Warn("Request rejected", slog.String("code", appErr.Code), slog.Any("error", err))
+	}
+
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(appErr.Status),
+		Status:   appErr.Status,
+		Detail:   appErr.Message,
+		Instance: r.URL.Path,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(appErr.Status)
+	json.NewEncoder(w).Encode(problem)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log/slog"
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -14,9 +15,21 @@ var (
 )
 
 type Config struct {
-	DatabaseURL string
-	JWTSecret   string
-	SMTPConfig  SMTPConfig
+	DatabaseURL     string
+	DBPoolConfig    DBPoolConfig
+	ShutdownConfig  ShutdownConfig
+	LogConfig       LogConfig
+	HealthConfig    HealthConfig
+	TracingConfig   TracingConfig
+	JWTSecret       string
+	CheckinSecret   string
+	SMTPConfig      SMTPConfig
+	NoShowConfig    NoShowConfig
+	WeightCutConfig WeightCutConfig
+
+	// BootstrapStaffEmail is made staff at startup while there is no staff
+	// account yet
+	BootstrapStaffEmail string
 }
 
 type SMTPConfig struct {
@@ -26,6 +39,61 @@ type SMTPConfig struct {
 	Password string
 }
 
+// DBPoolConfig sizes the database connection pool. Zero values keep the pgxpool
+// defaults.
+type DBPoolConfig struct {
+	MaxConns               int
+	MinConns               int
+	MaxConnLifetimeMinutes int
+	MaxConnIdleMinutes     int
+}
+
+// ShutdownConfig controls graceful shutdown. DrainTimeoutSeconds bounds the
+// whole shutdown: draining in-flight requests and stopping every component.
+type ShutdownConfig struct {
+	DrainTimeoutSeconds int
+}
+
+// LogConfig controls the service logger. Level is one of debug, info, warn or
+// error, Format is json or text. EmailMasking is none, partial or full; tokens
+// and passwords are always redacted.
+type LogConfig struct {
+	Level        string
+	Format       string
+	EmailMasking string
+}
+
+// HealthConfig controls the readiness checks. Each check gets
+// CheckTimeoutMillis to finish and results are reused for CacheSeconds.
+type HealthConfig struct {
+	CheckTimeoutMillis int
+	CacheSeconds       int
+}
+
+// TracingConfig selects where spans are exported: "none", "stdout" or
+// "otlp". An empty OTLPEndpoint falls back to the standard OTEL_EXPORTER_OTLP_*
+// environment variables.
+type TracingConfig struct {
+	Exporter     string
+	OTLPEndpoint string
+	ServiceName  string
+}
+
+// NoShowConfig controls booking bans for repeated no-shows. A zero
+// BanThreshold disables bans.
+type NoShowConfig struct {
+	BanThreshold  int
+	BanWindowDays int
+	BanDays       int
+}
+
+// WeightCutConfig controls when coaches are alerted about a fighter's weight
+// cut. SafeCutPercent is the largest share of body weight a fighter should
+// still have to cut on fight day.
+type WeightCutConfig struct {
+	SafeCutPercent int
+}
+
 func LoadConfig() *Config {
 	loadConfigOnce.Do(func() {
 		configInstance = load()
@@ -43,14 +111,72 @@ func load() *Config {
 
 	cfg := &Config{
 		DatabaseURL: os.Getenv("DB_URL"),
-		JWTSecret:   os.Getenv("JWT_SECRET"),
+		DBPoolConfig: DBPoolConfig{
+			MaxConns:               envInt("DB_MAX_CONNS", 10),
+			MinConns:               envInt("DB_MIN_CONNS", 0),
+			MaxConnLifetimeMinutes: envInt("DB_MAX_CONN_LIFETIME_MINUTES", 60),
+			MaxConnIdleMinutes:     envInt("DB_MAX_CONN_IDLE_MINUTES", 30),
+		},
+		JWTSecret:     os.Getenv("JWT_SECRET"),
+		CheckinSecret: os.Getenv("CHECKIN_SECRET"),
 		SMTPConfig: SMTPConfig{
 			Host:     os.Getenv("SMTP_HOST"),
 			Port:     os.Getenv("SMTP_PORT"),
 			User:     os.Getenv("SMTP_USER"),
 			Password: os.Getenv("SMTP_PASSWORD"),
 		},
+		ShutdownConfig: ShutdownConfig{
+			DrainTimeoutSeconds: envInt("SHUTDOWN_DRAIN_TIMEOUT_SECONDS", 20),
+		},
+		LogConfig: LogConfig{
+			Level:        envString("LOG_LEVEL", "info"),
+			Format:       envString("LOG_FORMAT", "json"),
+			EmailMasking: envString("LOG_EMAIL_MASKING", "partial"),
+		},
+		HealthConfig: HealthConfig{
+			CheckTimeoutMillis: envInt("READY_CHECK_TIMEOUT_MS", 2000),
+			CacheSeconds:       envInt("READY_CACHE_SECONDS", 5),
+		},
+		TracingConfig: TracingConfig{
+			Exporter:     envString("TRACING_EXPORTER", "none"),
+			OTLPEndpoint: os.Getenv("TRACING_OTLP_ENDPOINT"),
+			ServiceName:  envString("TRACING_SERVICE_NAME", "boxer66-service"),
+		},
+		NoShowConfig: NoShowConfig{
+			BanThreshold:  envInt("NO_SHOW_BAN_THRESHOLD", 3),
+			BanWindowDays: envInt("NO_SHOW_BAN_WINDOW_DAYS", 30),
+			BanDays:       envInt("NO_SHOW_BAN_DAYS", 7),
+		},
+		WeightCutConfig: WeightCutConfig{
+			SafeCutPercent: envInt("WEIGHT_CUT_SAFE_PERCENT", 5),
+		},
+		BootstrapStaffEmail: os.Getenv("BOOTSTRAP_STAFF_EMAIL"),
 	}
 
 	return cfg
 }
+
+// envString reads a string environment variable, falling back to def when it
+// is unset.
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envInt reads an integer environment variable, falling back to def when it
+// is unset or malformed.
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("Ignoring malformed environment variable", slog.String("key", key), slog.Any("error", err))
+		return def
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Component is a subsystem with start and stop hooks. Either hook can be nil.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Lifecycle starts components in the order they were registered and stops
+// them in reverse, so a component can rely on everything registered before it
+// while it runs and while it shuts down.
+type Lifecycle struct {
+	components []Component
+	started    []Component
+}
+
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+func (l *Lifecycle) Register(c Component) {
+	l.components = append(l.components, c)
+}
+
+// Start runs the start hooks in registration order. If one fails, the
+// components already started are stopped again before returning.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, c := range l.components {
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				return errors.Join(
+					fmt.Errorf("failed to start %s: %w", c.Name, err),
+					l.Stop(ctx),
+				)
+			}
+		}
+		l.started = append(l.started, c)
+		slog.Info("Started component", slog.String("component", c.Name))
+	}
+	return nil
+}
+
+// Stop runs the stop hooks of started components in reverse order. A failing
+// hook doesn't keep the remaining components from stopping; all errors are
+// returned together.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(l.started) - 1; i >= 0; i-- {
+		c := l.started[i]
+		if c.Stop != nil {
+			if err := c.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.Name, err))
+				continue
+			}
+		}
+		slog.Info("Stopped component", slog.String("component", c.Name))
+	}
+	l.started = nil
+	return errors.Join(errs...)
+}
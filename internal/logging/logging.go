@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/grez-lucas/boxer66-service/internal/config"
+)
+
+type contextKey struct{}
+
+// New builds the service logger from cfg. Unknown levels fall back to info
+// and unknown formats to JSON. Sensitive values are redacted, see Email and
+// replaceAttr.
+func New(cfg config.LogConfig) *slog.Logger {
+	switch cfg.EmailMasking {
+	case EmailMaskingNone, EmailMaskingPartial, EmailMaskingFull:
+		emailMasking = cfg.EmailMasking
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       parseLevel(cfg.Level),
+		ReplaceAttr: replaceAttr,
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	return slog.New(handler)
+}
+
+// FromContext returns the logger stored in ctx by WithAttrs, or the default
+// logger if there is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithAttrs stores a logger in ctx that adds attrs to everything logged
+// through FromContext.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return context.WithValue(ctx, contextKey{}, FromContext(ctx).With(args...))
+}
+
+func parseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const redacted = "[REDACTED]"
+
+// Email masking modes, see config.LogConfig.EmailMasking.
+const (
+	EmailMaskingNone    = "none"
+	EmailMaskingPartial = "partial"
+	EmailMaskingFull    = "full"
+)
+
+// emailMasking is set by New before anything is logged.
+var emailMasking = EmailMaskingPartial
+
+// sensitiveKeys are attribute keys whose values are dropped whatever their
+// type, in case a secret is logged as a plain string.
+var sensitiveKeys = []string{"token", "password", "secret", "jwt", "authorization", "cookie"}
+
+// emailPattern finds addresses inside free text, such as an SMTP server reply
+// wrapped in an error.
+var emailPattern = regexp.MustCompile(`[^\s<>()"',;:@]+@[^\s<>()"',;:@]+\.[^\s<>()"',;:@]+`)
+
+// Email is an email address that is masked according to the configured
+// policy when logged.
+type Email string
+
+func (e Email) LogValue() slog.Value {
+	return slog.StringValue(maskEmail(string(e)))
+}
+
+// replaceAttr is the slog.HandlerOptions.ReplaceAttr policy of the service
+// logger. LogValuer types are resolved before it runs, so it only has to catch
+// sensitive values logged as plain strings or inside errors.
+func replaceAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	switch {
+	case a.Value.Kind() == slog.KindString && strings.Contains(key, "email"):
+		return slog.String(a.Key, maskEmail(a.Value.String()))
+	case a.Value.Kind() == slog.KindString:
+		return slog.String(a.Key, maskEmails(a.Value.String()))
+	case a.Value.Kind() == slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, maskEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+// maskEmails masks every address found in s.
+func maskEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllStringFunc(s, maskEmail)
+}
+
+// maskEmail keeps the first character of the local part and the domain in
+// partial mode, so support can still tell addresses apart.
+func maskEmail(email string) string {
+	switch emailMasking {
+	case EmailMaskingNone:
+		return email
+	case EmailMaskingFull:
+		return redacted
+	}
+
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return redacted
+	}
+	_, size := utf8.DecodeRuneInString(local)
+	return local[:size] + "***@" + domain
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+// logLine logs a single attribute through the service policy and returns
+// the value it was written with.
+func logLine(t *testing.T, attr slog.Attr) any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: replaceAttr}))
+	logger.LogAttrs(t.Context(), slog.LevelInfo, "test", attr)
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to decode log line %q: %v", buf.String(), err)
+	}
+	return line[attr.Key]
+}
+
+func withEmailMasking(t *testing.T, mode string) {
+	t.Helper()
+	previous := emailMasking
+	emailMasking = mode
+	t.Cleanup(func() { emailMasking = previous })
+}
+
+func TestReplaceAttr(t *testing.T) {
+	withEmailMasking(t, EmailMaskingPartial)
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want any
+	}{
+		{name: "token", attr: slog.String("token", "ABC12"), want: redacted},
+		{name: "jwt header", attr: slog.String("jwt-token", "eyJhbGciOi"), want: redacted},
+		{name: "password", attr: slog.String("new_password", "hunter22"), want: redacted},
+		{name: "authorization", attr: slog.String("Authorization", "Bearer x"), want: redacted},
+		{name: "secret of any type", attr: slog.Int("client_secret", 42), want: redacted},
+		{name: "email key", attr: slog.String("email", "john@example.com"), want: "j***@example.com"},
+		{name: "email in a string", attr: slog.String("msg", "sent to john@example.com"), want: "sent to j***@example.com"},
+		{
+			name: "email in an error",
+			attr: slog.Any("error", fmt.Errorf("failed to send email: %w", errors.New("550 <john@example.com>: rejected"))),
+			want: "failed to send email: 550 <j***@example.com>: rejected",
+		},
+		{name: "other values", attr: slog.String("route", "GET /users/{id}"), want: "GET /users/{id}"},
+		{name: "numbers", attr: slog.Int("user_id", 7), want: float64(7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logLine(t, tt.attr); got != tt.want {
+				t.Errorf("logged %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailLogValue(t *testing.T) {
+	tests := []struct {
+		mode  string
+		email string
+		want  string
+	}{
+		{mode: EmailMaskingPartial, email: "john@example.com", want: "j***@example.com"},
+		{mode: EmailMaskingPartial, email: "émile@example.com", want: "é***@example.com"},
+		{mode: EmailMaskingPartial, email: "not-an-email", want: redacted},
+		{mode: EmailMaskingPartial, email: "@example.com", want: redacted},
+		{mode: EmailMaskingFull, email: "john@example.com", want: redacted},
+		{mode: EmailMaskingNone, email: "john@example.com", want: "john@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+" "+tt.email, func(t *testing.T) {
+			withEmailMasking(t, tt.mode)
+
+			// The key doesn't mention email, so only the LogValuer masks it
+			if got := logLine(t, slog.Any("recipient", Email(tt.email))); got != tt.want {
+				t.Errorf("logged %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package openapi serves the OpenAPI document of the service and a browsable
+// reference built from it.
+//
+// openapi.yaml is maintained by hand. Add or update its entry whenever a route
+// is added to router.NewRouter or a request or response DTO changes. The
+// contract test in the router package checks responses against it.
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// docsPage renders the spec with Redoc. The spec URL is relative so the page
+// also works under the /api prefix. Redoc is pinned so a new release can't
+// change the page without a code change.
+const docsPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Boxer66 API</title>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+  </head>
+  <body>
+    <redoc spec-url="openapi.yaml"></redoc>
+    <script src="https://cdn.redoc.ly/redoc/v2.5.0/bundles/redoc.standalone.js"></script>
+  </body>
+</html>
+`
+
+// Spec serves the OpenAPI document.
+func Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(spec)
+}
+
+// Docs serves the API reference.
+func Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(docsPage))
+}
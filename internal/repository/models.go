@@ -5,12 +5,143 @@
 package repository
 
 import (
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Attendance struct {
+	ID          int32       `json:"id"`
+	UserID      int32       `json:"user_id"`
+	SessionID   pgtype.Int4 `json:"session_id"`
+	Source      string      `json:"source"`
+	RecordedBy  pgtype.Int4 `json:"recorded_by"`
+	CheckedInAt time.Time   `json:"checked_in_at"`
+	ClassTypeID pgtype.Int4 `json:"class_type_id"`
+}
+
+type Booking struct {
+	ID               int32              `json:"id"`
+	SessionID        int32              `json:"session_id"`
+	UserID           int32              `json:"user_id"`
+	Status           string             `json:"status"`
+	PromotedAt       pgtype.Timestamptz `json:"promoted_at"`
+	CancelledAt      pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	CheckedInAt      pgtype.Timestamptz `json:"checked_in_at"`
+	LateCancellation bool               `json:"late_cancellation"`
+}
+
+type Bout struct {
+	ID         int32       `json:"id"`
+	FighterID  int32       `json:"fighter_id"`
+	Opponent   string      `json:"opponent"`
+	Event      string      `json:"event"`
+	BoutDate   time.Time   `json:"bout_date"`
+	Level      string      `json:"level"`
+	Result     string      `json:"result"`
+	Method     string      `json:"method"`
+	Round      pgtype.Int4 `json:"round"`
+	Notes      string      `json:"notes"`
+	RecordedBy pgtype.Int4 `json:"recorded_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type ClassSchedule struct {
+	ID          int32       `json:"id"`
+	ClassTypeID int32       `json:"class_type_id"`
+	RoomID      int32       `json:"room_id"`
+	CoachID     pgtype.Int4 `json:"coach_id"`
+	Dtstart     time.Time   `json:"dtstart"`
+	Rrule       string      `json:"rrule"`
+	Timezone    string      `json:"timezone"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+type ClassScheduleException struct {
+	ID         int32     `json:"id"`
+	ScheduleID int32     `json:"schedule_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ClassSession struct {
+	ID          int32              `json:"id"`
+	ScheduleID  int32              `json:"schedule_id"`
+	StartsAt    time.Time          `json:"starts_at"`
+	EndsAt      time.Time          `json:"ends_at"`
+	Capacity    int32              `json:"capacity"`
+	CancelledAt pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+type ClassType struct {
+	ID                        int32     `json:"id"`
+	Name                      string    `json:"name"`
+	Description               string    `json:"description"`
+	DurationMinutes           int32     `json:"duration_minutes"`
+	CreatedAt                 time.Time `json:"created_at"`
+	CancellationWindowMinutes int32     `json:"cancellation_window_minutes"`
+	LateCancelPenalty         string    `json:"late_cancel_penalty"`
+	NoShowPenalty             string    `json:"no_show_penalty"`
+	PenaltyFeeCents           int32     `json:"penalty_fee_cents"`
+}
+
+type Closure struct {
+	ID        int32     `json:"id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CoachAvailability struct {
+	ID        int32       `json:"id"`
+	CoachID   int32       `json:"coach_id"`
+	Weekday   int16       `json:"weekday"`
+	StartTime pgtype.Time `json:"start_time"`
+	EndTime   pgtype.Time `json:"end_time"`
+}
+
+type CoachAvailabilityException struct {
+	ID        int32     `json:"id"`
+	CoachID   int32     `json:"coach_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CoachProfile struct {
+	UserID         int32     `json:"user_id"`
+	Bio            string    `json:"bio"`
+	Specialties    []string  `json:"specialties"`
+	Certifications []string  `json:"certifications"`
+	Timezone       string    `json:"timezone"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreditLedger struct {
+	ID             int32              `json:"id"`
+	UserID         int32              `json:"user_id"`
+	Kind           string             `json:"kind"`
+	Delta          int32              `json:"delta"`
+	LotID          pgtype.Int4        `json:"lot_id"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	SubscriptionID pgtype.Int4        `json:"subscription_id"`
+	AttendanceID   pgtype.Int4        `json:"attendance_id"`
+	RefundOf       pgtype.Int4        `json:"refund_of"`
+	Reason         string             `json:"reason"`
+	CreatedBy      pgtype.Int4        `json:"created_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
 type EmailVerificationToken struct {
 	ID                     uuid.UUID   `json:"id"`
 	Email                  string      `json:"email"`
@@ -21,10 +152,180 @@ type EmailVerificationToken struct {
 	ExpiresAt              time.Time   `json:"expires_at"`
 }
 
-type User struct {
+type FighterProfile struct {
+	UserID           int32       `json:"user_id"`
+	DisplayName      string      `json:"display_name"`
+	WeightClass      string      `json:"weight_class"`
+	HeightCm         pgtype.Int4 `json:"height_cm"`
+	ReachCm          pgtype.Int4 `json:"reach_cm"`
+	Stance           string      `json:"stance"`
+	Level            string      `json:"level"`
+	Public           bool        `json:"public"`
+	ShowMeasurements bool        `json:"show_measurements"`
+	ShowBouts        bool        `json:"show_bouts"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Experience       string      `json:"experience"`
+}
+
+type LegalAcceptance struct {
+	ID         int32      `json:"id"`
+	UserID     int32      `json:"user_id"`
+	DocumentID int32      `json:"document_id"`
+	IpAddress  netip.Addr `json:"ip_address"`
+	AcceptedAt time.Time  `json:"accepted_at"`
+}
+
+type LegalDocument struct {
+	ID          int32     `json:"id"`
+	Kind        string    `json:"kind"`
+	Version     int32     `json:"version"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type MembershipFreeze struct {
+	ID             int32              `json:"id"`
+	SubscriptionID int32              `json:"subscription_id"`
+	UserID         int32              `json:"user_id"`
+	Status         string             `json:"status"`
+	StartsAt       time.Time          `json:"starts_at"`
+	EndsAt         time.Time          `json:"ends_at"`
+	EndedAt        pgtype.Timestamptz `json:"ended_at"`
+	Reason         string             `json:"reason"`
+	RequestedBy    pgtype.Int4        `json:"requested_by"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type MembershipPlan struct {
+	ID                   int32       `json:"id"`
+	Name                 string      `json:"name"`
+	Description          string      `json:"description"`
+	PriceCents           int32       `json:"price_cents"`
+	BillingPeriod        string      `json:"billing_period"`
+	ValidityDays         pgtype.Int4 `json:"validity_days"`
+	ClassAllowance       pgtype.Int4 `json:"class_allowance"`
+	IsActive             bool        `json:"is_active"`
+	CreatedAt            time.Time   `json:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at"`
+	MaxFreezesPerYear    int32       `json:"max_freezes_per_year"`
+	MaxFreezeDaysPerYear int32       `json:"max_freeze_days_per_year"`
+}
+
+type Penalty struct {
+	ID            int32              `json:"id"`
+	UserID        int32              `json:"user_id"`
+	BookingID     int32              `json:"booking_id"`
+	Reason        string             `json:"reason"`
+	Kind          string             `json:"kind"`
+	FeeCents      int32              `json:"fee_cents"`
+	CreditEntryID pgtype.Int4        `json:"credit_entry_id"`
+	BannedUntil   pgtype.Timestamptz `json:"banned_until"`
+	WaivedAt      pgtype.Timestamptz `json:"waived_at"`
+	WaivedBy      pgtype.Int4        `json:"waived_by"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+type PendingLegalAcceptance struct {
+	ID         int32      `json:"id"`
+	Email      string     `json:"email"`
+	DocumentID int32      `json:"document_id"`
+	IpAddress  netip.Addr `json:"ip_address"`
+	AcceptedAt time.Time  `json:"accepted_at"`
+}
+
+type Room struct {
 	ID        int32     `json:"id"`
-	Email     string    `json:"email"`
-	Password  []byte    `json:"password"`
+	Name      string    `json:"name"`
+	Capacity  int32     `json:"capacity"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SparringPairing struct {
+	ID         int32       `json:"id"`
+	SessionID  int32       `json:"session_id"`
+	FighterAID int32       `json:"fighter_a_id"`
+	FighterBID int32       `json:"fighter_b_id"`
+	RecordedBy pgtype.Int4 `json:"recorded_by"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type Subscription struct {
+	ID          int32              `json:"id"`
+	UserID      int32              `json:"user_id"`
+	PlanID      int32              `json:"plan_id"`
+	Status      string             `json:"status"`
+	StartsAt    time.Time          `json:"starts_at"`
+	EndsAt      time.Time          `json:"ends_at"`
+	RenewsAt    pgtype.Timestamptz `json:"renews_at"`
+	PausedAt    pgtype.Timestamptz `json:"paused_at"`
+	CancelledAt pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedBy   pgtype.Int4        `json:"created_by"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+type TrainingSession struct {
+	ID               int32              `json:"id"`
+	CoachID          int32              `json:"coach_id"`
+	MemberID         int32              `json:"member_id"`
+	Status           string             `json:"status"`
+	StartsAt         time.Time          `json:"starts_at"`
+	EndsAt           time.Time          `json:"ends_at"`
+	Note             string             `json:"note"`
+	DeclineReason    string             `json:"decline_reason"`
+	ProposedStartsAt pgtype.Timestamptz `json:"proposed_starts_at"`
+	ProposedEndsAt   pgtype.Timestamptz `json:"proposed_ends_at"`
+	ProposedBy       pgtype.Int4        `json:"proposed_by"`
+	CancelledBy      pgtype.Int4        `json:"cancelled_by"`
+	ReminderSentAt   pgtype.Timestamptz `json:"reminder_sent_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+type User struct {
+	ID              int32       `json:"id"`
+	Email           string      `json:"email"`
+	Password        []byte      `json:"password"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	Status          string      `json:"status"`
+	StatusReason    pgtype.Text `json:"status_reason"`
+	StatusChangedAt time.Time   `json:"status_changed_at"`
+	Role            string      `json:"role"`
+	PhotoUrl        pgtype.Text `json:"photo_url"`
+}
+
+type UserStatusTransition struct {
+	ID         int32       `json:"id"`
+	UserID     int32       `json:"user_id"`
+	FromStatus pgtype.Text `json:"from_status"`
+	ToStatus   string      `json:"to_status"`
+	Reason     pgtype.Text `json:"reason"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type WeighIn struct {
+	ID             int32         `json:"id"`
+	UserID         int32         `json:"user_id"`
+	WeightKg       float64       `json:"weight_kg"`
+	BodyFatPercent pgtype.Float8 `json:"body_fat_percent"`
+	MeasuredAt     time.Time     `json:"measured_at"`
+	Source         string        `json:"source"`
+	Notes          string        `json:"notes"`
+	RecordedBy     pgtype.Int4   `json:"recorded_by"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type WeightTarget struct {
+	UserID    int32              `json:"user_id"`
+	TargetKg  float64            `json:"target_kg"`
+	FightDate time.Time          `json:"fight_date"`
+	CoachID   pgtype.Int4        `json:"coach_id"`
+	AlertedAt pgtype.Timestamptz `json:"alerted_at"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
 }
@@ -7,105 +7,3995 @@ package repository
 
 import (
 	"context"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelBooking = `-- name: CancelBooking :one
+UPDATE bookings
+SET status = 'cancelled', late_cancellation = $2, cancelled_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status <> 'cancelled'
+RETURNING id, session_id, user_id, status, promoted_at, cancelled_at, created_at, updated_at, checked_in_at, late_cancellation
+`
+
+type CancelBookingParams struct {
+	ID               int32 `json:"id"`
+	LateCancellation bool  `json:"late_cancellation"`
+}
+
+func (q *Queries) CancelBooking(ctx context.Context, arg CancelBookingParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, cancelBooking, arg.ID, arg.LateCancellation)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.UserID,
+		&i.Status,
+		&i.PromotedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CheckedInAt,
+		&i.LateCancellation,
+	)
+	return i, err
+}
+
+const cancelClassSession = `-- name: CancelClassSession :exec
+UPDATE class_sessions
+SET cancelled_at = NOW()
+WHERE schedule_id = $1 AND starts_at = $2 AND cancelled_at IS NULL
+`
+
+type CancelClassSessionParams struct {
+	ScheduleID int32     `json:"schedule_id"`
+	StartsAt   time.Time `json:"starts_at"`
+}
+
+func (q *Queries) CancelClassSession(ctx context.Context, arg CancelClassSessionParams) error {
+	_, err := q.db.Exec(ctx, cancelClassSession, arg.ScheduleID, arg.StartsAt)
+	return err
+}
+
+const cancelClassSessionsBetween = `-- name: CancelClassSessionsBetween :exec
+UPDATE class_sessions
+SET cancelled_at = NOW()
+WHERE starts_at < $1 AND ends_at > $2 AND cancelled_at IS NULL
+`
+
+type CancelClassSessionsBetweenParams struct {
+	ToTime   time.Time `json:"to_time"`
+	FromTime time.Time `json:"from_time"`
+}
+
+func (q *Queries) CancelClassSessionsBetween(ctx context.Context, arg CancelClassSessionsBetweenParams) error {
+	_, err := q.db.Exec(ctx, cancelClassSessionsBetween, arg.ToTime, arg.FromTime)
+	return err
+}
+
+const cancelStaleFreezes = `-- name: CancelStaleFreezes :exec
+UPDATE membership_freezes
+SET status = 'cancelled', updated_at = NOW()
+FROM subscriptions
+WHERE subscriptions.id = membership_freezes.subscription_id
+  AND membership_freezes.status = 'scheduled'
+  AND subscriptions.status IN ('cancelled', 'expired')
+`
+
+// Scheduled freezes of subscriptions that ended or were cancelled before the
+// freeze started can't apply anymore.
+func (q *Queries) CancelStaleFreezes(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, cancelStaleFreezes)
+	return err
+}
+
+const cancelSubscription = `-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'cancelled', cancelled_at = NOW(), renews_at = NULL, paused_at = NULL, updated_at = NOW()
+WHERE id = $1 AND status IN ('active', 'paused', 'frozen')
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+func (q *Queries) CancelSubscription(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, cancelSubscription, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const claimPendingLegalAcceptances = `-- name: ClaimPendingLegalAcceptances :exec
+WITH claimed AS (
+  DELETE FROM pending_legal_acceptances
+  WHERE email = $2
+  RETURNING document_id, ip_address, accepted_at
+)
+INSERT INTO legal_acceptances (user_id, document_id, ip_address, accepted_at)
+SELECT $1::INTEGER, claimed.document_id, claimed.ip_address, claimed.accepted_at
+FROM claimed
+ON CONFLICT (user_id, document_id) DO NOTHING
+`
+
+type ClaimPendingLegalAcceptancesParams struct {
+	UserID int32  `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+func (q *Queries) ClaimPendingLegalAcceptances(ctx context.Context, arg ClaimPendingLegalAcceptancesParams) error {
+	_, err := q.db.Exec(ctx, claimPendingLegalAcceptances, arg.UserID, arg.Email)
+	return err
+}
+
+const claimTrainingSessionReminders = `-- name: ClaimTrainingSessionReminders :many
+UPDATE training_sessions
+SET reminder_sent_at = NOW()
+FROM users members, users coaches, coach_profiles
+WHERE members.id = training_sessions.member_id
+  AND coaches.id = training_sessions.coach_id
+  AND coach_profiles.user_id = training_sessions.coach_id
+  AND training_sessions.status = 'confirmed'
+  AND training_sessions.reminder_sent_at IS NULL
+  AND training_sessions.starts_at > NOW()
+  AND training_sessions.starts_at <= $1
+RETURNING training_sessions.id, training_sessions.starts_at, training_sessions.ends_at,
+  members.email AS member_email, coaches.email AS coach_email, coach_profiles.timezone
+`
+
+type ClaimTrainingSessionRemindersRow struct {
+	ID          int32     `json:"id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	MemberEmail string    `json:"member_email"`
+	CoachEmail  string    `json:"coach_email"`
+	Timezone    string    `json:"timezone"`
+}
+
+// Marks confirmed sessions starting before the cutoff as reminded and returns
+// them, so each reminder goes out once.
+func (q *Queries) ClaimTrainingSessionReminders(ctx context.Context, cutoff time.Time) ([]ClaimTrainingSessionRemindersRow, error) {
+	rows, err := q.db.Query(ctx, claimTrainingSessionReminders, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimTrainingSessionRemindersRow
+	for rows.Next() {
+		var i ClaimTrainingSessionRemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.MemberEmail,
+			&i.CoachEmail,
+			&i.Timezone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimWeightCutAlert = `-- name: ClaimWeightCutAlert :one
+UPDATE weight_targets
+SET alerted_at = NOW()
+FROM users fighters, users coaches
+WHERE weight_targets.user_id = $1
+  AND weight_targets.alerted_at IS NULL
+  AND fighters.id = weight_targets.user_id
+  AND coaches.id = weight_targets.coach_id
+RETURNING coaches.email AS coach_email,
+  COALESCE(
+    (SELECT display_name FROM fighter_profiles WHERE fighter_profiles.user_id = weight_targets.user_id),
+    fighters.email
+  )::VARCHAR AS fighter_name
+`
+
+type ClaimWeightCutAlertRow struct {
+	CoachEmail  string `json:"coach_email"`
+	FighterName string `json:"fighter_name"`
+}
+
+// Marks a target as alerted and returns who to alert, so each target alerts
+// its coach once.
+func (q *Queries) ClaimWeightCutAlert(ctx context.Context, userID int32) (ClaimWeightCutAlertRow, error) {
+	row := q.db.QueryRow(ctx, claimWeightCutAlert, userID)
+	var i ClaimWeightCutAlertRow
+	err := row.Scan(&i.CoachEmail, &i.FighterName)
+	return i, err
+}
+
+const clearTrainingSessionProposal = `-- name: ClearTrainingSessionProposal :one
+UPDATE training_sessions
+SET proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at
+`
+
+func (q *Queries) ClearTrainingSessionProposal(ctx context.Context, id int32) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, clearTrainingSessionProposal, id)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const countBookedInSession = `-- name: CountBookedInSession :one
+SELECT COUNT(*) FROM bookings
+WHERE session_id = $1 AND status IN ('booked', 'attended')
+`
+
+// Members who checked in keep their seat.
+func (q *Queries) CountBookedInSession(ctx context.Context, sessionID int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countBookedInSession, sessionID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countNoShowsSince = `-- name: CountNoShowsSince :one
+SELECT COUNT(*) FROM penalties
+WHERE user_id = $1 AND reason = 'no_show' AND kind <> 'ban' AND waived_at IS NULL AND created_at > $2
+`
+
+type CountNoShowsSinceParams struct {
+	UserID int32     `json:"user_id"`
+	Since  time.Time `json:"since"`
+}
+
+func (q *Queries) CountNoShowsSince(ctx context.Context, arg CountNoShowsSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countNoShowsSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAttendance = `-- name: CreateAttendance :one
+INSERT INTO attendances (user_id, session_id, class_type_id, source, recorded_by, checked_in_at)
+VALUES (
+  $1,
+  $2,
+  COALESCE($3::INTEGER, (
+    SELECT class_schedules.class_type_id
+    FROM class_sessions
+    JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+    WHERE class_sessions.id = $2
+  )),
+  $4,
+  $5,
+  COALESCE($6::TIMESTAMPTZ, NOW())
+)
+RETURNING id, user_id, session_id, source, recorded_by, checked_in_at, class_type_id
+`
+
+type CreateAttendanceParams struct {
+	UserID      int32              `json:"user_id"`
+	SessionID   pgtype.Int4        `json:"session_id"`
+	ClassTypeID pgtype.Int4        `json:"class_type_id"`
+	Source      string             `json:"source"`
+	RecordedBy  pgtype.Int4        `json:"recorded_by"`
+	CheckedInAt pgtype.Timestamptz `json:"checked_in_at"`
+}
+
+// The class type defaults to the one of the session, if any.
+func (q *Queries) CreateAttendance(ctx context.Context, arg CreateAttendanceParams) (Attendance, error) {
+	row := q.db.QueryRow(ctx, createAttendance,
+		arg.UserID,
+		arg.SessionID,
+		arg.ClassTypeID,
+		arg.Source,
+		arg.RecordedBy,
+		arg.CheckedInAt,
+	)
+	var i Attendance
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.SessionID,
+		&i.Source,
+		&i.RecordedBy,
+		&i.CheckedInAt,
+		&i.ClassTypeID,
+	)
+	return i, err
+}
+
+const createBooking = `-- name: CreateBooking :one
+INSERT INTO bookings (session_id, user_id, status)
+VALUES ($1, $2, $3)
+RETURNING id, session_id, user_id, status, promoted_at, cancelled_at, created_at, updated_at, checked_in_at, late_cancellation
+`
+
+type CreateBookingParams struct {
+	SessionID int32  `json:"session_id"`
+	UserID    int32  `json:"user_id"`
+	Status    string `json:"status"`
+}
+
+func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, createBooking, arg.SessionID, arg.UserID, arg.Status)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.UserID,
+		&i.Status,
+		&i.PromotedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CheckedInAt,
+		&i.LateCancellation,
+	)
+	return i, err
+}
+
+const createBout = `-- name: CreateBout :one
+INSERT INTO bouts (fighter_id, opponent, event, bout_date, level, result, method, round, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, fighter_id, opponent, event, bout_date, level, result, method, round, notes, recorded_by, created_at
+`
+
+type CreateBoutParams struct {
+	FighterID  int32       `json:"fighter_id"`
+	Opponent   string      `json:"opponent"`
+	Event      string      `json:"event"`
+	BoutDate   time.Time   `json:"bout_date"`
+	Level      string      `json:"level"`
+	Result     string      `json:"result"`
+	Method     string      `json:"method"`
+	Round      pgtype.Int4 `json:"round"`
+	Notes      string      `json:"notes"`
+	RecordedBy pgtype.Int4 `json:"recorded_by"`
+}
+
+func (q *Queries) CreateBout(ctx context.Context, arg CreateBoutParams) (Bout, error) {
+	row := q.db.QueryRow(ctx, createBout,
+		arg.FighterID,
+		arg.Opponent,
+		arg.Event,
+		arg.BoutDate,
+		arg.Level,
+		arg.Result,
+		arg.Method,
+		arg.Round,
+		arg.Notes,
+		arg.RecordedBy,
+	)
+	var i Bout
+	err := row.Scan(
+		&i.ID,
+		&i.FighterID,
+		&i.Opponent,
+		&i.Event,
+		&i.BoutDate,
+		&i.Level,
+		&i.Result,
+		&i.Method,
+		&i.Round,
+		&i.Notes,
+		&i.RecordedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createClassSchedule = `-- name: CreateClassSchedule :one
+INSERT INTO class_schedules (class_type_id, room_id, coach_id, dtstart, rrule, timezone)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, class_type_id, room_id, coach_id, dtstart, rrule, timezone, created_at, updated_at
+`
+
+type CreateClassScheduleParams struct {
+	ClassTypeID int32       `json:"class_type_id"`
+	RoomID      int32       `json:"room_id"`
+	CoachID     pgtype.Int4 `json:"coach_id"`
+	Dtstart     time.Time   `json:"dtstart"`
+	Rrule       string      `json:"rrule"`
+	Timezone    string      `json:"timezone"`
+}
+
+func (q *Queries) CreateClassSchedule(ctx context.Context, arg CreateClassScheduleParams) (ClassSchedule, error) {
+	row := q.db.QueryRow(ctx, createClassSchedule,
+		arg.ClassTypeID,
+		arg.RoomID,
+		arg.CoachID,
+		arg.Dtstart,
+		arg.Rrule,
+		arg.Timezone,
+	)
+	var i ClassSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.ClassTypeID,
+		&i.RoomID,
+		&i.CoachID,
+		&i.Dtstart,
+		&i.Rrule,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createClassScheduleException = `-- name: CreateClassScheduleException :one
+INSERT INTO class_schedule_exceptions (schedule_id, starts_at, reason)
+VALUES ($1, $2, $3)
+ON CONFLICT (schedule_id, starts_at) DO UPDATE
+SET reason = EXCLUDED.reason
+RETURNING id, schedule_id, starts_at, reason, created_at
+`
+
+type CreateClassScheduleExceptionParams struct {
+	ScheduleID int32     `json:"schedule_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	Reason     string    `json:"reason"`
+}
+
+func (q *Queries) CreateClassScheduleException(ctx context.Context, arg CreateClassScheduleExceptionParams) (ClassScheduleException, error) {
+	row := q.db.QueryRow(ctx, createClassScheduleException, arg.ScheduleID, arg.StartsAt, arg.Reason)
+	var i ClassScheduleException
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.StartsAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createClassSessions = `-- name: CreateClassSessions :exec
+INSERT INTO class_sessions (schedule_id, starts_at, ends_at, capacity)
+SELECT
+  unnest($1::INTEGER[]),
+  unnest($2::TIMESTAMPTZ[]),
+  unnest($3::TIMESTAMPTZ[]),
+  unnest($4::INTEGER[])
+ON CONFLICT (schedule_id, starts_at) DO NOTHING
+`
+
+type CreateClassSessionsParams struct {
+	ScheduleIds []int32     `json:"schedule_ids"`
+	StartsAts   []time.Time `json:"starts_ats"`
+	EndsAts     []time.Time `json:"ends_ats"`
+	Capacities  []int32     `json:"capacities"`
+}
+
+// Materializes schedule occurrences so they can be booked. Existing sessions
+// are left untouched.
+func (q *Queries) CreateClassSessions(ctx context.Context, arg CreateClassSessionsParams) error {
+	_, err := q.db.Exec(ctx, createClassSessions,
+		arg.ScheduleIds,
+		arg.StartsAts,
+		arg.EndsAts,
+		arg.Capacities,
+	)
+	return err
+}
+
+const createClassType = `-- name: CreateClassType :one
+INSERT INTO class_types (name, description, duration_minutes, cancellation_window_minutes, late_cancel_penalty, no_show_penalty, penalty_fee_cents)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, description, duration_minutes, created_at, cancellation_window_minutes, late_cancel_penalty, no_show_penalty, penalty_fee_cents
+`
+
+type CreateClassTypeParams struct {
+	Name                      string `json:"name"`
+	Description               string `json:"description"`
+	DurationMinutes           int32  `json:"duration_minutes"`
+	CancellationWindowMinutes int32  `json:"cancellation_window_minutes"`
+	LateCancelPenalty         string `json:"late_cancel_penalty"`
+	NoShowPenalty             string `json:"no_show_penalty"`
+	PenaltyFeeCents           int32  `json:"penalty_fee_cents"`
+}
+
+func (q *Queries) CreateClassType(ctx context.Context, arg CreateClassTypeParams) (ClassType, error) {
+	row := q.db.QueryRow(ctx, createClassType,
+		arg.Name,
+		arg.Description,
+		arg.DurationMinutes,
+		arg.CancellationWindowMinutes,
+		arg.LateCancelPenalty,
+		arg.NoShowPenalty,
+		arg.PenaltyFeeCents,
+	)
+	var i ClassType
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.DurationMinutes,
+		&i.CreatedAt,
+		&i.CancellationWindowMinutes,
+		&i.LateCancelPenalty,
+		&i.NoShowPenalty,
+		&i.PenaltyFeeCents,
+	)
+	return i, err
+}
+
+const createClosure = `-- name: CreateClosure :one
+INSERT INTO closures (starts_at, ends_at, reason)
+VALUES ($1, $2, $3)
+RETURNING id, starts_at, ends_at, reason, created_at
+`
+
+type CreateClosureParams struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+func (q *Queries) CreateClosure(ctx context.Context, arg CreateClosureParams) (Closure, error) {
+	row := q.db.QueryRow(ctx, createClosure, arg.StartsAt, arg.EndsAt, arg.Reason)
+	var i Closure
+	err := row.Scan(
+		&i.ID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCoachAvailability = `-- name: CreateCoachAvailability :many
+INSERT INTO coach_availability (coach_id, weekday, start_time, end_time)
+SELECT $1,
+  unnest($2::SMALLINT[]),
+  unnest($3::TIME[]),
+  unnest($4::TIME[])
+RETURNING id, coach_id, weekday, start_time, end_time
+`
+
+type CreateCoachAvailabilityParams struct {
+	CoachID    int32         `json:"coach_id"`
+	Weekdays   []int16       `json:"weekdays"`
+	StartTimes []pgtype.Time `json:"start_times"`
+	EndTimes   []pgtype.Time `json:"end_times"`
+}
+
+func (q *Queries) CreateCoachAvailability(ctx context.Context, arg CreateCoachAvailabilityParams) ([]CoachAvailability, error) {
+	rows, err := q.db.Query(ctx, createCoachAvailability,
+		arg.CoachID,
+		arg.Weekdays,
+		arg.StartTimes,
+		arg.EndTimes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CoachAvailability
+	for rows.Next() {
+		var i CoachAvailability
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoachID,
+			&i.Weekday,
+			&i.StartTime,
+			&i.EndTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createCoachAvailabilityException = `-- name: CreateCoachAvailabilityException :one
+INSERT INTO coach_availability_exceptions (coach_id, starts_at, ends_at, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, coach_id, starts_at, ends_at, reason, created_at
+`
+
+type CreateCoachAvailabilityExceptionParams struct {
+	CoachID  int32     `json:"coach_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+func (q *Queries) CreateCoachAvailabilityException(ctx context.Context, arg CreateCoachAvailabilityExceptionParams) (CoachAvailabilityException, error) {
+	row := q.db.QueryRow(ctx, createCoachAvailabilityException,
+		arg.CoachID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+	)
+	var i CoachAvailabilityException
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCreditLedgerEntry = `-- name: CreateCreditLedgerEntry :one
+INSERT INTO credit_ledger (user_id, kind, delta, lot_id, expires_at, subscription_id, attendance_id, refund_of, reason, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, user_id, kind, delta, lot_id, expires_at, subscription_id, attendance_id, refund_of, reason, created_by, created_at
+`
+
+type CreateCreditLedgerEntryParams struct {
+	UserID         int32              `json:"user_id"`
+	Kind           string             `json:"kind"`
+	Delta          int32              `json:"delta"`
+	LotID          pgtype.Int4        `json:"lot_id"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	SubscriptionID pgtype.Int4        `json:"subscription_id"`
+	AttendanceID   pgtype.Int4        `json:"attendance_id"`
+	RefundOf       pgtype.Int4        `json:"refund_of"`
+	Reason         string             `json:"reason"`
+	CreatedBy      pgtype.Int4        `json:"created_by"`
+}
+
+func (q *Queries) CreateCreditLedgerEntry(ctx context.Context, arg CreateCreditLedgerEntryParams) (CreditLedger, error) {
+	row := q.db.QueryRow(ctx, createCreditLedgerEntry,
+		arg.UserID,
+		arg.Kind,
+		arg.Delta,
+		arg.LotID,
+		arg.ExpiresAt,
+		arg.SubscriptionID,
+		arg.AttendanceID,
+		arg.RefundOf,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var i CreditLedger
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Delta,
+		&i.LotID,
+		&i.ExpiresAt,
+		&i.SubscriptionID,
+		&i.AttendanceID,
+		&i.RefundOf,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createEmailVerificationToken = `-- name: CreateEmailVerificationToken :one
 INSERT INTO email_verification_tokens (email, verification_token, hashed_password_cache_key, expires_at)
 VALUES ($1, $2, $3, $4)
 RETURNING id, email, verification_token, hashed_password_cache_key, token_type, created_at, expires_at
 `
 
-type CreateEmailVerificationTokenParams struct {
-	Email                  string    `json:"email"`
-	VerificationToken      string    `json:"verification_token"`
-	HashedPasswordCacheKey string    `json:"hashed_password_cache_key"`
-	ExpiresAt              time.Time `json:"expires_at"`
+type CreateEmailVerificationTokenParams struct {
+	Email                  string    `json:"email"`
+	VerificationToken      string    `json:"verification_token"`
+	HashedPasswordCacheKey string    `json:"hashed_password_cache_key"`
+	ExpiresAt              time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error) {
+	row := q.db.QueryRow(ctx, createEmailVerificationToken,
+		arg.Email,
+		arg.VerificationToken,
+		arg.HashedPasswordCacheKey,
+		arg.ExpiresAt,
+	)
+	var i EmailVerificationToken
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.VerificationToken,
+		&i.HashedPasswordCacheKey,
+		&i.TokenType,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const createLegalAcceptance = `-- name: CreateLegalAcceptance :exec
+INSERT INTO legal_acceptances (user_id, document_id, ip_address)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, document_id) DO NOTHING
+`
+
+type CreateLegalAcceptanceParams struct {
+	UserID     int32      `json:"user_id"`
+	DocumentID int32      `json:"document_id"`
+	IpAddress  netip.Addr `json:"ip_address"`
+}
+
+func (q *Queries) CreateLegalAcceptance(ctx context.Context, arg CreateLegalAcceptanceParams) error {
+	_, err := q.db.Exec(ctx, createLegalAcceptance, arg.UserID, arg.DocumentID, arg.IpAddress)
+	return err
+}
+
+const createMembershipFreeze = `-- name: CreateMembershipFreeze :one
+INSERT INTO membership_freezes (subscription_id, user_id, starts_at, ends_at, reason, requested_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, subscription_id, user_id, status, starts_at, ends_at, ended_at, reason, requested_by, created_at, updated_at
+`
+
+type CreateMembershipFreezeParams struct {
+	SubscriptionID int32       `json:"subscription_id"`
+	UserID         int32       `json:"user_id"`
+	StartsAt       time.Time   `json:"starts_at"`
+	EndsAt         time.Time   `json:"ends_at"`
+	Reason         string      `json:"reason"`
+	RequestedBy    pgtype.Int4 `json:"requested_by"`
+}
+
+func (q *Queries) CreateMembershipFreeze(ctx context.Context, arg CreateMembershipFreezeParams) (MembershipFreeze, error) {
+	row := q.db.QueryRow(ctx, createMembershipFreeze,
+		arg.SubscriptionID,
+		arg.UserID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Reason,
+		arg.RequestedBy,
+	)
+	var i MembershipFreeze
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.UserID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.EndedAt,
+		&i.Reason,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createMembershipPlan = `-- name: CreateMembershipPlan :one
+INSERT INTO membership_plans (name, description, price_cents, billing_period, validity_days, class_allowance, max_freezes_per_year, max_freeze_days_per_year)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, name, description, price_cents, billing_period, validity_days, class_allowance, is_active, created_at, updated_at, max_freezes_per_year, max_freeze_days_per_year
+`
+
+type CreateMembershipPlanParams struct {
+	Name                 string      `json:"name"`
+	Description          string      `json:"description"`
+	PriceCents           int32       `json:"price_cents"`
+	BillingPeriod        string      `json:"billing_period"`
+	ValidityDays         pgtype.Int4 `json:"validity_days"`
+	ClassAllowance       pgtype.Int4 `json:"class_allowance"`
+	MaxFreezesPerYear    int32       `json:"max_freezes_per_year"`
+	MaxFreezeDaysPerYear int32       `json:"max_freeze_days_per_year"`
+}
+
+func (q *Queries) CreateMembershipPlan(ctx context.Context, arg CreateMembershipPlanParams) (MembershipPlan, error) {
+	row := q.db.QueryRow(ctx, createMembershipPlan,
+		arg.Name,
+		arg.Description,
+		arg.PriceCents,
+		arg.BillingPeriod,
+		arg.ValidityDays,
+		arg.ClassAllowance,
+		arg.MaxFreezesPerYear,
+		arg.MaxFreezeDaysPerYear,
+	)
+	var i MembershipPlan
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceCents,
+		&i.BillingPeriod,
+		&i.ValidityDays,
+		&i.ClassAllowance,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxFreezesPerYear,
+		&i.MaxFreezeDaysPerYear,
+	)
+	return i, err
+}
+
+const createPenalty = `-- name: CreatePenalty :one
+INSERT INTO penalties (user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at
+`
+
+type CreatePenaltyParams struct {
+	UserID        int32              `json:"user_id"`
+	BookingID     int32              `json:"booking_id"`
+	Reason        string             `json:"reason"`
+	Kind          string             `json:"kind"`
+	FeeCents      int32              `json:"fee_cents"`
+	CreditEntryID pgtype.Int4        `json:"credit_entry_id"`
+	BannedUntil   pgtype.Timestamptz `json:"banned_until"`
+}
+
+func (q *Queries) CreatePenalty(ctx context.Context, arg CreatePenaltyParams) (Penalty, error) {
+	row := q.db.QueryRow(ctx, createPenalty,
+		arg.UserID,
+		arg.BookingID,
+		arg.Reason,
+		arg.Kind,
+		arg.FeeCents,
+		arg.CreditEntryID,
+		arg.BannedUntil,
+	)
+	var i Penalty
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BookingID,
+		&i.Reason,
+		&i.Kind,
+		&i.FeeCents,
+		&i.CreditEntryID,
+		&i.BannedUntil,
+		&i.WaivedAt,
+		&i.WaivedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPendingLegalAcceptance = `-- name: CreatePendingLegalAcceptance :exec
+INSERT INTO pending_legal_acceptances (email, document_id, ip_address)
+VALUES ($1, $2, $3)
+ON CONFLICT (email, document_id) DO UPDATE
+SET ip_address = EXCLUDED.ip_address, accepted_at = NOW()
+`
+
+type CreatePendingLegalAcceptanceParams struct {
+	Email      string     `json:"email"`
+	DocumentID int32      `json:"document_id"`
+	IpAddress  netip.Addr `json:"ip_address"`
+}
+
+func (q *Queries) CreatePendingLegalAcceptance(ctx context.Context, arg CreatePendingLegalAcceptanceParams) error {
+	_, err := q.db.Exec(ctx, createPendingLegalAcceptance, arg.Email, arg.DocumentID, arg.IpAddress)
+	return err
+}
+
+const createRoom = `-- name: CreateRoom :one
+INSERT INTO rooms (name, capacity)
+VALUES ($1, $2)
+RETURNING id, name, capacity, created_at
+`
+
+type CreateRoomParams struct {
+	Name     string `json:"name"`
+	Capacity int32  `json:"capacity"`
+}
+
+func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
+	row := q.db.QueryRow(ctx, createRoom, arg.Name, arg.Capacity)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Capacity,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createSparringPairings = `-- name: CreateSparringPairings :many
+INSERT INTO sparring_pairings (session_id, fighter_a_id, fighter_b_id, recorded_by)
+SELECT $1, unnest($2::INTEGER[]), unnest($3::INTEGER[]), $4
+ON CONFLICT DO NOTHING
+RETURNING id, session_id, fighter_a_id, fighter_b_id, recorded_by, created_at
+`
+
+type CreateSparringPairingsParams struct {
+	SessionID   int32       `json:"session_id"`
+	FighterAIds []int32     `json:"fighter_a_ids"`
+	FighterBIds []int32     `json:"fighter_b_ids"`
+	RecordedBy  pgtype.Int4 `json:"recorded_by"`
+}
+
+func (q *Queries) CreateSparringPairings(ctx context.Context, arg CreateSparringPairingsParams) ([]SparringPairing, error) {
+	rows, err := q.db.Query(ctx, createSparringPairings,
+		arg.SessionID,
+		arg.FighterAIds,
+		arg.FighterBIds,
+		arg.RecordedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SparringPairing
+	for rows.Next() {
+		var i SparringPairing
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.FighterAID,
+			&i.FighterBID,
+			&i.RecordedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createSubscription = `-- name: CreateSubscription :one
+INSERT INTO subscriptions (user_id, plan_id, starts_at, ends_at, renews_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+type CreateSubscriptionParams struct {
+	UserID    int32              `json:"user_id"`
+	PlanID    int32              `json:"plan_id"`
+	StartsAt  time.Time          `json:"starts_at"`
+	EndsAt    time.Time          `json:"ends_at"`
+	RenewsAt  pgtype.Timestamptz `json:"renews_at"`
+	CreatedBy pgtype.Int4        `json:"created_by"`
+}
+
+func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, createSubscription,
+		arg.UserID,
+		arg.PlanID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.RenewsAt,
+		arg.CreatedBy,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createTrainingSession = `-- name: CreateTrainingSession :one
+INSERT INTO training_sessions (coach_id, member_id, starts_at, ends_at, note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at
+`
+
+type CreateTrainingSessionParams struct {
+	CoachID  int32     `json:"coach_id"`
+	MemberID int32     `json:"member_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Note     string    `json:"note"`
+}
+
+func (q *Queries) CreateTrainingSession(ctx context.Context, arg CreateTrainingSessionParams) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, createTrainingSession,
+		arg.CoachID,
+		arg.MemberID,
+		arg.StartsAt,
+		arg.EndsAt,
+		arg.Note,
+	)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (email, password, status, updated_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url
+`
+
+type CreateUserParams struct {
+	Email    string `json:"email"`
+	Password []byte `json:"password"`
+	Status   string `json:"status"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.Password, arg.Status)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Role,
+		&i.PhotoUrl,
+	)
+	return i, err
+}
+
+const createUserStatusTransition = `-- name: CreateUserStatusTransition :one
+INSERT INTO user_status_transitions (user_id, from_status, to_status, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, from_status, to_status, reason, created_at
+`
+
+type CreateUserStatusTransitionParams struct {
+	UserID     int32       `json:"user_id"`
+	FromStatus pgtype.Text `json:"from_status"`
+	ToStatus   string      `json:"to_status"`
+	Reason     pgtype.Text `json:"reason"`
+}
+
+func (q *Queries) CreateUserStatusTransition(ctx context.Context, arg CreateUserStatusTransitionParams) (UserStatusTransition, error) {
+	row := q.db.QueryRow(ctx, createUserStatusTransition,
+		arg.UserID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Reason,
+	)
+	var i UserStatusTransition
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWeighIn = `-- name: CreateWeighIn :one
+INSERT INTO weigh_ins (user_id, weight_kg, body_fat_percent, measured_at, source, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, weight_kg, body_fat_percent, measured_at, source, notes, recorded_by, created_at
+`
+
+type CreateWeighInParams struct {
+	UserID         int32         `json:"user_id"`
+	WeightKg       float64       `json:"weight_kg"`
+	BodyFatPercent pgtype.Float8 `json:"body_fat_percent"`
+	MeasuredAt     time.Time     `json:"measured_at"`
+	Source         string        `json:"source"`
+	Notes          string        `json:"notes"`
+	RecordedBy     pgtype.Int4   `json:"recorded_by"`
+}
+
+func (q *Queries) CreateWeighIn(ctx context.Context, arg CreateWeighInParams) (WeighIn, error) {
+	row := q.db.QueryRow(ctx, createWeighIn,
+		arg.UserID,
+		arg.WeightKg,
+		arg.BodyFatPercent,
+		arg.MeasuredAt,
+		arg.Source,
+		arg.Notes,
+		arg.RecordedBy,
+	)
+	var i WeighIn
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.WeightKg,
+		&i.BodyFatPercent,
+		&i.MeasuredAt,
+		&i.Source,
+		&i.Notes,
+		&i.RecordedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBout = `-- name: DeleteBout :execrows
+DELETE FROM bouts
+WHERE id = $1
+`
+
+func (q *Queries) DeleteBout(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBout, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCoachAvailability = `-- name: DeleteCoachAvailability :exec
+DELETE FROM coach_availability
+WHERE coach_id = $1
+`
+
+func (q *Queries) DeleteCoachAvailability(ctx context.Context, coachID int32) error {
+	_, err := q.db.Exec(ctx, deleteCoachAvailability, coachID)
+	return err
+}
+
+const deleteCoachAvailabilityException = `-- name: DeleteCoachAvailabilityException :execrows
+DELETE FROM coach_availability_exceptions
+WHERE id = $1 AND coach_id = $2
+`
+
+type DeleteCoachAvailabilityExceptionParams struct {
+	ID      int32 `json:"id"`
+	CoachID int32 `json:"coach_id"`
+}
+
+func (q *Queries) DeleteCoachAvailabilityException(ctx context.Context, arg DeleteCoachAvailabilityExceptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCoachAvailabilityException, arg.ID, arg.CoachID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteEmailVerificationTokenByID = `-- name: DeleteEmailVerificationTokenByID :exec
+DELETE FROM email_verification_tokens
+WHERE id = $1
+`
+
+func (q *Queries) DeleteEmailVerificationTokenByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteEmailVerificationTokenByID, id)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, deleteUser, id)
+	return err
+}
+
+const deleteWeighIn = `-- name: DeleteWeighIn :execrows
+DELETE FROM weigh_ins
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteWeighInParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) DeleteWeighIn(ctx context.Context, arg DeleteWeighInParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWeighIn, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWeightTarget = `-- name: DeleteWeightTarget :execrows
+DELETE FROM weight_targets
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteWeightTarget(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWeightTarget, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const endDueFreezes = `-- name: EndDueFreezes :many
+WITH ended AS (
+  UPDATE membership_freezes
+  SET status = 'completed', ended_at = ends_at, updated_at = NOW()
+  WHERE status = 'active' AND ends_at <= NOW()
+  RETURNING subscription_id
+)
+UPDATE subscriptions
+SET status = 'active', updated_at = NOW()
+WHERE id IN (SELECT subscription_id FROM ended) AND status = 'frozen'
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+// Completes the freezes that reached their end date and unfreezes the
+// subscriptions.
+func (q *Queries) EndDueFreezes(ctx context.Context) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, endDueFreezes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PlanID,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.RenewsAt,
+			&i.PausedAt,
+			&i.CancelledAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const expireLapsedSubscriptions = `-- name: ExpireLapsedSubscriptions :exec
+UPDATE subscriptions
+SET status = 'expired', updated_at = NOW()
+WHERE status = 'active' AND ends_at <= NOW()
+`
+
+// Active subscriptions that reached their end date without being renewed
+// expire. Paused and frozen ones have their end date moved when they resume.
+func (q *Queries) ExpireLapsedSubscriptions(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, expireLapsedSubscriptions)
+	return err
+}
+
+const freezeSubscription = `-- name: FreezeSubscription :exec
+UPDATE subscriptions
+SET status = 'frozen', updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+`
+
+func (q *Queries) FreezeSubscription(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, freezeSubscription, id)
+	return err
+}
+
+const getActiveBan = `-- name: GetActiveBan :one
+SELECT id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at FROM penalties
+WHERE user_id = $1 AND kind = 'ban' AND waived_at IS NULL AND banned_until > NOW()
+ORDER BY banned_until DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveBan(ctx context.Context, userID int32) (Penalty, error) {
+	row := q.db.QueryRow(ctx, getActiveBan, userID)
+	var i Penalty
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BookingID,
+		&i.Reason,
+		&i.Kind,
+		&i.FeeCents,
+		&i.CreditEntryID,
+		&i.BannedUntil,
+		&i.WaivedAt,
+		&i.WaivedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getActiveBooking = `-- name: GetActiveBooking :one
+SELECT id, session_id, user_id, status, promoted_at, cancelled_at, created_at, updated_at, checked_in_at, late_cancellation FROM bookings
+WHERE session_id = $1 AND user_id = $2 AND status <> 'cancelled'
+`
+
+type GetActiveBookingParams struct {
+	SessionID int32 `json:"session_id"`
+	UserID    int32 `json:"user_id"`
+}
+
+func (q *Queries) GetActiveBooking(ctx context.Context, arg GetActiveBookingParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, getActiveBooking, arg.SessionID, arg.UserID)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.UserID,
+		&i.Status,
+		&i.PromotedAt,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CheckedInAt,
+		&i.LateCancellation,
+	)
+	return i, err
+}
+
+const getActiveMembershipFreeze = `-- name: GetActiveMembershipFreeze :one
+SELECT id, subscription_id, user_id, status, starts_at, ends_at, ended_at, reason, requested_by, created_at, updated_at FROM membership_freezes
+WHERE user_id = $1 AND status = 'active'
+ORDER BY ends_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveMembershipFreeze(ctx context.Context, userID int32) (MembershipFreeze, error) {
+	row := q.db.QueryRow(ctx, getActiveMembershipFreeze, userID)
+	var i MembershipFreeze
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.UserID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.EndedAt,
+		&i.Reason,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getActiveMembershipPlans = `-- name: GetActiveMembershipPlans :many
+SELECT id, name, description, price_cents, billing_period, validity_days, class_allowance, is_active, created_at, updated_at, max_freezes_per_year, max_freeze_days_per_year FROM membership_plans
+WHERE is_active
+ORDER BY price_cents
+`
+
+func (q *Queries) GetActiveMembershipPlans(ctx context.Context) ([]MembershipPlan, error) {
+	rows, err := q.db.Query(ctx, getActiveMembershipPlans)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MembershipPlan
+	for rows.Next() {
+		var i MembershipPlan
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceCents,
+			&i.BillingPeriod,
+			&i.ValidityDays,
+			&i.ClassAllowance,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MaxFreezesPerYear,
+			&i.MaxFreezeDaysPerYear,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllUsers = `-- name: GetAllUsers :many
+SELECT id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url FROM users
+`
+
+func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
+	rows, err := q.db.Query(ctx, getAllUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.Role,
+			&i.PhotoUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceCountsByClassType = `-- name: GetAttendanceCountsByClassType :many
+SELECT
+  attendances.class_type_id,
+  class_types.name AS class_type_name,
+  COUNT(*) AS attendances
+FROM attendances
+LEFT JOIN class_types ON class_types.id = attendances.class_type_id
+WHERE attendances.user_id = $1
+  AND attendances.checked_in_at >= $2
+  AND attendances.checked_in_at < $3
+GROUP BY attendances.class_type_id, class_types.name
+ORDER BY attendances DESC
+`
+
+type GetAttendanceCountsByClassTypeParams struct {
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAttendanceCountsByClassTypeRow struct {
+	ClassTypeID   pgtype.Int4 `json:"class_type_id"`
+	ClassTypeName pgtype.Text `json:"class_type_name"`
+	Attendances   int64       `json:"attendances"`
+}
+
+func (q *Queries) GetAttendanceCountsByClassType(ctx context.Context, arg GetAttendanceCountsByClassTypeParams) ([]GetAttendanceCountsByClassTypeRow, error) {
+	rows, err := q.db.Query(ctx, getAttendanceCountsByClassType, arg.UserID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendanceCountsByClassTypeRow
+	for rows.Next() {
+		var i GetAttendanceCountsByClassTypeRow
+		if err := rows.Scan(&i.ClassTypeID, &i.ClassTypeName, &i.Attendances); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceCountsByMonth = `-- name: GetAttendanceCountsByMonth :many
+SELECT
+  date_trunc('month', checked_in_at AT TIME ZONE $1::TEXT)::DATE AS month,
+  COUNT(*) AS attendances
+FROM attendances
+WHERE user_id = $2
+  AND checked_in_at >= $3
+  AND checked_in_at < $4
+GROUP BY month
+ORDER BY month
+`
+
+type GetAttendanceCountsByMonthParams struct {
+	Timezone string    `json:"timezone"`
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAttendanceCountsByMonthRow struct {
+	Month       time.Time `json:"month"`
+	Attendances int64     `json:"attendances"`
+}
+
+func (q *Queries) GetAttendanceCountsByMonth(ctx context.Context, arg GetAttendanceCountsByMonthParams) ([]GetAttendanceCountsByMonthRow, error) {
+	rows, err := q.db.Query(ctx, getAttendanceCountsByMonth,
+		arg.Timezone,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendanceCountsByMonthRow
+	for rows.Next() {
+		var i GetAttendanceCountsByMonthRow
+		if err := rows.Scan(&i.Month, &i.Attendances); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceCountsByWeek = `-- name: GetAttendanceCountsByWeek :many
+SELECT
+  date_trunc('week', checked_in_at AT TIME ZONE $1::TEXT)::DATE AS week,
+  COUNT(*) AS attendances
+FROM attendances
+WHERE user_id = $2
+  AND checked_in_at >= $3
+  AND checked_in_at < $4
+GROUP BY week
+ORDER BY week
+`
+
+type GetAttendanceCountsByWeekParams struct {
+	Timezone string    `json:"timezone"`
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAttendanceCountsByWeekRow struct {
+	Week        time.Time `json:"week"`
+	Attendances int64     `json:"attendances"`
+}
+
+func (q *Queries) GetAttendanceCountsByWeek(ctx context.Context, arg GetAttendanceCountsByWeekParams) ([]GetAttendanceCountsByWeekRow, error) {
+	rows, err := q.db.Query(ctx, getAttendanceCountsByWeek,
+		arg.Timezone,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendanceCountsByWeekRow
+	for rows.Next() {
+		var i GetAttendanceCountsByWeekRow
+		if err := rows.Scan(&i.Week, &i.Attendances); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceLog = `-- name: GetAttendanceLog :many
+SELECT attendances.id, attendances.user_id, attendances.session_id, attendances.source, attendances.recorded_by, attendances.checked_in_at, attendances.class_type_id, class_types.name AS class_type_name
+FROM attendances
+LEFT JOIN class_types ON class_types.id = attendances.class_type_id
+WHERE attendances.user_id = $1
+  AND attendances.checked_in_at >= $2
+  AND attendances.checked_in_at < $3
+ORDER BY attendances.checked_in_at DESC
+`
+
+type GetAttendanceLogParams struct {
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetAttendanceLogRow struct {
+	ID            int32       `json:"id"`
+	UserID        int32       `json:"user_id"`
+	SessionID     pgtype.Int4 `json:"session_id"`
+	Source        string      `json:"source"`
+	RecordedBy    pgtype.Int4 `json:"recorded_by"`
+	CheckedInAt   time.Time   `json:"checked_in_at"`
+	ClassTypeID   pgtype.Int4 `json:"class_type_id"`
+	ClassTypeName pgtype.Text `json:"class_type_name"`
+}
+
+func (q *Queries) GetAttendanceLog(ctx context.Context, arg GetAttendanceLogParams) ([]GetAttendanceLogRow, error) {
+	rows, err := q.db.Query(ctx, getAttendanceLog, arg.UserID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAttendanceLogRow
+	for rows.Next() {
+		var i GetAttendanceLogRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.SessionID,
+			&i.Source,
+			&i.RecordedBy,
+			&i.CheckedInAt,
+			&i.ClassTypeID,
+			&i.ClassTypeName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAttendanceStreaks = `-- name: GetAttendanceStreaks :one
+WITH weeks AS (
+  SELECT DISTINCT date_trunc('week', checked_in_at AT TIME ZONE $1::TEXT)::DATE AS week
+  FROM attendances
+  WHERE user_id = $2
+), islands AS (
+  SELECT week, week - (ROW_NUMBER() OVER (ORDER BY week) * 7)::INTEGER AS anchor
+  FROM weeks
+), streaks AS (
+  SELECT MAX(week) AS last_week, COUNT(*) AS weeks
+  FROM islands
+  GROUP BY anchor
+)
+SELECT
+  COALESCE(MAX(weeks), 0)::INTEGER AS longest_streak,
+  COALESCE(MAX(weeks) FILTER (
+    WHERE last_week >= date_trunc('week', NOW() AT TIME ZONE $1::TEXT)::DATE - 7
+  ), 0)::INTEGER AS current_streak
+FROM streaks
+`
+
+type GetAttendanceStreaksParams struct {
+	Timezone string `json:"timezone"`
+	UserID   int32  `json:"user_id"`
+}
+
+type GetAttendanceStreaksRow struct {
+	LongestStreak int32 `json:"longest_streak"`
+	CurrentStreak int32 `json:"current_streak"`
+}
+
+// A streak is a run of consecutive weeks with at least one attendance. Weeks
+// are numbered with ROW_NUMBER so that consecutive weeks share the same
+// week - n*7 anchor (gaps and islands). The current streak is the one that
+// includes this week or, if nothing was recorded yet this week, last week.
+func (q *Queries) GetAttendanceStreaks(ctx context.Context, arg GetAttendanceStreaksParams) (GetAttendanceStreaksRow, error) {
+	row := q.db.QueryRow(ctx, getAttendanceStreaks, arg.Timezone, arg.UserID)
+	var i GetAttendanceStreaksRow
+	err := row.Scan(&i.LongestStreak, &i.CurrentStreak)
+	return i, err
+}
+
+const getBoutsByFighterID = `-- name: GetBoutsByFighterID :many
+SELECT id, fighter_id, opponent, event, bout_date, level, result, method, round, notes, recorded_by, created_at FROM bouts
+WHERE fighter_id = $1
+ORDER BY bout_date DESC, id DESC
+`
+
+func (q *Queries) GetBoutsByFighterID(ctx context.Context, fighterID int32) ([]Bout, error) {
+	rows, err := q.db.Query(ctx, getBoutsByFighterID, fighterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Bout
+	for rows.Next() {
+		var i Bout
+		if err := rows.Scan(
+			&i.ID,
+			&i.FighterID,
+			&i.Opponent,
+			&i.Event,
+			&i.BoutDate,
+			&i.Level,
+			&i.Result,
+			&i.Method,
+			&i.Round,
+			&i.Notes,
+			&i.RecordedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCheckedInFighters = `-- name: GetCheckedInFighters :many
+SELECT DISTINCT ON (users.id)
+  users.id, users.email,
+  fighter_profiles.display_name, fighter_profiles.weight_class,
+  fighter_profiles.stance, fighter_profiles.experience
+FROM attendances
+JOIN users ON users.id = attendances.user_id
+LEFT JOIN fighter_profiles ON fighter_profiles.user_id = users.id
+WHERE attendances.session_id = $1
+ORDER BY users.id
+`
+
+type GetCheckedInFightersRow struct {
+	ID          int32       `json:"id"`
+	Email       string      `json:"email"`
+	DisplayName pgtype.Text `json:"display_name"`
+	WeightClass pgtype.Text `json:"weight_class"`
+	Stance      pgtype.Text `json:"stance"`
+	Experience  pgtype.Text `json:"experience"`
+}
+
+// Members checked in to a session, with their fighter profile if they have one.
+func (q *Queries) GetCheckedInFighters(ctx context.Context, sessionID pgtype.Int4) ([]GetCheckedInFightersRow, error) {
+	rows, err := q.db.Query(ctx, getCheckedInFighters, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCheckedInFightersRow
+	for rows.Next() {
+		var i GetCheckedInFightersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.DisplayName,
+			&i.WeightClass,
+			&i.Stance,
+			&i.Experience,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClassScheduleByID = `-- name: GetClassScheduleByID :one
+SELECT id, class_type_id, room_id, coach_id, dtstart, rrule, timezone, created_at, updated_at FROM class_schedules
+WHERE id = $1
+`
+
+func (q *Queries) GetClassScheduleByID(ctx context.Context, id int32) (ClassSchedule, error) {
+	row := q.db.QueryRow(ctx, getClassScheduleByID, id)
+	var i ClassSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.ClassTypeID,
+		&i.RoomID,
+		&i.CoachID,
+		&i.Dtstart,
+		&i.Rrule,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getClassScheduleExceptionsBetween = `-- name: GetClassScheduleExceptionsBetween :many
+SELECT id, schedule_id, starts_at, reason, created_at FROM class_schedule_exceptions
+WHERE starts_at >= $1 AND starts_at < $2
+`
+
+type GetClassScheduleExceptionsBetweenParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+func (q *Queries) GetClassScheduleExceptionsBetween(ctx context.Context, arg GetClassScheduleExceptionsBetweenParams) ([]ClassScheduleException, error) {
+	rows, err := q.db.Query(ctx, getClassScheduleExceptionsBetween, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClassScheduleException
+	for rows.Next() {
+		var i ClassScheduleException
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.StartsAt,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClassSession = `-- name: GetClassSession :one
+SELECT id, schedule_id, starts_at, ends_at, capacity, cancelled_at, created_at FROM class_sessions
+WHERE id = $1
+`
+
+func (q *Queries) GetClassSession(ctx context.Context, id int32) (ClassSession, error) {
+	row := q.db.QueryRow(ctx, getClassSession, id)
+	var i ClassSession
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Capacity,
+		&i.CancelledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getClassSessionForUpdate = `-- name: GetClassSessionForUpdate :one
+SELECT class_sessions.id, class_sessions.schedule_id, class_sessions.starts_at, class_sessions.ends_at, class_sessions.capacity, class_sessions.cancelled_at, class_sessions.created_at, class_types.name AS class_type_name, class_schedules.timezone,
+  class_types.cancellation_window_minutes, class_types.late_cancel_penalty, class_types.penalty_fee_cents
+FROM class_sessions
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+WHERE class_sessions.id = $1
+FOR UPDATE OF class_sessions
+`
+
+type GetClassSessionForUpdateRow struct {
+	ID                        int32              `json:"id"`
+	ScheduleID                int32              `json:"schedule_id"`
+	StartsAt                  time.Time          `json:"starts_at"`
+	EndsAt                    time.Time          `json:"ends_at"`
+	Capacity                  int32              `json:"capacity"`
+	CancelledAt               pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt                 time.Time          `json:"created_at"`
+	ClassTypeName             string             `json:"class_type_name"`
+	Timezone                  string             `json:"timezone"`
+	CancellationWindowMinutes int32              `json:"cancellation_window_minutes"`
+	LateCancelPenalty         string             `json:"late_cancel_penalty"`
+	PenaltyFeeCents           int32              `json:"penalty_fee_cents"`
+}
+
+func (q *Queries) GetClassSessionForUpdate(ctx context.Context, id int32) (GetClassSessionForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getClassSessionForUpdate, id)
+	var i GetClassSessionForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Capacity,
+		&i.CancelledAt,
+		&i.CreatedAt,
+		&i.ClassTypeName,
+		&i.Timezone,
+		&i.CancellationWindowMinutes,
+		&i.LateCancelPenalty,
+		&i.PenaltyFeeCents,
+	)
+	return i, err
+}
+
+const getClassSessionsBetween = `-- name: GetClassSessionsBetween :many
+SELECT id, schedule_id, starts_at, ends_at, capacity, cancelled_at, created_at FROM class_sessions
+WHERE starts_at >= $1 AND starts_at < $2
+ORDER BY starts_at
+`
+
+type GetClassSessionsBetweenParams struct {
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+func (q *Queries) GetClassSessionsBetween(ctx context.Context, arg GetClassSessionsBetweenParams) ([]ClassSession, error) {
+	rows, err := q.db.Query(ctx, getClassSessionsBetween, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClassSession
+	for rows.Next() {
+		var i ClassSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduleID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Capacity,
+			&i.CancelledAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClassTypes = `-- name: GetClassTypes :many
+SELECT id, name, description, duration_minutes, created_at, cancellation_window_minutes, late_cancel_penalty, no_show_penalty, penalty_fee_cents FROM class_types
+ORDER BY name
+`
+
+func (q *Queries) GetClassTypes(ctx context.Context) ([]ClassType, error) {
+	rows, err := q.db.Query(ctx, getClassTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClassType
+	for rows.Next() {
+		var i ClassType
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.DurationMinutes,
+			&i.CreatedAt,
+			&i.CancellationWindowMinutes,
+			&i.LateCancelPenalty,
+			&i.NoShowPenalty,
+			&i.PenaltyFeeCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClosuresBetween = `-- name: GetClosuresBetween :many
+SELECT id, starts_at, ends_at, reason, created_at FROM closures
+WHERE starts_at < $1 AND ends_at > $2
+ORDER BY starts_at
+`
+
+type GetClosuresBetweenParams struct {
+	ToTime   time.Time `json:"to_time"`
+	FromTime time.Time `json:"from_time"`
+}
+
+func (q *Queries) GetClosuresBetween(ctx context.Context, arg GetClosuresBetweenParams) ([]Closure, error) {
+	rows, err := q.db.Query(ctx, getClosuresBetween, arg.ToTime, arg.FromTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Closure
+	for rows.Next() {
+		var i Closure
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCoach = `-- name: GetCoach :one
+SELECT coach_profiles.user_id, coach_profiles.bio, coach_profiles.specialties, coach_profiles.certifications, coach_profiles.timezone, coach_profiles.created_at, coach_profiles.updated_at, users.email
+FROM coach_profiles
+JOIN users ON users.id = coach_profiles.user_id
+WHERE coach_profiles.user_id = $1 AND users.role IN ('coach', 'staff') AND users.status = 'active'
+`
+
+type GetCoachRow struct {
+	UserID         int32     `json:"user_id"`
+	Bio            string    `json:"bio"`
+	Specialties    []string  `json:"specialties"`
+	Certifications []string  `json:"certifications"`
+	Timezone       string    `json:"timezone"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Email          string    `json:"email"`
+}
+
+func (q *Queries) GetCoach(ctx context.Context, userID int32) (GetCoachRow, error) {
+	row := q.db.QueryRow(ctx, getCoach, userID)
+	var i GetCoachRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Bio,
+		&i.Specialties,
+		&i.Certifications,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+	)
+	return i, err
+}
+
+const getCoachAvailability = `-- name: GetCoachAvailability :many
+SELECT id, coach_id, weekday, start_time, end_time FROM coach_availability
+WHERE coach_id = $1
+ORDER BY weekday, start_time
+`
+
+func (q *Queries) GetCoachAvailability(ctx context.Context, coachID int32) ([]CoachAvailability, error) {
+	rows, err := q.db.Query(ctx, getCoachAvailability, coachID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CoachAvailability
+	for rows.Next() {
+		var i CoachAvailability
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoachID,
+			&i.Weekday,
+			&i.StartTime,
+			&i.EndTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCoachAvailabilityExceptionsBetween = `-- name: GetCoachAvailabilityExceptionsBetween :many
+SELECT id, coach_id, starts_at, ends_at, reason, created_at FROM coach_availability_exceptions
+WHERE coach_id = $1 AND starts_at < $2 AND ends_at > $3
+ORDER BY starts_at
+`
+
+type GetCoachAvailabilityExceptionsBetweenParams struct {
+	CoachID  int32     `json:"coach_id"`
+	ToTime   time.Time `json:"to_time"`
+	FromTime time.Time `json:"from_time"`
+}
+
+func (q *Queries) GetCoachAvailabilityExceptionsBetween(ctx context.Context, arg GetCoachAvailabilityExceptionsBetweenParams) ([]CoachAvailabilityException, error) {
+	rows, err := q.db.Query(ctx, getCoachAvailabilityExceptionsBetween, arg.CoachID, arg.ToTime, arg.FromTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CoachAvailabilityException
+	for rows.Next() {
+		var i CoachAvailabilityException
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoachID,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCoachClassSessionsBetween = `-- name: GetCoachClassSessionsBetween :many
+SELECT class_sessions.starts_at, class_sessions.ends_at
+FROM class_sessions
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+WHERE class_schedules.coach_id = $1
+  AND class_sessions.cancelled_at IS NULL
+  AND class_sessions.starts_at < $2
+  AND class_sessions.ends_at > $3
+ORDER BY class_sessions.starts_at
+`
+
+type GetCoachClassSessionsBetweenParams struct {
+	CoachID  pgtype.Int4 `json:"coach_id"`
+	ToTime   time.Time   `json:"to_time"`
+	FromTime time.Time   `json:"from_time"`
+}
+
+type GetCoachClassSessionsBetweenRow struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Group classes the coach teaches count as booked time.
+func (q *Queries) GetCoachClassSessionsBetween(ctx context.Context, arg GetCoachClassSessionsBetweenParams) ([]GetCoachClassSessionsBetweenRow, error) {
+	rows, err := q.db.Query(ctx, getCoachClassSessionsBetween, arg.CoachID, arg.ToTime, arg.FromTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCoachClassSessionsBetweenRow
+	for rows.Next() {
+		var i GetCoachClassSessionsBetweenRow
+		if err := rows.Scan(&i.StartsAt, &i.EndsAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCoachTrainingSessionsBetween = `-- name: GetCoachTrainingSessionsBetween :many
+SELECT id, starts_at, ends_at
+FROM training_sessions
+WHERE coach_id = $1
+  AND status IN ('requested', 'confirmed')
+  AND starts_at < $2
+  AND ends_at > $3
+ORDER BY starts_at
+`
+
+type GetCoachTrainingSessionsBetweenParams struct {
+	CoachID  int32     `json:"coach_id"`
+	ToTime   time.Time `json:"to_time"`
+	FromTime time.Time `json:"from_time"`
+}
+
+type GetCoachTrainingSessionsBetweenRow struct {
+	ID       int32     `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// Requested and confirmed sessions count as booked time.
+func (q *Queries) GetCoachTrainingSessionsBetween(ctx context.Context, arg GetCoachTrainingSessionsBetweenParams) ([]GetCoachTrainingSessionsBetweenRow, error) {
+	rows, err := q.db.Query(ctx, getCoachTrainingSessionsBetween, arg.CoachID, arg.ToTime, arg.FromTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCoachTrainingSessionsBetweenRow
+	for rows.Next() {
+		var i GetCoachTrainingSessionsBetweenRow
+		if err := rows.Scan(&i.ID, &i.StartsAt, &i.EndsAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCoaches = `-- name: GetCoaches :many
+SELECT coach_profiles.user_id, coach_profiles.bio, coach_profiles.specialties, coach_profiles.certifications, coach_profiles.timezone, coach_profiles.created_at, coach_profiles.updated_at, users.email
+FROM coach_profiles
+JOIN users ON users.id = coach_profiles.user_id
+WHERE users.role IN ('coach', 'staff') AND users.status = 'active'
+ORDER BY users.email
+`
+
+type GetCoachesRow struct {
+	UserID         int32     `json:"user_id"`
+	Bio            string    `json:"bio"`
+	Specialties    []string  `json:"specialties"`
+	Certifications []string  `json:"certifications"`
+	Timezone       string    `json:"timezone"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Email          string    `json:"email"`
+}
+
+func (q *Queries) GetCoaches(ctx context.Context) ([]GetCoachesRow, error) {
+	rows, err := q.db.Query(ctx, getCoaches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCoachesRow
+	for rows.Next() {
+		var i GetCoachesRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Bio,
+			&i.Specialties,
+			&i.Certifications,
+			&i.Timezone,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCreditBalance = `-- name: GetCreditBalance :one
+SELECT COALESCE(SUM(delta), 0)::INTEGER AS balance
+FROM credit_ledger
+WHERE user_id = $1
+`
+
+func (q *Queries) GetCreditBalance(ctx context.Context, userID int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getCreditBalance, userID)
+	var balance int32
+	err := row.Scan(&balance)
+	return balance, err
+}
+
+const getCreditLedger = `-- name: GetCreditLedger :many
+SELECT id, user_id, kind, delta, lot_id, expires_at, subscription_id, attendance_id, refund_of, reason, created_by, created_at FROM credit_ledger
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) GetCreditLedger(ctx context.Context, userID int32) ([]CreditLedger, error) {
+	rows, err := q.db.Query(ctx, getCreditLedger, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CreditLedger
+	for rows.Next() {
+		var i CreditLedger
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Kind,
+			&i.Delta,
+			&i.LotID,
+			&i.ExpiresAt,
+			&i.SubscriptionID,
+			&i.AttendanceID,
+			&i.RefundOf,
+			&i.Reason,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCreditLedgerEntryByID = `-- name: GetCreditLedgerEntryByID :one
+SELECT id, user_id, kind, delta, lot_id, expires_at, subscription_id, attendance_id, refund_of, reason, created_by, created_at FROM credit_ledger
+WHERE id = $1
+`
+
+func (q *Queries) GetCreditLedgerEntryByID(ctx context.Context, id int32) (CreditLedger, error) {
+	row := q.db.QueryRow(ctx, getCreditLedgerEntryByID, id)
+	var i CreditLedger
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.Delta,
+		&i.LotID,
+		&i.ExpiresAt,
+		&i.SubscriptionID,
+		&i.AttendanceID,
+		&i.RefundOf,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCurrentLegalDocuments = `-- name: GetCurrentLegalDocuments :many
+SELECT DISTINCT ON (kind) id, kind, version, title, body, published_at, created_at FROM legal_documents
+WHERE published_at <= NOW()
+ORDER BY kind, version DESC
+`
+
+func (q *Queries) GetCurrentLegalDocuments(ctx context.Context) ([]LegalDocument, error) {
+	rows, err := q.db.Query(ctx, getCurrentLegalDocuments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LegalDocument
+	for rows.Next() {
+		var i LegalDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Version,
+			&i.Title,
+			&i.Body,
+			&i.PublishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCurrentSubscription = `-- name: GetCurrentSubscription :one
+SELECT id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at FROM subscriptions
+WHERE user_id = $1 AND status = 'active' AND starts_at <= NOW() AND ends_at > NOW()
+ORDER BY ends_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetCurrentSubscription(ctx context.Context, userID int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getCurrentSubscription, userID)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getEmailVerificationTokenByEmail = `-- name: GetEmailVerificationTokenByEmail :one
+SELECT id, email, verification_token, hashed_password_cache_key, token_type, created_at, expires_at FROM email_verification_tokens
+WHERE email = $1
+`
+
+func (q *Queries) GetEmailVerificationTokenByEmail(ctx context.Context, email string) (EmailVerificationToken, error) {
+	row := q.db.QueryRow(ctx, getEmailVerificationTokenByEmail, email)
+	var i EmailVerificationToken
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.VerificationToken,
+		&i.HashedPasswordCacheKey,
+		&i.TokenType,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getFighterProfile = `-- name: GetFighterProfile :one
+SELECT user_id, display_name, weight_class, height_cm, reach_cm, stance, level, public, show_measurements, show_bouts, created_at, updated_at, experience FROM fighter_profiles
+WHERE user_id = $1
+`
+
+func (q *Queries) GetFighterProfile(ctx context.Context, userID int32) (FighterProfile, error) {
+	row := q.db.QueryRow(ctx, getFighterProfile, userID)
+	var i FighterProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.DisplayName,
+		&i.WeightClass,
+		&i.HeightCm,
+		&i.ReachCm,
+		&i.Stance,
+		&i.Level,
+		&i.Public,
+		&i.ShowMeasurements,
+		&i.ShowBouts,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Experience,
+	)
+	return i, err
+}
+
+const getFighterRecords = `-- name: GetFighterRecords :many
+SELECT fighter_id, level,
+  COUNT(*) FILTER (WHERE result = 'win')::INTEGER AS wins,
+  COUNT(*) FILTER (WHERE result = 'loss')::INTEGER AS losses,
+  COUNT(*) FILTER (WHERE result = 'draw')::INTEGER AS draws,
+  COUNT(*) FILTER (WHERE result = 'no_contest')::INTEGER AS no_contests,
+  COUNT(*) FILTER (WHERE result = 'win' AND method IN ('ko', 'tko', 'rsc'))::INTEGER AS ko_wins
+FROM bouts
+WHERE fighter_id = ANY($1::INTEGER[])
+GROUP BY fighter_id, level
+`
+
+type GetFighterRecordsRow struct {
+	FighterID  int32  `json:"fighter_id"`
+	Level      string `json:"level"`
+	Wins       int32  `json:"wins"`
+	Losses     int32  `json:"losses"`
+	Draws      int32  `json:"draws"`
+	NoContests int32  `json:"no_contests"`
+	KoWins     int32  `json:"ko_wins"`
+}
+
+// W-L-D totals per level for the given fighters.
+func (q *Queries) GetFighterRecords(ctx context.Context, fighterIds []int32) ([]GetFighterRecordsRow, error) {
+	rows, err := q.db.Query(ctx, getFighterRecords, fighterIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFighterRecordsRow
+	for rows.Next() {
+		var i GetFighterRecordsRow
+		if err := rows.Scan(
+			&i.FighterID,
+			&i.Level,
+			&i.Wins,
+			&i.Losses,
+			&i.Draws,
+			&i.NoContests,
+			&i.KoWins,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFreezeUsage = `-- name: GetFreezeUsage :one
+SELECT COUNT(*)::INTEGER AS freezes,
+  COALESCE(CEIL(SUM(EXTRACT(EPOCH FROM COALESCE(ended_at, ends_at) - starts_at)) / 86400), 0)::INTEGER AS days
+FROM membership_freezes
+WHERE user_id = $1
+  AND status <> 'cancelled'
+  AND starts_at >= $2
+  AND starts_at < $3
+`
+
+type GetFreezeUsageParams struct {
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+type GetFreezeUsageRow struct {
+	Freezes int32 `json:"freezes"`
+	Days    int32 `json:"days"`
+}
+
+// Counts a member's freezes starting in [from, to) and the days they cover.
+func (q *Queries) GetFreezeUsage(ctx context.Context, arg GetFreezeUsageParams) (GetFreezeUsageRow, error) {
+	row := q.db.QueryRow(ctx, getFreezeUsage, arg.UserID, arg.FromTime, arg.ToTime)
+	var i GetFreezeUsageRow
+	err := row.Scan(&i.Freezes, &i.Days)
+	return i, err
+}
+
+const getLatestAttendance = `-- name: GetLatestAttendance :one
+SELECT id, user_id, session_id, source, recorded_by, checked_in_at, class_type_id FROM attendances
+WHERE user_id = $1
+ORDER BY checked_in_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestAttendance(ctx context.Context, userID int32) (Attendance, error) {
+	row := q.db.QueryRow(ctx, getLatestAttendance, userID)
+	var i Attendance
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.SessionID,
+		&i.Source,
+		&i.RecordedBy,
+		&i.CheckedInAt,
+		&i.ClassTypeID,
+	)
+	return i, err
+}
+
+const getLatestBan = `-- name: GetLatestBan :one
+SELECT id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at FROM penalties
+WHERE user_id = $1 AND kind = 'ban'
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestBan(ctx context.Context, userID int32) (Penalty, error) {
+	row := q.db.QueryRow(ctx, getLatestBan, userID)
+	var i Penalty
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BookingID,
+		&i.Reason,
+		&i.Kind,
+		&i.FeeCents,
+		&i.CreditEntryID,
+		&i.BannedUntil,
+		&i.WaivedAt,
+		&i.WaivedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestWeighIns = `-- name: GetLatestWeighIns :many
+SELECT DISTINCT ON (user_id) user_id, weight_kg, measured_at
+FROM weigh_ins
+WHERE user_id = ANY($1::INTEGER[]) AND measured_at >= $2
+ORDER BY user_id, measured_at DESC
+`
+
+type GetLatestWeighInsParams struct {
+	UserIds []int32   `json:"user_ids"`
+	Since   time.Time `json:"since"`
+}
+
+type GetLatestWeighInsRow struct {
+	UserID     int32     `json:"user_id"`
+	WeightKg   float64   `json:"weight_kg"`
+	MeasuredAt time.Time `json:"measured_at"`
+}
+
+func (q *Queries) GetLatestWeighIns(ctx context.Context, arg GetLatestWeighInsParams) ([]GetLatestWeighInsRow, error) {
+	rows, err := q.db.Query(ctx, getLatestWeighIns, arg.UserIds, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLatestWeighInsRow
+	for rows.Next() {
+		var i GetLatestWeighInsRow
+		if err := rows.Scan(&i.UserID, &i.WeightKg, &i.MeasuredAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMembershipFreezeForUpdate = `-- name: GetMembershipFreezeForUpdate :one
+SELECT id, subscription_id, user_id, status, starts_at, ends_at, ended_at, reason, requested_by, created_at, updated_at FROM membership_freezes
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetMembershipFreezeForUpdate(ctx context.Context, id int32) (MembershipFreeze, error) {
+	row := q.db.QueryRow(ctx, getMembershipFreezeForUpdate, id)
+	var i MembershipFreeze
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.UserID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.EndedAt,
+		&i.Reason,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMembershipFreezesByUserID = `-- name: GetMembershipFreezesByUserID :many
+SELECT id, subscription_id, user_id, status, starts_at, ends_at, ended_at, reason, requested_by, created_at, updated_at FROM membership_freezes
+WHERE user_id = $1
+ORDER BY starts_at DESC
+`
+
+func (q *Queries) GetMembershipFreezesByUserID(ctx context.Context, userID int32) ([]MembershipFreeze, error) {
+	rows, err := q.db.Query(ctx, getMembershipFreezesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MembershipFreeze
+	for rows.Next() {
+		var i MembershipFreeze
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.UserID,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.EndedAt,
+			&i.Reason,
+			&i.RequestedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMembershipPlanByID = `-- name: GetMembershipPlanByID :one
+SELECT id, name, description, price_cents, billing_period, validity_days, class_allowance, is_active, created_at, updated_at, max_freezes_per_year, max_freeze_days_per_year FROM membership_plans
+WHERE id = $1
+`
+
+func (q *Queries) GetMembershipPlanByID(ctx context.Context, id int32) (MembershipPlan, error) {
+	row := q.db.QueryRow(ctx, getMembershipPlanByID, id)
+	var i MembershipPlan
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceCents,
+		&i.BillingPeriod,
+		&i.ValidityDays,
+		&i.ClassAllowance,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxFreezesPerYear,
+		&i.MaxFreezeDaysPerYear,
+	)
+	return i, err
+}
+
+const getOpenCreditLots = `-- name: GetOpenCreditLots :many
+SELECT lot.id, lot.expires_at, (lot.delta + COALESCE(SUM(entry.delta), 0))::INTEGER AS remaining
+FROM credit_ledger AS lot
+LEFT JOIN credit_ledger AS entry ON entry.lot_id = lot.id
+WHERE lot.user_id = $1 AND lot.lot_id IS NULL
+GROUP BY lot.id
+HAVING lot.delta + COALESCE(SUM(entry.delta), 0) > 0
+ORDER BY lot.expires_at NULLS LAST, lot.id
+`
+
+type GetOpenCreditLotsRow struct {
+	ID        int32              `json:"id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	Remaining int32              `json:"remaining"`
+}
+
+// Lots that still have credits left, soonest to expire first.
+func (q *Queries) GetOpenCreditLots(ctx context.Context, userID int32) ([]GetOpenCreditLotsRow, error) {
+	rows, err := q.db.Query(ctx, getOpenCreditLots, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOpenCreditLotsRow
+	for rows.Next() {
+		var i GetOpenCreditLotsRow
+		if err := rows.Scan(&i.ID, &i.ExpiresAt, &i.Remaining); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOutstandingLegalDocuments = `-- name: GetOutstandingLegalDocuments :many
+SELECT id, kind, version, title, body, published_at, created_at FROM legal_documents
+WHERE id IN (
+  SELECT DISTINCT ON (kind) id FROM legal_documents
+  WHERE published_at <= NOW()
+  ORDER BY kind, version DESC
+)
+AND NOT EXISTS (
+  SELECT 1 FROM legal_acceptances
+  WHERE legal_acceptances.document_id = legal_documents.id
+    AND legal_acceptances.user_id = $1
+)
+ORDER BY kind
+`
+
+func (q *Queries) GetOutstandingLegalDocuments(ctx context.Context, userID int32) ([]LegalDocument, error) {
+	rows, err := q.db.Query(ctx, getOutstandingLegalDocuments, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LegalDocument
+	for rows.Next() {
+		var i LegalDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Version,
+			&i.Title,
+			&i.Body,
+			&i.PublishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPenaltiesByUserID = `-- name: GetPenaltiesByUserID :many
+SELECT id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at FROM penalties
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) GetPenaltiesByUserID(ctx context.Context, userID int32) ([]Penalty, error) {
+	rows, err := q.db.Query(ctx, getPenaltiesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Penalty
+	for rows.Next() {
+		var i Penalty
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.BookingID,
+			&i.Reason,
+			&i.Kind,
+			&i.FeeCents,
+			&i.CreditEntryID,
+			&i.BannedUntil,
+			&i.WaivedAt,
+			&i.WaivedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPenaltyByID = `-- name: GetPenaltyByID :one
+SELECT id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at FROM penalties
+WHERE id = $1
+`
+
+func (q *Queries) GetPenaltyByID(ctx context.Context, id int32) (Penalty, error) {
+	row := q.db.QueryRow(ctx, getPenaltyByID, id)
+	var i Penalty
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BookingID,
+		&i.Reason,
+		&i.Kind,
+		&i.FeeCents,
+		&i.CreditEntryID,
+		&i.BannedUntil,
+		&i.WaivedAt,
+		&i.WaivedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPublicFighterProfile = `-- name: GetPublicFighterProfile :one
+SELECT fighter_profiles.user_id, fighter_profiles.display_name, fighter_profiles.weight_class, fighter_profiles.height_cm, fighter_profiles.reach_cm, fighter_profiles.stance, fighter_profiles.level, fighter_profiles.public, fighter_profiles.show_measurements, fighter_profiles.show_bouts, fighter_profiles.created_at, fighter_profiles.updated_at, fighter_profiles.experience
+FROM fighter_profiles
+JOIN users ON users.id = fighter_profiles.user_id
+WHERE fighter_profiles.user_id = $1 AND fighter_profiles.public AND users.status = 'active'
+`
+
+func (q *Queries) GetPublicFighterProfile(ctx context.Context, userID int32) (FighterProfile, error) {
+	row := q.db.QueryRow(ctx, getPublicFighterProfile, userID)
+	var i FighterProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.DisplayName,
+		&i.WeightClass,
+		&i.HeightCm,
+		&i.ReachCm,
+		&i.Stance,
+		&i.Level,
+		&i.Public,
+		&i.ShowMeasurements,
+		&i.ShowBouts,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Experience,
+	)
+	return i, err
+}
+
+const getPublicFighterProfiles = `-- name: GetPublicFighterProfiles :many
+SELECT fighter_profiles.user_id, fighter_profiles.display_name, fighter_profiles.weight_class, fighter_profiles.height_cm, fighter_profiles.reach_cm, fighter_profiles.stance, fighter_profiles.level, fighter_profiles.public, fighter_profiles.show_measurements, fighter_profiles.show_bouts, fighter_profiles.created_at, fighter_profiles.updated_at, fighter_profiles.experience
+FROM fighter_profiles
+JOIN users ON users.id = fighter_profiles.user_id
+WHERE fighter_profiles.public AND users.status = 'active'
+  AND ($1::VARCHAR IS NULL OR fighter_profiles.weight_class = $1)
+ORDER BY fighter_profiles.display_name
+`
+
+func (q *Queries) GetPublicFighterProfiles(ctx context.Context, weightClass pgtype.Text) ([]FighterProfile, error) {
+	rows, err := q.db.Query(ctx, getPublicFighterProfiles, weightClass)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FighterProfile
+	for rows.Next() {
+		var i FighterProfile
+		if err := rows.Scan(
+			&i.UserID,
+			&i.DisplayName,
+			&i.WeightClass,
+			&i.HeightCm,
+			&i.ReachCm,
+			&i.Stance,
+			&i.Level,
+			&i.Public,
+			&i.ShowMeasurements,
+			&i.ShowBouts,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Experience,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentSparringPairs = `-- name: GetRecentSparringPairs :many
+SELECT fighter_a_id, fighter_b_id, COUNT(*)::INTEGER AS times
+FROM sparring_pairings
+WHERE fighter_a_id = ANY($1::INTEGER[])
+  AND fighter_b_id = ANY($1::INTEGER[])
+  AND created_at >= $2
+GROUP BY fighter_a_id, fighter_b_id
+`
+
+type GetRecentSparringPairsParams struct {
+	FighterIds []int32   `json:"fighter_ids"`
+	Since      time.Time `json:"since"`
+}
+
+type GetRecentSparringPairsRow struct {
+	FighterAID int32 `json:"fighter_a_id"`
+	FighterBID int32 `json:"fighter_b_id"`
+	Times      int32 `json:"times"`
+}
+
+func (q *Queries) GetRecentSparringPairs(ctx context.Context, arg GetRecentSparringPairsParams) ([]GetRecentSparringPairsRow, error) {
+	rows, err := q.db.Query(ctx, getRecentSparringPairs, arg.FighterIds, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentSparringPairsRow
+	for rows.Next() {
+		var i GetRecentSparringPairsRow
+		if err := rows.Scan(&i.FighterAID, &i.FighterBID, &i.Times); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRooms = `-- name: GetRooms :many
+SELECT id, name, capacity, created_at FROM rooms
+ORDER BY name
+`
+
+func (q *Queries) GetRooms(ctx context.Context) ([]Room, error) {
+	rows, err := q.db.Query(ctx, getRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Capacity,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScheduledClasses = `-- name: GetScheduledClasses :many
+SELECT
+  class_schedules.id, class_schedules.class_type_id, class_schedules.room_id, class_schedules.coach_id, class_schedules.dtstart, class_schedules.rrule, class_schedules.timezone, class_schedules.created_at, class_schedules.updated_at,
+  class_types.name AS class_type_name,
+  class_types.duration_minutes,
+  rooms.name AS room_name,
+  rooms.capacity
+FROM class_schedules
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+JOIN rooms ON rooms.id = class_schedules.room_id
+WHERE class_schedules.dtstart < $1
+ORDER BY class_schedules.id
+`
+
+type GetScheduledClassesRow struct {
+	ID              int32       `json:"id"`
+	ClassTypeID     int32       `json:"class_type_id"`
+	RoomID          int32       `json:"room_id"`
+	CoachID         pgtype.Int4 `json:"coach_id"`
+	Dtstart         time.Time   `json:"dtstart"`
+	Rrule           string      `json:"rrule"`
+	Timezone        string      `json:"timezone"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	ClassTypeName   string      `json:"class_type_name"`
+	DurationMinutes int32       `json:"duration_minutes"`
+	RoomName        string      `json:"room_name"`
+	Capacity        int32       `json:"capacity"`
+}
+
+// Schedules that may have occurrences before the given time, joined with what
+// the timetable needs to display them.
+func (q *Queries) GetScheduledClasses(ctx context.Context, before time.Time) ([]GetScheduledClassesRow, error) {
+	rows, err := q.db.Query(ctx, getScheduledClasses, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetScheduledClassesRow
+	for rows.Next() {
+		var i GetScheduledClassesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClassTypeID,
+			&i.RoomID,
+			&i.CoachID,
+			&i.Dtstart,
+			&i.Rrule,
+			&i.Timezone,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClassTypeName,
+			&i.DurationMinutes,
+			&i.RoomName,
+			&i.Capacity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionBookings = `-- name: GetSessionBookings :many
+SELECT bookings.id, bookings.session_id, bookings.user_id, bookings.status, bookings.promoted_at, bookings.cancelled_at, bookings.created_at, bookings.updated_at, bookings.checked_in_at, bookings.late_cancellation, users.email
+FROM bookings
+JOIN users ON users.id = bookings.user_id
+WHERE bookings.session_id = $1 AND bookings.status <> 'cancelled'
+ORDER BY bookings.status, bookings.created_at, bookings.id
+`
+
+type GetSessionBookingsRow struct {
+	ID               int32              `json:"id"`
+	SessionID        int32              `json:"session_id"`
+	UserID           int32              `json:"user_id"`
+	Status           string             `json:"status"`
+	PromotedAt       pgtype.Timestamptz `json:"promoted_at"`
+	CancelledAt      pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	CheckedInAt      pgtype.Timestamptz `json:"checked_in_at"`
+	LateCancellation bool               `json:"late_cancellation"`
+	Email            string             `json:"email"`
+}
+
+func (q *Queries) GetSessionBookings(ctx context.Context, sessionID int32) ([]GetSessionBookingsRow, error) {
+	rows, err := q.db.Query(ctx, getSessionBookings, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSessionBookingsRow
+	for rows.Next() {
+		var i GetSessionBookingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.UserID,
+			&i.Status,
+			&i.PromotedAt,
+			&i.CancelledAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CheckedInAt,
+			&i.LateCancellation,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubscriptionByID = `-- name: GetSubscriptionByID :one
+SELECT id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at FROM subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetSubscriptionByID(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscriptionByID, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSubscriptionForUpdate = `-- name: GetSubscriptionForUpdate :one
+SELECT id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at FROM subscriptions
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetSubscriptionForUpdate(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscriptionForUpdate, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSubscriptionsByUserID = `-- name: GetSubscriptionsByUserID :many
+SELECT id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at FROM subscriptions
+WHERE user_id = $1
+ORDER BY starts_at DESC
+`
+
+func (q *Queries) GetSubscriptionsByUserID(ctx context.Context, userID int32) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, getSubscriptionsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PlanID,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.RenewsAt,
+			&i.PausedAt,
+			&i.CancelledAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrainingSessionForUpdate = `-- name: GetTrainingSessionForUpdate :one
+SELECT id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at FROM training_sessions
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetTrainingSessionForUpdate(ctx context.Context, id int32) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, getTrainingSessionForUpdate, id)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTrainingSessionParticipants = `-- name: GetTrainingSessionParticipants :one
+SELECT members.email AS member_email, coaches.email AS coach_email, coach_profiles.timezone
+FROM training_sessions
+JOIN users members ON members.id = training_sessions.member_id
+JOIN users coaches ON coaches.id = training_sessions.coach_id
+JOIN coach_profiles ON coach_profiles.user_id = training_sessions.coach_id
+WHERE training_sessions.id = $1
+`
+
+type GetTrainingSessionParticipantsRow struct {
+	MemberEmail string `json:"member_email"`
+	CoachEmail  string `json:"coach_email"`
+	Timezone    string `json:"timezone"`
+}
+
+func (q *Queries) GetTrainingSessionParticipants(ctx context.Context, id int32) (GetTrainingSessionParticipantsRow, error) {
+	row := q.db.QueryRow(ctx, getTrainingSessionParticipants, id)
+	var i GetTrainingSessionParticipantsRow
+	err := row.Scan(&i.MemberEmail, &i.CoachEmail, &i.Timezone)
+	return i, err
+}
+
+const getTrainingSessionsByUserID = `-- name: GetTrainingSessionsByUserID :many
+SELECT id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at FROM training_sessions
+WHERE member_id = $1 OR coach_id = $1
+ORDER BY starts_at DESC
+`
+
+// Sessions the user takes as a member or gives as a coach.
+func (q *Queries) GetTrainingSessionsByUserID(ctx context.Context, userID int32) ([]TrainingSession, error) {
+	rows, err := q.db.Query(ctx, getTrainingSessionsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TrainingSession
+	for rows.Next() {
+		var i TrainingSession
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoachID,
+			&i.MemberID,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.Note,
+			&i.DeclineReason,
+			&i.ProposedStartsAt,
+			&i.ProposedEndsAt,
+			&i.ProposedBy,
+			&i.CancelledBy,
+			&i.ReminderSentAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url FROM users
+WHERE lower(email) = lower($1)
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Role,
+		&i.PhotoUrl,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Role,
+		&i.PhotoUrl,
+	)
+	return i, err
+}
+
+const getUserStatusTransitions = `-- name: GetUserStatusTransitions :many
+SELECT id, user_id, from_status, to_status, reason, created_at FROM user_status_transitions
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetUserStatusTransitions(ctx context.Context, userID int32) ([]UserStatusTransition, error) {
+	rows, err := q.db.Query(ctx, getUserStatusTransitions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserStatusTransition
+	for rows.Next() {
+		var i UserStatusTransition
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWaitlistPosition = `-- name: GetWaitlistPosition :one
+SELECT COUNT(*) FROM bookings
+WHERE session_id = $1 AND status = 'waitlisted'
+  AND (created_at, id) <= ($2::TIMESTAMPTZ, $3::INTEGER)
+`
+
+type GetWaitlistPositionParams struct {
+	SessionID int32     `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) GetWaitlistPosition(ctx context.Context, arg GetWaitlistPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getWaitlistPosition, arg.SessionID, arg.CreatedAt, arg.ID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getWeighInsBetween = `-- name: GetWeighInsBetween :many
+SELECT id, user_id, weight_kg, body_fat_percent, measured_at, source, notes, recorded_by, created_at FROM weigh_ins
+WHERE user_id = $1 AND measured_at >= $2 AND measured_at < $3
+ORDER BY measured_at
+`
+
+type GetWeighInsBetweenParams struct {
+	UserID   int32     `json:"user_id"`
+	FromTime time.Time `json:"from_time"`
+	ToTime   time.Time `json:"to_time"`
+}
+
+func (q *Queries) GetWeighInsBetween(ctx context.Context, arg GetWeighInsBetweenParams) ([]WeighIn, error) {
+	rows, err := q.db.Query(ctx, getWeighInsBetween, arg.UserID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WeighIn
+	for rows.Next() {
+		var i WeighIn
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.WeightKg,
+			&i.BodyFatPercent,
+			&i.MeasuredAt,
+			&i.Source,
+			&i.Notes,
+			&i.RecordedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWeightTarget = `-- name: GetWeightTarget :one
+SELECT user_id, target_kg, fight_date, coach_id, alerted_at, created_at, updated_at FROM weight_targets
+WHERE user_id = $1
+`
+
+func (q *Queries) GetWeightTarget(ctx context.Context, userID int32) (WeightTarget, error) {
+	row := q.db.QueryRow(ctx, getWeightTarget, userID)
+	var i WeightTarget
+	err := row.Scan(
+		&i.UserID,
+		&i.TargetKg,
+		&i.FightDate,
+		&i.CoachID,
+		&i.AlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const hasOverlappingFreeze = `-- name: HasOverlappingFreeze :one
+SELECT EXISTS (
+  SELECT 1 FROM membership_freezes
+  WHERE subscription_id = $1
+    AND status IN ('scheduled', 'active')
+    AND starts_at < $2
+    AND ends_at > $3
+)
+`
+
+type HasOverlappingFreezeParams struct {
+	SubscriptionID int32     `json:"subscription_id"`
+	EndsAt         time.Time `json:"ends_at"`
+	StartsAt       time.Time `json:"starts_at"`
 }
 
-func (q *Queries) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error) {
-	row := q.db.QueryRow(ctx, createEmailVerificationToken,
-		arg.Email,
-		arg.VerificationToken,
-		arg.HashedPasswordCacheKey,
-		arg.ExpiresAt,
+func (q *Queries) HasOverlappingFreeze(ctx context.Context, arg HasOverlappingFreezeParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasOverlappingFreeze, arg.SubscriptionID, arg.EndsAt, arg.StartsAt)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const hasUnlimitedMembership = `-- name: HasUnlimitedMembership :one
+SELECT EXISTS (
+  SELECT 1 FROM subscriptions
+  JOIN membership_plans ON membership_plans.id = subscriptions.plan_id
+  WHERE subscriptions.user_id = $1
+    AND subscriptions.status = 'active'
+    AND subscriptions.starts_at <= NOW()
+    AND subscriptions.ends_at > NOW()
+    AND membership_plans.class_allowance IS NULL
+)
+`
+
+func (q *Queries) HasUnlimitedMembership(ctx context.Context, userID int32) (bool, error) {
+	row := q.db.QueryRow(ctx, hasUnlimitedMembership, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const lockUserForUpdate = `-- name: LockUserForUpdate :one
+SELECT id FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+// Serializes writes to a user's credit ledger.
+func (q *Queries) LockUserForUpdate(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockUserForUpdate, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const markBookingAttended = `-- name: MarkBookingAttended :exec
+UPDATE bookings
+SET status = 'attended', checked_in_at = $3, updated_at = NOW()
+WHERE session_id = $1 AND user_id = $2 AND status = 'booked'
+`
+
+type MarkBookingAttendedParams struct {
+	SessionID   int32              `json:"session_id"`
+	UserID      int32              `json:"user_id"`
+	CheckedInAt pgtype.Timestamptz `json:"checked_in_at"`
+}
+
+func (q *Queries) MarkBookingAttended(ctx context.Context, arg MarkBookingAttendedParams) error {
+	_, err := q.db.Exec(ctx, markBookingAttended, arg.SessionID, arg.UserID, arg.CheckedInAt)
+	return err
+}
+
+const markNoShows = `-- name: MarkNoShows :many
+WITH no_shows AS (
+  UPDATE bookings
+  SET status = 'no_show', updated_at = NOW()
+  FROM class_sessions
+  WHERE class_sessions.id = bookings.session_id
+    AND bookings.status = 'booked'
+    AND class_sessions.cancelled_at IS NULL
+    AND class_sessions.ends_at < $1
+    AND NOT EXISTS (
+      SELECT 1 FROM attendances
+      WHERE attendances.session_id = bookings.session_id AND attendances.user_id = bookings.user_id
+    )
+  RETURNING bookings.id, bookings.user_id, bookings.session_id
+)
+SELECT no_shows.id, no_shows.user_id, users.email, class_sessions.starts_at,
+  class_types.name AS class_type_name, class_schedules.timezone,
+  class_types.no_show_penalty, class_types.penalty_fee_cents
+FROM no_shows
+JOIN users ON users.id = no_shows.user_id
+JOIN class_sessions ON class_sessions.id = no_shows.session_id
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+ORDER BY no_shows.user_id, class_sessions.starts_at
+`
+
+type MarkNoShowsRow struct {
+	ID              int32     `json:"id"`
+	UserID          int32     `json:"user_id"`
+	Email           string    `json:"email"`
+	StartsAt        time.Time `json:"starts_at"`
+	ClassTypeName   string    `json:"class_type_name"`
+	Timezone        string    `json:"timezone"`
+	NoShowPenalty   string    `json:"no_show_penalty"`
+	PenaltyFeeCents int32     `json:"penalty_fee_cents"`
+}
+
+// Marks bookings of sessions that ended before the cutoff without the member
+// checking in.
+func (q *Queries) MarkNoShows(ctx context.Context, cutoff time.Time) ([]MarkNoShowsRow, error) {
+	rows, err := q.db.Query(ctx, markNoShows, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MarkNoShowsRow
+	for rows.Next() {
+		var i MarkNoShowsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.StartsAt,
+			&i.ClassTypeName,
+			&i.Timezone,
+			&i.NoShowPenalty,
+			&i.PenaltyFeeCents,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveTrainingSession = `-- name: MoveTrainingSession :one
+UPDATE training_sessions
+SET starts_at = proposed_starts_at,
+    ends_at = proposed_ends_at,
+    proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    reminder_sent_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND proposed_starts_at IS NOT NULL
+RETURNING id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at
+`
+
+// Moves a session to its proposed time. A new reminder goes out for the new time.
+func (q *Queries) MoveTrainingSession(ctx context.Context, id int32) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, moveTrainingSession, id)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
-	var i EmailVerificationToken
+	return i, err
+}
+
+const pauseSubscription = `-- name: PauseSubscription :one
+UPDATE subscriptions
+SET status = 'paused', paused_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+func (q *Queries) PauseSubscription(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, pauseSubscription, id)
+	var i Subscription
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.VerificationToken,
-		&i.HashedPasswordCacheKey,
-		&i.TokenType,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
 		&i.CreatedAt,
-		&i.ExpiresAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (email, password, updated_at)
-VALUES ($1, $2, NOW())
-RETURNING id, email, password, created_at, updated_at
+const promoteFirstStaff = `-- name: PromoteFirstStaff :execrows
+UPDATE users
+SET role = 'staff', updated_at = NOW()
+WHERE lower(email) = lower($1) AND status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'staff')
 `
 
-type CreateUserParams struct {
-	Email    string `json:"email"`
-	Password []byte `json:"password"`
+// Makes the active user with the given email staff, unless there is staff
+// already.
+func (q *Queries) PromoteFirstStaff(ctx context.Context, email string) (int64, error) {
+	result, err := q.db.Exec(ctx, promoteFirstStaff, email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.Password)
-	var i User
+const promoteNextWaitlisted = `-- name: PromoteNextWaitlisted :one
+UPDATE bookings
+SET status = 'booked', promoted_at = NOW(), updated_at = NOW()
+WHERE bookings.id = (
+  SELECT waiting.id FROM bookings AS waiting
+  JOIN users ON users.id = waiting.user_id
+  WHERE waiting.session_id = $1 AND waiting.status = 'waitlisted'
+    AND users.status = 'active'
+    AND NOT EXISTS (
+      SELECT 1 FROM penalties
+      WHERE penalties.user_id = waiting.user_id AND penalties.kind = 'ban'
+        AND penalties.waived_at IS NULL AND penalties.banned_until > NOW()
+    )
+    AND NOT EXISTS (
+      SELECT 1 FROM membership_freezes
+      WHERE membership_freezes.user_id = waiting.user_id AND membership_freezes.status = 'active'
+    )
+  ORDER BY waiting.created_at, waiting.id
+  LIMIT 1
+)
+RETURNING id, session_id, user_id, status, promoted_at, cancelled_at, created_at, updated_at, checked_in_at, late_cancellation
+`
+
+// Gives the freed spot to whoever has been waiting the longest among the
+// members who could book it themselves. Banned, frozen and inactive members
+// stay on the waitlist.
+func (q *Queries) PromoteNextWaitlisted(ctx context.Context, sessionID int32) (Booking, error) {
+	row := q.db.QueryRow(ctx, promoteNextWaitlisted, sessionID)
+	var i Booking
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Password,
+		&i.SessionID,
+		&i.UserID,
+		&i.Status,
+		&i.PromotedAt,
+		&i.CancelledAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CheckedInAt,
+		&i.LateCancellation,
 	)
 	return i, err
 }
 
-const deleteEmailVerificationTokenByID = `-- name: DeleteEmailVerificationTokenByID :exec
-DELETE FROM email_verification_tokens
+const proposeTrainingSessionTime = `-- name: ProposeTrainingSessionTime :one
+UPDATE training_sessions
+SET proposed_starts_at = $2,
+    proposed_ends_at = $3,
+    proposed_by = $4,
+    updated_at = NOW()
 WHERE id = $1
+RETURNING id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at
 `
 
-func (q *Queries) DeleteEmailVerificationTokenByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteEmailVerificationTokenByID, id)
-	return err
+type ProposeTrainingSessionTimeParams struct {
+	ID               int32              `json:"id"`
+	ProposedStartsAt pgtype.Timestamptz `json:"proposed_starts_at"`
+	ProposedEndsAt   pgtype.Timestamptz `json:"proposed_ends_at"`
+	ProposedBy       pgtype.Int4        `json:"proposed_by"`
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users
+func (q *Queries) ProposeTrainingSessionTime(ctx context.Context, arg ProposeTrainingSessionTimeParams) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, proposeTrainingSessionTime,
+		arg.ID,
+		arg.ProposedStartsAt,
+		arg.ProposedEndsAt,
+		arg.ProposedBy,
+	)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const renewSubscription = `-- name: RenewSubscription :one
+UPDATE subscriptions
+SET status = 'active', ends_at = $2, renews_at = $3, updated_at = NOW()
+WHERE id = $1 AND status IN ('active', 'expired')
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+type RenewSubscriptionParams struct {
+	ID       int32              `json:"id"`
+	EndsAt   time.Time          `json:"ends_at"`
+	RenewsAt pgtype.Timestamptz `json:"renews_at"`
+}
+
+func (q *Queries) RenewSubscription(ctx context.Context, arg RenewSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, renewSubscription, arg.ID, arg.EndsAt, arg.RenewsAt)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const resumeSubscription = `-- name: ResumeSubscription :one
+UPDATE subscriptions
+SET status = 'active',
+    ends_at = ends_at + (NOW() - paused_at),
+    renews_at = renews_at + (NOW() - paused_at),
+    paused_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'paused'
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+// The time spent paused is added to the end and renewal dates.
+func (q *Queries) ResumeSubscription(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, resumeSubscription, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setMembershipFreezeStatus = `-- name: SetMembershipFreezeStatus :one
+UPDATE membership_freezes
+SET status = $2, ended_at = $3, updated_at = NOW()
 WHERE id = $1
+RETURNING id, subscription_id, user_id, status, starts_at, ends_at, ended_at, reason, requested_by, created_at, updated_at
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+type SetMembershipFreezeStatusParams struct {
+	ID      int32              `json:"id"`
+	Status  string             `json:"status"`
+	EndedAt pgtype.Timestamptz `json:"ended_at"`
 }
 
-const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, email, password, created_at, updated_at FROM users
+func (q *Queries) SetMembershipFreezeStatus(ctx context.Context, arg SetMembershipFreezeStatusParams) (MembershipFreeze, error) {
+	row := q.db.QueryRow(ctx, setMembershipFreezeStatus, arg.ID, arg.Status, arg.EndedAt)
+	var i MembershipFreeze
+	err := row.Scan(
+		&i.ID,
+		&i.SubscriptionID,
+		&i.UserID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.EndedAt,
+		&i.Reason,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setTrainingSessionStatus = `-- name: SetTrainingSessionStatus :one
+UPDATE training_sessions
+SET status = $2,
+    decline_reason = $3,
+    cancelled_by = $4,
+    proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, coach_id, member_id, status, starts_at, ends_at, note, decline_reason, proposed_starts_at, proposed_ends_at, proposed_by, cancelled_by, reminder_sent_at, created_at, updated_at
 `
 
-func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
-	rows, err := q.db.Query(ctx, getAllUsers)
+type SetTrainingSessionStatusParams struct {
+	ID            int32       `json:"id"`
+	Status        string      `json:"status"`
+	DeclineReason string      `json:"decline_reason"`
+	CancelledBy   pgtype.Int4 `json:"cancelled_by"`
+}
+
+func (q *Queries) SetTrainingSessionStatus(ctx context.Context, arg SetTrainingSessionStatusParams) (TrainingSession, error) {
+	row := q.db.QueryRow(ctx, setTrainingSessionStatus,
+		arg.ID,
+		arg.Status,
+		arg.DeclineReason,
+		arg.CancelledBy,
+	)
+	var i TrainingSession
+	err := row.Scan(
+		&i.ID,
+		&i.CoachID,
+		&i.MemberID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.Note,
+		&i.DeclineReason,
+		&i.ProposedStartsAt,
+		&i.ProposedEndsAt,
+		&i.ProposedBy,
+		&i.CancelledBy,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const shiftSubscriptionEnd = `-- name: ShiftSubscriptionEnd :one
+UPDATE subscriptions
+SET ends_at = ends_at + $2::INTERVAL,
+    renews_at = renews_at + $2::INTERVAL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+type ShiftSubscriptionEndParams struct {
+	ID    int32           `json:"id"`
+	Shift pgtype.Interval `json:"shift"`
+}
+
+// Moves the end and renewal dates of a subscription by a freeze's length.
+func (q *Queries) ShiftSubscriptionEnd(ctx context.Context, arg ShiftSubscriptionEndParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, shiftSubscriptionEnd, arg.ID, arg.Shift)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PlanID,
+		&i.Status,
+		&i.StartsAt,
+		&i.EndsAt,
+		&i.RenewsAt,
+		&i.PausedAt,
+		&i.CancelledAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const startDueFreezes = `-- name: StartDueFreezes :many
+WITH started AS (
+  UPDATE membership_freezes
+  SET status = 'active', updated_at = NOW()
+  FROM subscriptions
+  WHERE subscriptions.id = membership_freezes.subscription_id
+    AND subscriptions.status = 'active'
+    AND membership_freezes.status = 'scheduled'
+    AND membership_freezes.starts_at <= NOW()
+  RETURNING membership_freezes.subscription_id
+)
+UPDATE subscriptions
+SET status = 'frozen', updated_at = NOW()
+WHERE id IN (SELECT subscription_id FROM started)
+RETURNING id, user_id, plan_id, status, starts_at, ends_at, renews_at, paused_at, cancelled_at, created_by, created_at, updated_at
+`
+
+// Starts the scheduled freezes of active subscriptions and freezes the
+// subscriptions.
+func (q *Queries) StartDueFreezes(ctx context.Context) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, startDueFreezes)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []Subscription
 	for rows.Next() {
-		var i User
+		var i Subscription
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Password,
+			&i.UserID,
+			&i.PlanID,
+			&i.Status,
+			&i.StartsAt,
+			&i.EndsAt,
+			&i.RenewsAt,
+			&i.PausedAt,
+			&i.CancelledAt,
+			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -119,33 +4009,77 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
-const getEmailVerificationTokenByEmail = `-- name: GetEmailVerificationTokenByEmail :one
-SELECT id, email, verification_token, hashed_password_cache_key, token_type, created_at, expires_at FROM email_verification_tokens
-WHERE email = $1
+const unfreezeSubscription = `-- name: UnfreezeSubscription :exec
+UPDATE subscriptions
+SET status = 'active', updated_at = NOW()
+WHERE id = $1 AND status = 'frozen'
 `
 
-func (q *Queries) GetEmailVerificationTokenByEmail(ctx context.Context, email string) (EmailVerificationToken, error) {
-	row := q.db.QueryRow(ctx, getEmailVerificationTokenByEmail, email)
-	var i EmailVerificationToken
+func (q *Queries) UnfreezeSubscription(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, unfreezeSubscription, id)
+	return err
+}
+
+const updateClassTypePenaltyPolicy = `-- name: UpdateClassTypePenaltyPolicy :one
+UPDATE class_types
+SET cancellation_window_minutes = $2, late_cancel_penalty = $3, no_show_penalty = $4, penalty_fee_cents = $5
+WHERE id = $1
+RETURNING id, name, description, duration_minutes, created_at, cancellation_window_minutes, late_cancel_penalty, no_show_penalty, penalty_fee_cents
+`
+
+type UpdateClassTypePenaltyPolicyParams struct {
+	ID                        int32  `json:"id"`
+	CancellationWindowMinutes int32  `json:"cancellation_window_minutes"`
+	LateCancelPenalty         string `json:"late_cancel_penalty"`
+	NoShowPenalty             string `json:"no_show_penalty"`
+	PenaltyFeeCents           int32  `json:"penalty_fee_cents"`
+}
+
+func (q *Queries) UpdateClassTypePenaltyPolicy(ctx context.Context, arg UpdateClassTypePenaltyPolicyParams) (ClassType, error) {
+	row := q.db.QueryRow(ctx, updateClassTypePenaltyPolicy,
+		arg.ID,
+		arg.CancellationWindowMinutes,
+		arg.LateCancelPenalty,
+		arg.NoShowPenalty,
+		arg.PenaltyFeeCents,
+	)
+	var i ClassType
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.VerificationToken,
-		&i.HashedPasswordCacheKey,
-		&i.TokenType,
+		&i.Name,
+		&i.Description,
+		&i.DurationMinutes,
 		&i.CreatedAt,
-		&i.ExpiresAt,
+		&i.CancellationWindowMinutes,
+		&i.LateCancelPenalty,
+		&i.NoShowPenalty,
+		&i.PenaltyFeeCents,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password, created_at, updated_at FROM users
-WHERE email = $1
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $1, updated_at = NOW()
+WHERE users.id = $2
+  AND (
+    users.role <> 'staff' OR $1::VARCHAR = 'staff' OR EXISTS (
+      SELECT 1 FROM users AS other
+      WHERE other.role = 'staff' AND other.status = 'active' AND other.id <> $2
+    )
+  )
+RETURNING id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
+type UpdateUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int32  `json:"id"`
+}
+
+// Staff can't be demoted while they are the only active staff, so someone is
+// always left to manage accounts.
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Role, arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -153,24 +4087,251 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Role,
+		&i.PhotoUrl,
 	)
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT id, email, password, created_at, updated_at FROM users
-WHERE id = $1
+const updateUserStatus = `-- name: UpdateUserStatus :one
+WITH updated AS (
+  UPDATE users
+  SET status = $1,
+      status_reason = $2,
+      status_changed_at = NOW(),
+      updated_at = NOW()
+  WHERE users.id = $3 AND users.status = $4
+  RETURNING id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url
+), transition AS (
+  INSERT INTO user_status_transitions (user_id, from_status, to_status, reason)
+  SELECT updated.id, $4::VARCHAR, updated.status, updated.status_reason
+  FROM updated
+)
+SELECT id, email, password, created_at, updated_at, status, status_reason, status_changed_at, role, photo_url FROM updated
 `
 
-func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByID, id)
-	var i User
+type UpdateUserStatusParams struct {
+	ToStatus   string      `json:"to_status"`
+	Reason     pgtype.Text `json:"reason"`
+	ID         int32       `json:"id"`
+	FromStatus string      `json:"from_status"`
+}
+
+type UpdateUserStatusRow struct {
+	ID              int32       `json:"id"`
+	Email           string      `json:"email"`
+	Password        []byte      `json:"password"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	Status          string      `json:"status"`
+	StatusReason    pgtype.Text `json:"status_reason"`
+	StatusChangedAt time.Time   `json:"status_changed_at"`
+	Role            string      `json:"role"`
+	PhotoUrl        pgtype.Text `json:"photo_url"`
+}
+
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (UpdateUserStatusRow, error) {
+	row := q.db.QueryRow(ctx, updateUserStatus,
+		arg.ToStatus,
+		arg.Reason,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i UpdateUserStatusRow
 	err := row.Scan(
 		&i.ID,
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.Role,
+		&i.PhotoUrl,
+	)
+	return i, err
+}
+
+const upsertCoachProfile = `-- name: UpsertCoachProfile :one
+INSERT INTO coach_profiles (user_id, bio, specialties, certifications, timezone)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET bio = EXCLUDED.bio,
+    specialties = EXCLUDED.specialties,
+    certifications = EXCLUDED.certifications,
+    timezone = EXCLUDED.timezone,
+    updated_at = NOW()
+RETURNING user_id, bio, specialties, certifications, timezone, created_at, updated_at
+`
+
+type UpsertCoachProfileParams struct {
+	UserID         int32    `json:"user_id"`
+	Bio            string   `json:"bio"`
+	Specialties    []string `json:"specialties"`
+	Certifications []string `json:"certifications"`
+	Timezone       string   `json:"timezone"`
+}
+
+func (q *Queries) UpsertCoachProfile(ctx context.Context, arg UpsertCoachProfileParams) (CoachProfile, error) {
+	row := q.db.QueryRow(ctx, upsertCoachProfile,
+		arg.UserID,
+		arg.Bio,
+		arg.Specialties,
+		arg.Certifications,
+		arg.Timezone,
+	)
+	var i CoachProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.Bio,
+		&i.Specialties,
+		&i.Certifications,
+		&i.Timezone,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertFighterProfile = `-- name: UpsertFighterProfile :one
+INSERT INTO fighter_profiles (
+  user_id, display_name, weight_class, height_cm, reach_cm, stance, level,
+  experience, public, show_measurements, show_bouts
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (user_id) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    weight_class = EXCLUDED.weight_class,
+    height_cm = EXCLUDED.height_cm,
+    reach_cm = EXCLUDED.reach_cm,
+    stance = EXCLUDED.stance,
+    level = EXCLUDED.level,
+    experience = EXCLUDED.experience,
+    public = EXCLUDED.public,
+    show_measurements = EXCLUDED.show_measurements,
+    show_bouts = EXCLUDED.show_bouts,
+    updated_at = NOW()
+RETURNING user_id, display_name, weight_class, height_cm, reach_cm, stance, level, public, show_measurements, show_bouts, created_at, updated_at, experience
+`
+
+type UpsertFighterProfileParams struct {
+	UserID           int32       `json:"user_id"`
+	DisplayName      string      `json:"display_name"`
+	WeightClass      string      `json:"weight_class"`
+	HeightCm         pgtype.Int4 `json:"height_cm"`
+	ReachCm          pgtype.Int4 `json:"reach_cm"`
+	Stance           string      `json:"stance"`
+	Level            string      `json:"level"`
+	Experience       string      `json:"experience"`
+	Public           bool        `json:"public"`
+	ShowMeasurements bool        `json:"show_measurements"`
+	ShowBouts        bool        `json:"show_bouts"`
+}
+
+func (q *Queries) UpsertFighterProfile(ctx context.Context, arg UpsertFighterProfileParams) (FighterProfile, error) {
+	row := q.db.QueryRow(ctx, upsertFighterProfile,
+		arg.UserID,
+		arg.DisplayName,
+		arg.WeightClass,
+		arg.HeightCm,
+		arg.ReachCm,
+		arg.Stance,
+		arg.Level,
+		arg.Experience,
+		arg.Public,
+		arg.ShowMeasurements,
+		arg.ShowBouts,
+	)
+	var i FighterProfile
+	err := row.Scan(
+		&i.UserID,
+		&i.DisplayName,
+		&i.WeightClass,
+		&i.HeightCm,
+		&i.ReachCm,
+		&i.Stance,
+		&i.Level,
+		&i.Public,
+		&i.ShowMeasurements,
+		&i.ShowBouts,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Experience,
+	)
+	return i, err
+}
+
+const upsertWeightTarget = `-- name: UpsertWeightTarget :one
+INSERT INTO weight_targets (user_id, target_kg, fight_date, coach_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET target_kg = EXCLUDED.target_kg,
+    fight_date = EXCLUDED.fight_date,
+    coach_id = EXCLUDED.coach_id,
+    alerted_at = NULL,
+    updated_at = NOW()
+RETURNING user_id, target_kg, fight_date, coach_id, alerted_at, created_at, updated_at
+`
+
+type UpsertWeightTargetParams struct {
+	UserID    int32       `json:"user_id"`
+	TargetKg  float64     `json:"target_kg"`
+	FightDate time.Time   `json:"fight_date"`
+	CoachID   pgtype.Int4 `json:"coach_id"`
+}
+
+// A new or changed target can trigger a new alert.
+func (q *Queries) UpsertWeightTarget(ctx context.Context, arg UpsertWeightTargetParams) (WeightTarget, error) {
+	row := q.db.QueryRow(ctx, upsertWeightTarget,
+		arg.UserID,
+		arg.TargetKg,
+		arg.FightDate,
+		arg.CoachID,
+	)
+	var i WeightTarget
+	err := row.Scan(
+		&i.UserID,
+		&i.TargetKg,
+		&i.FightDate,
+		&i.CoachID,
+		&i.AlertedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const waivePenalty = `-- name: WaivePenalty :one
+UPDATE penalties
+SET waived_at = NOW(), waived_by = $2
+WHERE id = $1 AND waived_at IS NULL
+RETURNING id, user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until, waived_at, waived_by, created_at
+`
+
+type WaivePenaltyParams struct {
+	ID       int32       `json:"id"`
+	WaivedBy pgtype.Int4 `json:"waived_by"`
+}
+
+func (q *Queries) WaivePenalty(ctx context.Context, arg WaivePenaltyParams) (Penalty, error) {
+	row := q.db.QueryRow(ctx, waivePenalty, arg.ID, arg.WaivedBy)
+	var i Penalty
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.BookingID,
+		&i.Reason,
+		&i.Kind,
+		&i.FeeCents,
+		&i.CreditEntryID,
+		&i.BannedUntil,
+		&i.WaivedAt,
+		&i.WaivedBy,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,277 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/grez-lucas/boxer66-service/health"
+	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/worker"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestRoutesMatchOpenAPISpec sends requests through NewRouter and checks that
+// each status and response body is the one openapi.yaml documents.
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	t.Setenv("JWT_SECRET", "contract-test-secret")
+
+	now := time.Date(2026, 1, 5, 18, 0, 0, 0, time.UTC)
+	password, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	member := []any{
+		int32(7), "member@example.com", password, now, now, "active", pgtype.Text{}, now, "member", pgtype.Text{},
+	}
+	staff := []any{
+		int32(1), "staff@example.com", password, now, now, "active", pgtype.Text{}, now, "staff", pgtype.Text{},
+	}
+	suspended := []any{
+		int32(7), "member@example.com", password, now, now, "suspended", pgtype.Text{String: "unpaid", Valid: true},
+		now, "member", pgtype.Text{},
+	}
+	coach := []any{
+		int32(7), "member@example.com", password, now, now, "active", pgtype.Text{}, now, "coach", pgtype.Text{},
+	}
+	db := &fakeDB{rows: map[string][][]any{
+		"GetUserByID":      {member, staff},
+		"GetUserByEmail":   {member},
+		"GetAllUsers":      {staff, member},
+		"UpdateUserStatus": {suspended},
+		"UpdateUserRole":   {coach},
+		"GetRooms":         {{int32(1), "Ring", int32(12), now}},
+		"GetClassTypes": {
+			{int32(1), "Boxing", "Pads and technique", int32(60), now, int32(120), "credit", "fee", int32(1500)},
+		},
+		"GetPenaltiesByUserID": {
+			{
+				int32(3), int32(7), int32(11), "no_show", "ban", int32(0), pgtype.Int4{},
+				pgtype.Timestamptz{Time: now.AddDate(0, 0, 7), Valid: true}, pgtype.Timestamptz{}, pgtype.Int4{}, now,
+			},
+		},
+	}}
+
+	dependencyDown := false
+	checker := health.NewChecker(time.Second, 0, health.Check{
+		Name: "database",
+		Run: func(context.Context) error {
+			if dependencyDown {
+				return errNoDatabase
+			}
+			return nil
+		},
+	})
+
+	handler := NewRouter(
+		&config.Config{},
+		db,
+		repository.New(db),
+		smtp.NewSMTPService(config.SMTPConfig{}),
+		worker.NewGroup(),
+		checker,
+	)
+	specRouter := loadSpec(t, handler)
+
+	memberToken, err := middleware.CreateJWT(&repository.User{ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staffToken, err := middleware.CreateJWT(&repository.User{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		token          string
+		dependencyDown bool
+		wantStatus     int
+	}{
+		{name: "liveness", method: http.MethodGet, path: "/healthz", wantStatus: http.StatusOK},
+		{name: "liveness under the api prefix", method: http.MethodGet, path: "/api/healthz", wantStatus: http.StatusOK},
+		{name: "ready", method: http.MethodGet, path: "/readyz", wantStatus: http.StatusOK},
+		{
+			name:           "not ready",
+			method:         http.MethodGet,
+			path:           "/readyz",
+			dependencyDown: true,
+			wantStatus:     http.StatusServiceUnavailable,
+		},
+		{name: "version", method: http.MethodGet, path: "/version", wantStatus: http.StatusOK},
+		{name: "empty schedule", method: http.MethodGet, path: "/schedule", wantStatus: http.StatusOK},
+		{name: "rooms", method: http.MethodGet, path: "/rooms", wantStatus: http.StatusOK},
+		{name: "class types", method: http.MethodGet, path: "/api/class-types", wantStatus: http.StatusOK},
+		{name: "no membership plans", method: http.MethodGet, path: "/membership-plans", wantStatus: http.StatusOK},
+		{name: "no coaches", method: http.MethodGet, path: "/coaches", wantStatus: http.StatusOK},
+		{name: "unknown coach", method: http.MethodGet, path: "/coaches/9", wantStatus: http.StatusNotFound},
+		{name: "invalid coach id", method: http.MethodGet, path: "/coaches/abc", wantStatus: http.StatusBadRequest},
+		{
+			name:       "login",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email":"Member@example.com","password":"correct horse"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "login with a wrong password",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email":"member@example.com","password":"wrong horse"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{name: "login with malformed JSON", method: http.MethodPost, path: "/login", body: `{"email":`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "register with invalid fields",
+			method:     http.MethodPost,
+			path:       "/register",
+			body:       `{"email":"not-an-email","password":"short"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{name: "penalties without a token", method: http.MethodGet, path: "/me/penalties", wantStatus: http.StatusUnauthorized},
+		{name: "own penalties", method: http.MethodGet, path: "/me/penalties", token: memberToken, wantStatus: http.StatusOK},
+		{
+			name:       "staff route as a member",
+			method:     http.MethodGet,
+			path:       "/users/7/penalties",
+			token:      memberToken,
+			wantStatus: http.StatusForbidden,
+		},
+		{name: "users as a member", method: http.MethodGet, path: "/users", token: memberToken, wantStatus: http.StatusForbidden},
+		{name: "users as staff", method: http.MethodGet, path: "/users", token: staffToken, wantStatus: http.StatusOK},
+		{
+			name:       "suspend a member",
+			method:     http.MethodPatch,
+			path:       "/users/7/status",
+			body:       `{"status":"suspended","reason":"unpaid"}`,
+			token:      staffToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown status",
+			method:     http.MethodPatch,
+			path:       "/users/7/status",
+			body:       `{"status":"gone"}`,
+			token:      staffToken,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "status of an unknown user",
+			method:     http.MethodPatch,
+			path:       "/users/9/status",
+			body:       `{"status":"active"}`,
+			token:      staffToken,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "status change that isn't allowed",
+			method:     http.MethodPatch,
+			path:       "/users/1/status",
+			body:       `{"status":"pending"}`,
+			token:      staffToken,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "make a member coach",
+			method:     http.MethodPatch,
+			path:       "/users/7/role",
+			body:       `{"role":"coach"}`,
+			token:      staffToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown role",
+			method:     http.MethodPatch,
+			path:       "/users/7/role",
+			body:       `{"role":"owner"}`,
+			token:      staffToken,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "role change as a member",
+			method:     http.MethodPatch,
+			path:       "/users/1/role",
+			body:       `{"role":"member"}`,
+			token:      memberToken,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dependencyDown = tt.dependencyDown
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.token != "" {
+				req.Header.Set("jwt-token", tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			route, pathParams, err := specRouter.FindRoute(req)
+			if err != nil {
+				t.Fatalf("%s %s is not documented: %v", tt.method, tt.path, err)
+			}
+			err = openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status: rec.Code,
+				Header: rec.Header(),
+				Body:   io.NopCloser(rec.Body),
+				Options: &openapi3filter.Options{
+					IncludeResponseStatus: true,
+				},
+			})
+			if err != nil {
+				t.Errorf("response doesn't match the spec: %v", err)
+			}
+		})
+	}
+}
+
+// loadSpec fetches the spec the router serves, so the test checks the
+// document clients actually get.
+func loadSpec(t *testing.T, handler http.Handler) routers.Router {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("failed to fetch the spec: status %d", rec.Code)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("failed to load the spec: %v", err)
+	}
+	specRouter, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("failed to route the spec: %v", err)
+	}
+	return specRouter
+}
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var errNoDatabase = errors.New("the router tests have no database")
+
+// fakeDB answers sqlc queries by name with canned rows, so requests go
+// through the real handlers and services without a database. Queries without
+// rows find nothing and writes fail.
+type fakeDB struct {
+	rows map[string][][]any
+	// tracer is called around every query like pgx calls the tracer of a
+	// connection
+	tracer pgx.QueryTracer
+}
+
+func (db *fakeDB) Exec(ctx context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	db.trace(ctx, sql, errNoDatabase)
+	return pgconn.CommandTag{}, errNoDatabase
+}
+
+func (db *fakeDB) Query(ctx context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	db.trace(ctx, sql, nil)
+	return &fakeRows{rows: db.rows[queryName(sql)]}, nil
+}
+
+// QueryRow returns the first canned row. A query by ID, whose first argument
+// is an int32, only finds the row with that ID in its first column.
+func (db *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.trace(ctx, sql, nil)
+	for _, row := range db.rows[queryName(sql)] {
+		if id, ok := firstArg(args).(int32); ok && row[0] != id {
+			continue
+		}
+		return fakeRow{values: row}
+	}
+	return fakeRow{err: pgx.ErrNoRows}
+}
+
+func (db *fakeDB) trace(ctx context.Context, sql string, err error) {
+	if db.tracer == nil {
+		return
+	}
+	ctx = db.tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+	db.tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+}
+
+func firstArg(args []any) any {
+	if len(args) == 0 {
+		return nil
+	}
+	return args[0]
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	return nil, errNoDatabase
+}
+
+// queryName reads the name sqlc puts on the first line of every query.
+func queryName(sql string) string {
+	line, _, _ := strings.Cut(sql, "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return ""
+	}
+	return fields[2]
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	return scan(r.values, dest)
+}
+
+type fakeRows struct {
+	rows    [][]any
+	current int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	if r.current >= len(r.rows) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	return scan(r.rows[r.current-1], dest)
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	return r.rows[r.current-1], nil
+}
+
+// scan copies values into dest. Values must have the exact type of the
+// column's field in the sqlc model.
+func scan(values []any, dest []any) error {
+	if len(values) != len(dest) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(dest))
+	}
+	for i, value := range values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
@@ -1,28 +1,229 @@
 package router
 
 import (
-	"context"
+	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/grez-lucas/boxer66-service/attendance"
+	"github.com/grez-lucas/boxer66-service/bookings"
+	"github.com/grez-lucas/boxer66-service/checkins"
+	"github.com/grez-lucas/boxer66-service/coaches"
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/fighters"
+	"github.com/grez-lucas/boxer66-service/health"
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
 	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/openapi"
 	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/worker"
+	"github.com/grez-lucas/boxer66-service/legal"
+	"github.com/grez-lucas/boxer66-service/memberships"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/penalties"
+	"github.com/grez-lucas/boxer66-service/schedule"
 	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/grez-lucas/boxer66-service/sparring"
 	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/grez-lucas/boxer66-service/weighins"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func NewRouter(ctx context.Context, cfg *config.Config, queries *repository.Queries) http.Handler {
+// apiPrefixPattern serves every route again under /api.
+const apiPrefixPattern = "/api/"
+
+// NewRouter wires services and handlers. Background jobs are added to workers
+// and only run once the group is started.
+func NewRouter(
+	cfg *config.Config,
+	db repository.TxBeginner,
+	queries *repository.Queries,
+	smtpService *smtp.SMTPService,
+	workers *worker.Group,
+	checker *health.Checker,
+) http.Handler {
 	// Initialize services and handlers
-	uService := users.NewUserService(ctx, queries)
-	smtpService := smtp.NewSMTPService(cfg.SMTPConfig)
+	uService := users.NewUserService(queries)
 	uHandlers := users.NewUserHandlers(uService, smtpService)
+	lService := legal.NewLegalService(queries)
+	lHandlers := legal.NewLegalHandlers(lService)
+	mService := memberships.NewMembershipService(db, queries)
+	mHandlers := memberships.NewMembershipHandlers(mService)
+	sService := schedule.NewScheduleService(queries)
+	sHandlers := schedule.NewScheduleHandlers(sService)
+	bService := bookings.NewBookingService(db, queries)
+	bHandlers := bookings.NewBookingHandlers(bService, smtpService)
+	cService := checkins.NewCheckinService(db, queries, cfg.CheckinSecret)
+	cHandlers := checkins.NewCheckinHandlers(cService)
+	aService := attendance.NewAttendanceService(db, queries)
+	aHandlers := attendance.NewAttendanceHandlers(aService)
+	crService := credits.NewCreditService(db, queries)
+	crHandlers := credits.NewCreditHandlers(crService)
+	pService := penalties.NewPenaltyService(db, queries, penalties.BanPolicy{
+		Threshold: cfg.NoShowConfig.BanThreshold,
+		Window:    time.Duration(cfg.NoShowConfig.BanWindowDays) * 24 * time.Hour,
+		Duration:  time.Duration(cfg.NoShowConfig.BanDays) * 24 * time.Hour,
+	})
+	pHandlers := penalties.NewPenaltyHandlers(pService)
+	coService := coaches.NewCoachService(db, queries)
+	coHandlers := coaches.NewCoachHandlers(coService, smtpService)
+	fService := fighters.NewFighterService(queries)
+	fHandlers := fighters.NewFighterHandlers(fService)
+	wService := weighins.NewWeighInService(queries, float64(cfg.WeightCutConfig.SafeCutPercent))
+	wHandlers := weighins.NewWeighInHandlers(wService, smtpService)
+	spService := sparring.NewSparringService(queries)
+	spHandlers := sparring.NewSparringHandlers(spService)
+	hHandlers := health.NewHealthHandlers(checker)
+
+	workers.Add("mark no-shows", 5*time.Minute, penalties.NewNoShowJob(pService, smtpService).Run)
+	workers.Add("apply membership freezes", 5*time.Minute, mService.ApplyFreezes)
+	workers.Add("expire subscriptions", 5*time.Minute, mService.ExpireSubscriptions)
+	workers.Add("send training session reminders", 5*time.Minute, coaches.NewReminderJob(coService, smtpService).Run)
+	workers.Add("create class sessions", time.Hour, sService.CreateSessions)
+
+	// authenticated only lets active accounts through, protected additionally
+	// requires the current legal documents to have been accepted.
+	authenticated := middleware.Auth(uService.CheckAccountStatus)
+	protected := middleware.Auth(uService.CheckAccountStatus, lService.RequireAcceptance, mService.FlagFrozen)
+	coachOnly := middleware.Auth(uService.CheckAccountStatus, uService.RequireRole(users.RoleCoach))
+	staffOnly := middleware.Auth(uService.CheckAccountStatus, uService.RequireRole(users.RoleStaff))
+	// bookable additionally keeps frozen members and members banned for
+	// no-shows from booking
+	bookable := middleware.Auth(
+		uService.CheckAccountStatus,
+		lService.RequireAcceptance,
+		mService.FlagFrozen,
+		mService.RejectFrozen,
+		pService.RejectBanned,
+	)
+
+	// handle writes the errors handlers return as problem details
+	handle := apperror.Handler
 
 	router := http.NewServeMux()
 
-	router.HandleFunc("GET /users", uHandlers.GetUsers)
-	router.HandleFunc("POST /login", uHandlers.Login)
-	router.HandleFunc("POST /register", uHandlers.Register)
-	router.HandleFunc("POST /verify-email", uHandlers.VerifyEmail)
+	router.HandleFunc("GET /healthz", handle(hHandlers.Healthz))
+	router.HandleFunc("GET /readyz", handle(hHandlers.Readyz))
+	router.HandleFunc("GET /version", handle(hHandlers.Version))
+	router.Handle("GET /metrics", promhttp.Handler())
+
+	router.HandleFunc("GET /openapi.yaml", openapi.Spec)
+	router.HandleFunc("GET /docs", openapi.Docs)
+
+	router.HandleFunc("GET /users", staffOnly(handle(uHandlers.GetUsers)))
+	router.HandleFunc("POST /login", handle(uHandlers.Login))
+	router.HandleFunc("POST /register", handle(uHandlers.Register))
+	router.HandleFunc("POST /verify-email", handle(uHandlers.VerifyEmail))
+	router.HandleFunc("PATCH /users/{id}/status", staffOnly(handle(uHandlers.ChangeStatus)))
+	router.HandleFunc("PATCH /users/{id}/role", staffOnly(handle(uHandlers.ChangeRole)))
+
+	router.HandleFunc("GET /legal/documents", handle(lHandlers.GetCurrentDocuments))
+	router.HandleFunc("GET /legal/outstanding", authenticated(handle(lHandlers.GetOutstandingDocuments)))
+	router.HandleFunc("POST /legal/acceptances", authenticated(handle(lHandlers.AcceptDocuments)))
+
+	router.HandleFunc("GET /membership-plans", handle(mHandlers.GetPlans))
+	router.HandleFunc("POST /membership-plans", staffOnly(handle(mHandlers.CreatePlan)))
+	router.HandleFunc("GET /users/{id}/subscriptions", staffOnly(handle(mHandlers.GetUserSubscriptions)))
+	router.HandleFunc("POST /users/{id}/subscriptions", staffOnly(handle(mHandlers.AssignSubscription)))
+	router.HandleFunc("POST /subscriptions/{id}/pause", staffOnly(handle(mHandlers.PauseSubscription)))
+	router.HandleFunc("POST /subscriptions/{id}/resume", staffOnly(handle(mHandlers.ResumeSubscription)))
+	router.HandleFunc("POST /subscriptions/{id}/cancel", staffOnly(handle(mHandlers.CancelSubscription)))
+	router.HandleFunc("POST /subscriptions/{id}/renew", staffOnly(handle(mHandlers.RenewSubscription)))
+	router.HandleFunc("GET /me/freezes", protected(handle(mHandlers.GetMyFreezes)))
+	router.HandleFunc("POST /me/freezes", protected(handle(mHandlers.RequestMyFreeze)))
+	router.HandleFunc("DELETE /me/freezes/{id}", protected(handle(mHandlers.EndMyFreeze)))
+	router.HandleFunc("GET /users/{id}/freezes", staffOnly(handle(mHandlers.GetUserFreezes)))
+	router.HandleFunc("POST /users/{id}/freezes", staffOnly(handle(mHandlers.RequestUserFreeze)))
+
+	router.HandleFunc("GET /schedule", handle(sHandlers.GetSchedule))
+	router.HandleFunc("GET /class-types", handle(sHandlers.GetClassTypes))
+	router.HandleFunc("POST /class-types", staffOnly(handle(sHandlers.CreateClassType)))
+	router.HandleFunc("PUT /class-types/{id}/penalty-policy", staffOnly(handle(sHandlers.UpdatePenaltyPolicy)))
+	router.HandleFunc("GET /rooms", handle(sHandlers.GetRooms))
+	router.HandleFunc("POST /rooms", staffOnly(handle(sHandlers.CreateRoom)))
+	router.HandleFunc("POST /schedules", staffOnly(handle(sHandlers.CreateSchedule)))
+	router.HandleFunc("POST /schedules/{id}/exceptions", staffOnly(handle(sHandlers.CancelOccurrence)))
+	router.HandleFunc("POST /closures", staffOnly(handle(sHandlers.CreateClosure)))
+
+	router.HandleFunc("GET /sessions/{id}/bookings", coachOnly(handle(bHandlers.GetSessionBookings)))
+	router.HandleFunc("POST /sessions/{id}/bookings", bookable(handle(bHandlers.Book)))
+	router.HandleFunc("DELETE /sessions/{id}/bookings", protected(handle(bHandlers.CancelBooking)))
+
+	router.HandleFunc("GET /me/checkin-code", protected(handle(cHandlers.GetCheckinCode)))
+	router.HandleFunc("POST /checkins", staffOnly(handle(cHandlers.CheckIn)))
+
+	router.HandleFunc("GET /me/attendance", protected(handle(aHandlers.GetMyAttendance)))
+	router.HandleFunc("GET /users/{id}/attendance", coachOnly(handle(aHandlers.GetUserAttendance)))
+	router.HandleFunc("POST /users/{id}/attendance", staffOnly(handle(aHandlers.RecordAttendance)))
+
+	router.HandleFunc("GET /me/credits", protected(handle(crHandlers.GetMyCredits)))
+	router.HandleFunc("GET /users/{id}/credits", staffOnly(handle(crHandlers.GetUserCredits)))
+	router.HandleFunc("POST /users/{id}/credits", staffOnly(handle(crHandlers.AddCredits)))
+	router.HandleFunc("POST /credits/{id}/refund", staffOnly(handle(crHandlers.Refund)))
+
+	router.HandleFunc("GET /me/penalties", authenticated(handle(pHandlers.GetMyPenalties)))
+	router.HandleFunc("GET /users/{id}/penalties", staffOnly(handle(pHandlers.GetUserPenalties)))
+	router.HandleFunc("POST /penalties/{id}/waive", staffOnly(handle(pHandlers.WaivePenalty)))
+
+	router.HandleFunc("GET /coaches", handle(coHandlers.GetCoaches))
+	router.HandleFunc("GET /coaches/{id}", handle(coHandlers.GetCoach))
+	router.HandleFunc("GET /coaches/{id}/availability", handle(coHandlers.GetAvailability))
+	router.HandleFunc("PUT /me/coach-profile", coachOnly(handle(coHandlers.UpdateMyProfile)))
+	router.HandleFunc("GET /me/availability", coachOnly(handle(coHandlers.GetMyWeeklyAvailability)))
+	router.HandleFunc("PUT /me/availability", coachOnly(handle(coHandlers.SetMyWeeklyAvailability)))
+	router.HandleFunc("POST /me/availability/exceptions", coachOnly(handle(coHandlers.AddMyException)))
+	router.HandleFunc("DELETE /me/availability/exceptions/{id}", coachOnly(handle(coHandlers.RemoveMyException)))
+
+	router.HandleFunc("POST /coaches/{id}/training-sessions", bookable(handle(coHandlers.RequestSession)))
+	router.HandleFunc("GET /me/training-sessions", protected(handle(coHandlers.GetMySessions)))
+	router.HandleFunc("POST /training-sessions/{id}/accept", coachOnly(handle(coHandlers.AcceptSession)))
+	router.HandleFunc("POST /training-sessions/{id}/decline", coachOnly(handle(coHandlers.DeclineSession)))
+	router.HandleFunc("POST /training-sessions/{id}/cancel", protected(handle(coHandlers.CancelSession)))
+	router.HandleFunc("POST /training-sessions/{id}/reschedule", protected(handle(coHandlers.ProposeReschedule)))
+	router.HandleFunc("POST /training-sessions/{id}/reschedule/accept", protected(handle(coHandlers.AcceptReschedule)))
+	router.HandleFunc("POST /training-sessions/{id}/reschedule/decline", protected(handle(coHandlers.DeclineReschedule)))
+
+	router.HandleFunc("GET /fighters", handle(fHandlers.GetFighters))
+	router.HandleFunc("GET /fighters/{id}", handle(fHandlers.GetFighter))
+	router.HandleFunc("GET /me/fighter-profile", protected(handle(fHandlers.GetMyProfile)))
+	router.HandleFunc("PUT /me/fighter-profile", protected(handle(fHandlers.UpdateMyProfile)))
+	router.HandleFunc("GET /users/{id}/fighter-profile", coachOnly(handle(fHandlers.GetUserProfile)))
+	router.HandleFunc("POST /users/{id}/bouts", coachOnly(handle(fHandlers.RecordBout)))
+	router.HandleFunc("DELETE /bouts/{id}", coachOnly(handle(fHandlers.DeleteBout)))
+
+	router.HandleFunc("GET /me/weigh-ins", protected(handle(wHandlers.GetMyWeighIns)))
+	router.HandleFunc("POST /me/weigh-ins", protected(handle(wHandlers.LogMyWeighIn)))
+	router.HandleFunc("DELETE /me/weigh-ins/{id}", protected(handle(wHandlers.DeleteMyWeighIn)))
+	router.HandleFunc("GET /me/weight-trend", protected(handle(wHandlers.GetMyTrend)))
+	router.HandleFunc("PUT /me/weight-target", protected(handle(wHandlers.SetMyTarget)))
+	router.HandleFunc("DELETE /me/weight-target", protected(handle(wHandlers.RemoveMyTarget)))
+	router.HandleFunc("GET /users/{id}/weigh-ins", coachOnly(handle(wHandlers.GetUserWeighIns)))
+	router.HandleFunc("POST /users/{id}/weigh-ins", coachOnly(handle(wHandlers.LogUserWeighIn)))
+	router.HandleFunc("GET /users/{id}/weight-trend", coachOnly(handle(wHandlers.GetUserTrend)))
+	router.HandleFunc("PUT /users/{id}/weight-target", coachOnly(handle(wHandlers.SetUserTarget)))
+
+	router.HandleFunc("GET /sessions/{id}/sparring-suggestions", coachOnly(handle(spHandlers.SuggestPairings)))
+	router.HandleFunc("POST /sessions/{id}/sparring-pairings", coachOnly(handle(spHandlers.RecordPairings)))
+
+	handler := withRoute(router)
+	router.Handle(apiPrefixPattern, http.StripPrefix("/api", handler))
+	return handler
+}
+
+// withRoute records the route pattern the request matches for the middlewares
+// around the router and adds it to the request logger. Requests under /api
+// are left to the call that handles them once the prefix is stripped, so the
+// pattern is resolved once, against the route that serves the request.
+func withRoute(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		if pattern == apiPrefixPattern {
+			mux.ServeHTTP(w, r)
+			return
+		}
 
-	router.Handle("/api/", http.StripPrefix("/api", router))
-	return router
+		middleware.SetRoute(r.Context(), pattern)
+		mux.ServeHTTP(w, r.WithContext(logging.WithAttrs(r.Context(), slog.String("route", pattern))))
+	})
 }
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/health"
+	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/worker"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/smtp"
+)
+
+// loggedRouter returns the router wrapped in the logging middleware, and the
+// buffer the default logger writes to for the rest of the test.
+func loggedRouter(t *testing.T) (http.Handler, *bytes.Buffer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	db := &fakeDB{}
+	handler := middleware.Logging(NewRouter(
+		&config.Config{},
+		db,
+		repository.New(db),
+		smtp.NewSMTPService(config.SMTPConfig{}),
+		worker.NewGroup(),
+		health.NewChecker(time.Second, 0),
+	))
+	return handler, &buf
+}
+
+func TestRouteLabels(t *testing.T) {
+	handler, buf := loggedRouter(t)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "route", path: "/coaches/abc", want: "GET /coaches/{id}"},
+		{name: "route under the api prefix", path: "/api/coaches/abc", want: "GET /coaches/{id}"},
+		{name: "no route", path: "/api/nothing-here", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			for _, line := range lines {
+				var entry struct {
+					Msg     string `json:"msg"`
+					Route   string `json:"route"`
+					Request struct {
+						Route string `json:"route"`
+					} `json:"request"`
+				}
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("failed to decode log line %q: %v", line, err)
+				}
+
+				if entry.Msg == "API Request Error" || entry.Msg == "API Request" {
+					if entry.Request.Route != tt.want {
+						t.Errorf("request logged with route %q, want %q", entry.Request.Route, tt.want)
+					}
+					continue
+				}
+				// Logs written while handling the request carry the route once
+				if n := strings.Count(line, `"route":`); n != 1 {
+					t.Errorf("%q has %d route attributes, want 1", entry.Msg, n)
+				}
+				if entry.Route != tt.want {
+					t.Errorf("%q logged with route %q, want %q", entry.Msg, entry.Route, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProbesAreNotLogged(t *testing.T) {
+	handler, buf := loggedRouter(t)
+
+	tests := []struct {
+		path       string
+		wantLogged bool
+	}{
+		{path: "/healthz"},
+		{path: "/api/healthz"},
+		{path: "/readyz"},
+		{path: "/api/readyz"},
+		{path: "/api/version"},
+		{path: "/metrics"},
+		{path: "/rooms", wantLogged: true},
+		{path: "/api/nothing-here", wantLogged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			buf.Reset()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			logged := strings.Contains(buf.String(), `"msg":"API Request`)
+			if logged != tt.wantLogged {
+				t.Errorf("request logged = %v, want %v: %s", logged, tt.wantLogged, buf)
+			}
+		})
+	}
+}
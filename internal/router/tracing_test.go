@@ -0,0 +1,92 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/health"
+	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
+	"github.com/grez-lucas/boxer66-service/internal/worker"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestTracingSpanChain checks that a request is traced end to end: the
+// service span is a child of the HTTP server span and the query span a child
+// of the service span.
+func TestTracingSpanChain(t *testing.T) {
+	t.Setenv("JWT_SECRET", "tracing-test-secret")
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(exporter, "boxer66-service-test")
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	now := time.Now()
+	password, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &fakeDB{
+		rows: map[string][][]any{
+			"GetUserByEmail": {
+				{int32(7), "member@example.com", password, now, now, "active", pgtype.Text{}, now, "member", pgtype.Text{}},
+			},
+		},
+		tracer: repository.QueryTracer{},
+	}
+	handler := middleware.Tracing(NewRouter(
+		&config.Config{},
+		db,
+		repository.New(db),
+		smtp.NewSMTPService(config.SMTPConfig{}),
+		worker.NewGroup(),
+		health.NewChecker(time.Second, 0),
+	))
+
+	body := strings.NewReader(`{"email":"member@example.com","password":"correct horse"}`)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/login", body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	if err := provider.ForceFlush(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	find := func(name string) tracetest.SpanStub {
+		t.Helper()
+		for _, span := range spans {
+			if span.Name == name {
+				return span
+			}
+		}
+		t.Fatalf("no %q span in %d spans", name, len(spans))
+		return tracetest.SpanStub{}
+	}
+
+	server := find("POST /login")
+	service := find("UserService.Login")
+	query := find("GetUserByEmail")
+
+	if got := service.Parent.SpanID(); got != server.SpanContext.SpanID() {
+		t.Errorf("service span has parent %s, want the server span %s", got, server.SpanContext.SpanID())
+	}
+	if got := query.Parent.SpanID(); got != service.SpanContext.SpanID() {
+		t.Errorf("query span has parent %s, want the service span %s", got, service.SpanContext.SpanID())
+	}
+	for _, span := range []tracetest.SpanStub{service, query} {
+		if span.SpanContext.TraceID() != server.SpanContext.TraceID() {
+			t.Errorf("%q is in trace %s, want %s", span.Name, span.SpanContext.TraceID(), server.SpanContext.TraceID())
+		}
+	}
+}
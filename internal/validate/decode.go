@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+)
+
+// MaxBodyBytes bounds the size of a JSON request body.
+const MaxBodyBytes = 1 << 20
+
+var ErrBodyTooLarge = apperror.New(http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large")
+
+// DecodeJSON decodes the request body into the struct v points to and
+// validates it with Struct. Bodies over MaxBodyBytes, unknown fields and
+// trailing data are rejected.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("request body has more than one JSON value")
+		}
+		return decodeError(err)
+	}
+
+	return Struct(v)
+}
+
+// decodeError maps a decoding error to the problem sent to the client.
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrBodyTooLarge.Wrap(err)
+	}
+
+	invalid := apperror.InvalidBody(err)
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return invalid.WithFields(apperror.FieldError{
+			Field:   typeErr.Field,
+			Message: "has the wrong type: got " + typeErr.Value,
+		})
+	}
+
+	// encoding/json has no typed error for unknown fields
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if field, unquoteErr := strconv.Unquote(name); unquoteErr == nil {
+			name = field
+		}
+		return invalid.WithFields(apperror.FieldError{Field: name, Message: "is not allowed"})
+	}
+
+	return invalid
+}
@@ -0,0 +1,221 @@
+// Package validate checks request DTOs against rules declared in struct tags.
+//
+// Rules go in the validate tag, separated by commas:
+//
+//	required        the field must not be empty
+//	email           the field must be an email address; it is normalized first
+//	length=min:max  the field must have between min and max characters (or
+//	                items); either bound may be left out
+//	bytes=min:max   like length, counting the bytes of a string, e.g. for
+//	                passwords bcrypt truncates past 72 bytes
+//
+// A regular expression the whole value must match goes in its own pattern
+// tag, so it may contain commas. Fields are reported by their JSON name.
+package validate
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidFields is returned by Struct with one FieldError per failed
+// field.
+var ErrInvalidFields = apperror.New(http.StatusUnprocessableEntity, "validation_failed", "Request has invalid fields")
+
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// Struct normalizes and validates the struct v points to. It returns
+// ErrInvalidFields listing every invalid field, or nil.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	var fields []apperror.FieldError
+	for i := range rv.NumField() {
+		field := rv.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		message, err := check(field, rv.Field(i))
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			fields = append(fields, apperror.FieldError{Field: jsonName(field), Message: message})
+		}
+	}
+
+	if len(fields) > 0 {
+		return ErrInvalidFields.WithFields(fields...)
+	}
+	return nil
+}
+
+// check runs the rules of a single field and returns the message of the first
+// one that fails. Errors are reserved for malformed tags.
+func check(field reflect.StructField, value reflect.Value) (string, error) {
+	for rule := range strings.SplitSeq(field.Tag.Get("validate"), ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "":
+		case "required":
+			if value.IsZero() || (value.Kind() == reflect.Slice && value.Len() == 0) {
+				return "is required", nil
+			}
+		case "email":
+			if value.Kind() != reflect.String {
+				return "", fmt.Errorf("validate: email rule on non-string field %s", field.Name)
+			}
+			email := NormalizeEmail(value.String())
+			value.SetString(email)
+			if email != "" && !isEmail(email) {
+				return "must be a valid email address", nil
+			}
+		case "length":
+			lo, hi, err := parseLength(arg)
+			if err != nil {
+				return "", fmt.Errorf("validate: field %s: %w", field.Name, err)
+			}
+			if message := checkLength(value, lo, hi); message != "" {
+				return message, nil
+			}
+		case "bytes":
+			if value.Kind() != reflect.String {
+				return "", fmt.Errorf("validate: bytes rule on non-string field %s", field.Name)
+			}
+			lo, hi, err := parseLength(arg)
+			if err != nil {
+				return "", fmt.Errorf("validate: field %s: %w", field.Name, err)
+			}
+			if message := lengthMessage(len(value.String()), lo, hi, "bytes"); message != "" {
+				return message, nil
+			}
+		default:
+			return "", fmt.Errorf("validate: unknown rule %q on field %s", name, field.Name)
+		}
+	}
+
+	if pattern := field.Tag.Get("pattern"); pattern != "" {
+		if value.Kind() != reflect.String {
+			return "", fmt.Errorf("validate: pattern on non-string field %s", field.Name)
+		}
+		re, err := compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("validate: field %s: %w", field.Name, err)
+		}
+		if value.String() != "" && !re.MatchString(value.String()) {
+			return "has an invalid format", nil
+		}
+	}
+
+	return "", nil
+}
+
+// NormalizeEmail trims whitespace, lowercases the address and converts an
+// internationalized domain to its ASCII form.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	domain, err := idna.Lookup.ToASCII(email[at+1:])
+	if err != nil {
+		return email
+	}
+	return email[:at+1] + domain
+}
+
+// isEmail reports whether email is a bare address, without a display name or
+// angle brackets.
+func isEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
+
+func checkLength(value reflect.Value, lo, hi int) string {
+	switch value.Kind() {
+	case reflect.String:
+		return lengthMessage(utf8.RuneCountInString(value.String()), lo, hi, "characters")
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return lengthMessage(value.Len(), lo, hi, "items")
+	default:
+		return ""
+	}
+}
+
+// lengthMessage describes why n is outside [lo, hi], or returns "" when it
+// isn't. A negative hi means there is no upper bound.
+func lengthMessage(n, lo, hi int, unit string) string {
+	switch {
+	case hi < 0 && n < lo:
+		return fmt.Sprintf("must have at least %d %s", lo, unit)
+	case hi >= 0 && (n < lo || n > hi):
+		if lo == hi {
+			return fmt.Sprintf("must have exactly %d %s", lo, unit)
+		}
+		if lo == 0 {
+			return fmt.Sprintf("must have at most %d %s", hi, unit)
+		}
+		return fmt.Sprintf("must have between %d and %d %s", lo, hi, unit)
+	}
+	return ""
+}
+
+// parseLength parses a "min:max" length argument. A missing max is returned
+// as -1.
+func parseLength(arg string) (lo, hi int, err error) {
+	loArg, hiArg, found := strings.Cut(arg, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid length %q, want min:max", arg)
+	}
+
+	hi = -1
+	if loArg != "" {
+		if lo, err = strconv.Atoi(loArg); err != nil {
+			return 0, 0, fmt.Errorf("invalid length %q: %w", arg, err)
+		}
+	}
+	if hiArg != "" {
+		if hi, err = strconv.Atoi(hiArg); err != nil {
+			return 0, 0, fmt.Errorf("invalid length %q: %w", arg, err)
+		}
+	}
+	return lo, hi, nil
+}
+
+// compile compiles a pattern anchored to the whole value, caching the result.
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+)
+
+type passwordRequest struct {
+	Password string `json:"password" validate:"required,length=8:,bytes=:72"`
+}
+
+func TestStructPasswordBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		message  string
+	}{
+		{name: "ascii", password: strings.Repeat("a", 72)},
+		{name: "too short", password: "short", message: "must have at least 8 characters"},
+		{name: "ascii too long", password: strings.Repeat("a", 73), message: "must have at most 72 bytes"},
+		// 72 characters, but 144 bytes once encoded
+		{name: "multibyte too long", password: strings.Repeat("é", 72), message: "must have at most 72 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(&passwordRequest{Password: tt.password})
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("Struct() = %v, want nil", err)
+				}
+				return
+			}
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) {
+				t.Fatalf("Struct() = %v, want an *apperror.Error", err)
+			}
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Message != tt.message {
+				t.Fatalf("Struct() fields = %+v, want one field with %q", appErr.Fields, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context) error
+}
+
+// Group runs a set of jobs with Every and waits for running jobs to finish
+// when stopped.
+type Group struct {
+	jobs   []job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Add registers a job. Jobs added after Start are not run.
+func (g *Group) Add(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	g.jobs = append(g.jobs, job{name: name, interval: interval, fn: fn})
+}
+
+func (g *Group) Start(ctx context.Context) error {
+	ctx, g.cancel = context.WithCancel(ctx)
+	for _, j := range g.jobs {
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			Every(ctx, j.name, j.interval, j.fn)
+		}()
+	}
+	return nil
+}
+
+// Stop stops scheduling new runs and waits for the running ones until ctx is
+// done.
+func (g *Group) Stop(ctx context.Context) error {
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs still running: %w", ctx.Err())
+	}
+}
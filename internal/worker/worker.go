@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs fn every interval until ctx is cancelled. A failing run is
+// logged and doesn't stop the next ones. Runs get a context that isn't
+// cancelled with ctx, so a run in progress finishes its work.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(context.WithoutCancel(ctx)); err != nil {
+				slog.Error("Background job failed", slog.String("job", name), slog.Any("error", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package legal
+
+import "time"
+
+type DocumentResponse struct {
+	ID          int32     `json:"id"`
+	Kind        string    `json:"kind"`
+	Version     int32     `json:"version"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type AcceptDocumentsRequest struct {
+	DocumentIDs []int32 `json:"document_ids"`
+}
@@ -0,0 +1,86 @@
+package legal
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type LegalHandlers struct {
+	lService ILegalService
+}
+
+func NewLegalHandlers(lService ILegalService) *LegalHandlers {
+	return &LegalHandlers{
+		lService: lService,
+	}
+}
+
+func (h *LegalHandlers) GetCurrentDocuments(w http.ResponseWriter, r *http.Request) error {
+	documents, err := h.lService.GetCurrentDocuments(r.Context())
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toDocumentResponses(documents), http.StatusOK)
+	return nil
+}
+
+func (h *LegalHandlers) GetOutstandingDocuments(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	documents, err := h.lService.GetOutstandingDocuments(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toDocumentResponses(documents), http.StatusOK)
+	return nil
+}
+
+func (h *LegalHandlers) AcceptDocuments(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var acceptRequest AcceptDocumentsRequest
+	if err := validate.DecodeJSON(w, r, &acceptRequest); err != nil {
+		return err
+	}
+
+	if err := h.lService.AcceptDocuments(r.Context(), userID, acceptRequest.DocumentIDs, middleware.ClientIP(r)); err != nil {
+		if errors.Is(err, ErrDocumentNotCurrent) {
+			return apperror.New(http.StatusBadRequest, "document_not_current", "Only the current version of a document can be accepted").Wrap(err)
+		}
+		return err
+	}
+
+	users.WriteSuccess(w, "Documents accepted", http.StatusOK)
+	return nil
+}
+
+func toDocumentResponses(documents []repository.LegalDocument) []DocumentResponse {
+	resp := make([]DocumentResponse, 0, len(documents))
+	for _, d := range documents {
+		resp = append(resp, DocumentResponse{
+			ID:          d.ID,
+			Kind:        d.Kind,
+			Version:     d.Version,
+			Title:       d.Title,
+			Body:        d.Body,
+			PublishedAt: d.PublishedAt,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,15 @@
+package legal
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type ILegalService interface {
+	GetCurrentDocuments(ctx context.Context) ([]repository.LegalDocument, error)
+	GetOutstandingDocuments(ctx context.Context, userID int32) ([]repository.LegalDocument, error)
+	AcceptDocuments(ctx context.Context, userID int32, documentIDs []int32, ip netip.Addr) error
+	RequireAcceptance(ctx context.Context, userID int32) (context.Context, error)
+}
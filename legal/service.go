@@ -0,0 +1,87 @@
+package legal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/middleware"
+)
+
+const (
+	DocumentKindTerms  = "terms"
+	DocumentKindWaiver = "waiver"
+)
+
+type LegalService struct {
+	repository *repository.Queries
+}
+
+func NewLegalService(
+	repository *repository.Queries,
+) *LegalService {
+	return &LegalService{
+		repository: repository,
+	}
+}
+
+var (
+	ErrDocumentsOutstanding = errors.New("the current legal documents must be accepted")
+	ErrDocumentNotCurrent   = errors.New("document is not a current legal document")
+)
+
+func (s *LegalService) GetCurrentDocuments(ctx context.Context) ([]repository.LegalDocument, error) {
+	return s.repository.GetCurrentLegalDocuments(ctx)
+}
+
+func (s *LegalService) GetOutstandingDocuments(ctx context.Context, userID int32) ([]repository.LegalDocument, error) {
+	return s.repository.GetOutstandingLegalDocuments(ctx, userID)
+}
+
+func (s *LegalService) AcceptDocuments(ctx context.Context, userID int32, documentIDs []int32, ip netip.Addr) error {
+	// Only the current version of a document can be accepted
+	currentDocuments, err := s.repository.GetCurrentLegalDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current legal documents: %w", err)
+	}
+	for _, documentID := range documentIDs {
+		isCurrent := slices.ContainsFunc(currentDocuments, func(d repository.LegalDocument) bool {
+			return d.ID == documentID
+		})
+		if !isCurrent {
+			return fmt.Errorf("%w: %d", ErrDocumentNotCurrent, documentID)
+		}
+	}
+
+	for _, documentID := range documentIDs {
+		err := s.repository.CreateLegalAcceptance(ctx, repository.CreateLegalAcceptanceParams{
+			UserID:     userID,
+			DocumentID: documentID,
+			IpAddress:  ip,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record legal acceptance in db: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RequireAcceptance is a middleware.AccountCheck that blocks users until they
+// have accepted the current version of every legal document.
+func (s *LegalService) RequireAcceptance(ctx context.Context, userID int32) (context.Context, error) {
+	outstanding, err := s.repository.GetOutstandingLegalDocuments(ctx, userID)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to get outstanding legal documents: %w", err)
+	}
+
+	if len(outstanding) > 0 {
+		return ctx, middleware.Reject(http.StatusUnavailableForLegalReasons, ErrDocumentsOutstanding)
+	}
+
+	return ctx, nil
+}
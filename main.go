@@ -2,32 +2,86 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/grez-lucas/boxer66-service/health"
 	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/lifecycle"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/metrics"
 	"github.com/grez-lucas/boxer66-service/internal/repository"
 	"github.com/grez-lucas/boxer66-service/internal/router"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
+	"github.com/grez-lucas/boxer66-service/internal/worker"
 	"github.com/grez-lucas/boxer66-service/middleware"
-	"github.com/jackc/pgx/v5"
+	"github.com/grez-lucas/boxer66-service/migrations"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
+	// Startup and shutdown use ctx, so it isn't cancelled by the shutdown
+	// signal.
 	ctx := context.Background()
 	cfg := config.LoadConfig()
+	slog.SetDefault(logging.New(cfg.LogConfig))
 
-	conn, err := pgx.Connect(ctx, cfg.DatabaseURL)
+	signalCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	exporter, err := tracing.NewExporter(ctx, cfg.TracingConfig)
+	if err != nil {
+		panic(err)
+	}
+	tracerProvider := tracing.NewProvider(exporter, cfg.TracingConfig.ServiceName)
+
+	pool, err := repository.NewPool(ctx, cfg.DatabaseURL, cfg.DBPoolConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	queries := repository.New(pool)
+
+	// Role changes need a staff account, so the first one comes from config
+	if err := users.NewUserService(queries).BootstrapStaff(ctx, cfg.BootstrapStaffEmail); err != nil {
+		panic(err)
+	}
+
+	smtpService := smtp.NewSMTPService(cfg.SMTPConfig)
+	workers := worker.NewGroup()
+
+	migrationVersion, err := migrations.LatestVersion()
 	if err != nil {
 		panic(err)
 	}
-	defer conn.Close(ctx)
+	checker := health.NewChecker(
+		time.Duration(cfg.HealthConfig.CheckTimeoutMillis)*time.Millisecond,
+		time.Duration(cfg.HealthConfig.CacheSeconds)*time.Second,
+		health.DatabaseCheck(pool),
+		health.MigrationCheck(pool, migrationVersion),
+		health.SMTPCheck(smtpService),
+	)
 
-	queries := repository.New(conn)
+	prometheus.MustRegister(metrics.NewPoolCollector(pool))
 
-	chain := middleware.CreateStack(middleware.Logging, middleware.Cors)
+	// Tracing, Metrics and Logging label requests with the route the router
+	// records through middleware.SetRoute.
+	chain := middleware.CreateStack(
+		middleware.RequestID,
+		middleware.Tracing,
+		middleware.Metrics,
+		middleware.Logging,
+		middleware.Cors,
+	)
 
-	router := router.NewRouter(ctx, cfg, queries)
+	router := router.NewRouter(cfg, pool, queries, smtpService, workers, checker)
 
 	server := http.Server{
 		Addr:              ":8080",
@@ -37,6 +91,54 @@ func main() {
 		Handler:           chain(router),
 	}
 
-	fmt.Println("Server listening on port :8080")
-	server.ListenAndServe()
+	// Components stop in reverse order: the server drains requests first, then
+	// workers finish their runs, then pending emails go out, the pool closes
+	// and the remaining spans are flushed.
+	serverErr := make(chan error, 1)
+	lc := lifecycle.New()
+	lc.Register(lifecycle.Component{Name: "tracing", Stop: tracerProvider.Shutdown})
+	lc.Register(lifecycle.Component{
+		Name: "database pool",
+		Stop: func(context.Context) error {
+			pool.Close()
+			return nil
+		},
+	})
+	lc.Register(lifecycle.Component{Name: "smtp", Stop: smtpService.Close})
+	lc.Register(lifecycle.Component{Name: "workers", Start: workers.Start, Stop: workers.Stop})
+	lc.Register(lifecycle.Component{
+		Name: "http server",
+		Start: func(context.Context) error {
+			go func() {
+				if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					serverErr <- err
+				}
+			}()
+			slog.Info("Server listening", slog.String("addr", server.Addr))
+			return nil
+		},
+		Stop: server.Shutdown,
+	})
+
+	if err := lc.Start(ctx); err != nil {
+		slog.Error("Failed to start", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	exitCode := 0
+	select {
+	case <-signalCtx.Done():
+		slog.Info("Shutting down")
+	case err := <-serverErr:
+		slog.Error("Server failed", slog.Any("error", err))
+		exitCode = 1
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Duration(cfg.ShutdownConfig.DrainTimeoutSeconds)*time.Second)
+	if err := lc.Stop(shutdownCtx); err != nil {
+		slog.Error("Failed to shut down cleanly", slog.Any("error", err))
+		exitCode = 1
+	}
+	cancel()
+	os.Exit(exitCode)
 }
@@ -0,0 +1,62 @@
+package memberships
+
+import "time"
+
+type PlanResponse struct {
+	ID                   int32  `json:"id"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	PriceCents           int32  `json:"price_cents"`
+	BillingPeriod        string `json:"billing_period"`
+	ValidityDays         *int32 `json:"validity_days"`
+	ClassAllowance       *int32 `json:"class_allowance"`
+	MaxFreezesPerYear    int32  `json:"max_freezes_per_year"`
+	MaxFreezeDaysPerYear int32  `json:"max_freeze_days_per_year"`
+}
+
+type CreatePlanRequest struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	PriceCents           int32  `json:"price_cents"`
+	BillingPeriod        string `json:"billing_period"`
+	ValidityDays         *int32 `json:"validity_days"`
+	ClassAllowance       *int32 `json:"class_allowance"`
+	MaxFreezesPerYear    *int32 `json:"max_freezes_per_year"`
+	MaxFreezeDaysPerYear *int32 `json:"max_freeze_days_per_year"`
+}
+
+type AssignSubscriptionRequest struct {
+	PlanID int32 `json:"plan_id"`
+	// StartsAt defaults to now when omitted
+	StartsAt *time.Time `json:"starts_at"`
+}
+
+type SubscriptionResponse struct {
+	ID          int32      `json:"id"`
+	UserID      int32      `json:"user_id"`
+	PlanID      int32      `json:"plan_id"`
+	Status      string     `json:"status"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	RenewsAt    *time.Time `json:"renews_at"`
+	PausedAt    *time.Time `json:"paused_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+}
+
+type FreezeRequest struct {
+	// StartsAt defaults to now when omitted
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   time.Time  `json:"ends_at"`
+	Reason   string     `json:"reason"`
+}
+
+type FreezeResponse struct {
+	ID             int32      `json:"id"`
+	SubscriptionID int32      `json:"subscription_id"`
+	UserID         int32      `json:"user_id"`
+	Status         string     `json:"status"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         time.Time  `json:"ends_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+	Reason         string     `json:"reason"`
+}
@@ -0,0 +1,297 @@
+package memberships
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultMaxFreezesPerYear    = 2
+	defaultMaxFreezeDaysPerYear = 60
+	// minFreezeLength keeps members from freezing around a single class
+	minFreezeLength = 24 * time.Hour
+
+	FreezeStatusScheduled = "scheduled"
+	FreezeStatusActive    = "active"
+	FreezeStatusCompleted = "completed"
+	FreezeStatusCancelled = "cancelled"
+)
+
+var (
+	ErrNoActiveSubscription = errors.New("member has no active subscription")
+	ErrInvalidFreeze        = errors.New("freeze is invalid")
+	ErrFreezeOverlaps       = errors.New("freeze overlaps another freeze")
+	ErrFreezeLimitReached   = errors.New("freeze limit for the year reached")
+	ErrFreezeDoesntExist    = errors.New("freeze does not exist")
+	ErrFreezeAlreadyEnded   = errors.New("freeze has already ended")
+	ErrMembershipFrozen     = errors.New("membership is frozen")
+)
+
+type freezeContextKey struct{}
+
+// FreezeFromContext returns the active freeze flagged by FlagFrozen, if the
+// member is frozen.
+func FreezeFromContext(ctx context.Context) (*repository.MembershipFreeze, bool) {
+	freeze, ok := ctx.Value(freezeContextKey{}).(*repository.MembershipFreeze)
+	return freeze, ok
+}
+
+func (s *MembershipService) GetFreezes(ctx context.Context, userID int32) ([]repository.MembershipFreeze, error) {
+	return s.repository.GetMembershipFreezesByUserID(ctx, userID)
+}
+
+// RequestFreeze freezes a member's current subscription between two dates,
+// within the yearly limits of its plan. The subscription end date is pushed
+// back by the length of the freeze right away.
+func (s *MembershipService) RequestFreeze(
+	ctx context.Context,
+	userID int32,
+	startsAt, endsAt time.Time,
+	reason string,
+	requestedBy int32,
+) (*repository.MembershipFreeze, error) {
+	now := time.Now()
+	if startsAt.Before(now) {
+		startsAt = now
+	}
+	if endsAt.Sub(startsAt) < minFreezeLength {
+		return nil, fmt.Errorf("%w: a freeze must last at least a day", ErrInvalidFreeze)
+	}
+
+	var freeze repository.MembershipFreeze
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		current, err := q.GetCurrentSubscription(ctx, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoActiveSubscription
+			}
+			return fmt.Errorf("failed to get current subscription: %w", err)
+		}
+
+		subscription, err := q.GetSubscriptionForUpdate(ctx, current.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock subscription: %w", err)
+		}
+		if subscription.Status != SubscriptionStatusActive {
+			return ErrNoActiveSubscription
+		}
+		if !startsAt.Before(subscription.EndsAt) {
+			return fmt.Errorf("%w: the subscription ends before the freeze starts", ErrInvalidFreeze)
+		}
+
+		overlaps, err := q.HasOverlappingFreeze(ctx, repository.HasOverlappingFreezeParams{
+			SubscriptionID: subscription.ID,
+			StartsAt:       startsAt,
+			EndsAt:         endsAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check overlapping freezes: %w", err)
+		}
+		if overlaps {
+			return ErrFreezeOverlaps
+		}
+
+		plan, err := q.GetMembershipPlanByID(ctx, subscription.PlanID)
+		if err != nil {
+			return fmt.Errorf("failed to get plan: %w", err)
+		}
+		if err := checkFreezeLimits(ctx, q, plan, userID, startsAt, endsAt); err != nil {
+			return err
+		}
+
+		freeze, err = q.CreateMembershipFreeze(ctx, repository.CreateMembershipFreezeParams{
+			SubscriptionID: subscription.ID,
+			UserID:         userID,
+			StartsAt:       startsAt,
+			EndsAt:         endsAt,
+			Reason:         reason,
+			RequestedBy:    pgtype.Int4{Int32: requestedBy, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create freeze in db: %w", err)
+		}
+
+		if _, err := q.ShiftSubscriptionEnd(ctx, repository.ShiftSubscriptionEndParams{
+			ID:    subscription.ID,
+			Shift: toInterval(endsAt.Sub(startsAt)),
+		}); err != nil {
+			return fmt.Errorf("failed to extend subscription in db: %w", err)
+		}
+
+		// Freezes starting now don't wait for the next scheduler run
+		if !startsAt.After(now) {
+			freeze, err = q.SetMembershipFreezeStatus(ctx, repository.SetMembershipFreezeStatusParams{
+				ID:     freeze.ID,
+				Status: FreezeStatusActive,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to start freeze in db: %w", err)
+			}
+			if err := q.FreezeSubscription(ctx, subscription.ID); err != nil {
+				return fmt.Errorf("failed to freeze subscription in db: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &freeze, nil
+}
+
+// EndFreeze cancels a member's scheduled freeze or ends an active one early.
+// The subscription end date is pulled in by the unused part of the freeze.
+func (s *MembershipService) EndFreeze(ctx context.Context, freezeID, userID int32) (*repository.MembershipFreeze, error) {
+	var freeze repository.MembershipFreeze
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		var err error
+		freeze, err = q.GetMembershipFreezeForUpdate(ctx, freezeID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrFreezeDoesntExist
+			}
+			return fmt.Errorf("failed to get freeze: %w", err)
+		}
+		// Other members' freezes are reported as missing
+		if freeze.UserID != userID {
+			return ErrFreezeDoesntExist
+		}
+
+		if _, err := q.GetSubscriptionForUpdate(ctx, freeze.SubscriptionID); err != nil {
+			return fmt.Errorf("failed to lock subscription: %w", err)
+		}
+
+		now := time.Now()
+		var unused time.Duration
+		switch freeze.Status {
+		case FreezeStatusScheduled:
+			unused = freeze.EndsAt.Sub(freeze.StartsAt)
+			freeze, err = q.SetMembershipFreezeStatus(ctx, repository.SetMembershipFreezeStatusParams{
+				ID:     freeze.ID,
+				Status: FreezeStatusCancelled,
+			})
+		case FreezeStatusActive:
+			unused = freeze.EndsAt.Sub(now)
+			freeze, err = q.SetMembershipFreezeStatus(ctx, repository.SetMembershipFreezeStatusParams{
+				ID:      freeze.ID,
+				Status:  FreezeStatusCompleted,
+				EndedAt: pgtype.Timestamptz{Time: now, Valid: true},
+			})
+			if err == nil {
+				err = q.UnfreezeSubscription(ctx, freeze.SubscriptionID)
+			}
+		default:
+			return ErrFreezeAlreadyEnded
+		}
+		if err != nil {
+			return fmt.Errorf("failed to end freeze in db: %w", err)
+		}
+
+		if unused > 0 {
+			if _, err := q.ShiftSubscriptionEnd(ctx, repository.ShiftSubscriptionEndParams{
+				ID:    freeze.SubscriptionID,
+				Shift: toInterval(-unused),
+			}); err != nil {
+				return fmt.Errorf("failed to shorten subscription in db: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &freeze, nil
+}
+
+// ApplyFreezes starts freezes that are due and unfreezes members whose freeze
+// has ended. It is run periodically.
+func (s *MembershipService) ApplyFreezes(ctx context.Context) error {
+	return repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		if err := q.CancelStaleFreezes(ctx); err != nil {
+			return fmt.Errorf("failed to cancel stale freezes: %w", err)
+		}
+		if _, err := q.StartDueFreezes(ctx); err != nil {
+			return fmt.Errorf("failed to start freezes: %w", err)
+		}
+		if _, err := q.EndDueFreezes(ctx); err != nil {
+			return fmt.Errorf("failed to end freezes: %w", err)
+		}
+		return nil
+	})
+}
+
+// FlagFrozen is a middleware.AccountCheck that flags frozen members in the
+// request context. It doesn't reject anyone, see RejectFrozen.
+func (s *MembershipService) FlagFrozen(ctx context.Context, userID int32) (context.Context, error) {
+	freeze, err := s.repository.GetActiveMembershipFreeze(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ctx, nil
+		}
+		return ctx, fmt.Errorf("failed to get active freeze: %w", err)
+	}
+
+	return context.WithValue(ctx, freezeContextKey{}, &freeze), nil
+}
+
+// RejectFrozen is a middleware.AccountCheck that keeps frozen members out. It
+// must run after FlagFrozen.
+func (s *MembershipService) RejectFrozen(ctx context.Context, userID int32) (context.Context, error) {
+	if freeze, ok := FreezeFromContext(ctx); ok {
+		return ctx, middleware.Reject(
+			http.StatusForbidden,
+			fmt.Errorf("%w until %s", ErrMembershipFrozen, freeze.EndsAt.Format(time.RFC3339)),
+		)
+	}
+	return ctx, nil
+}
+
+// checkFreezeLimits checks a new freeze against the plan's limits for the
+// calendar year it starts in.
+func checkFreezeLimits(
+	ctx context.Context,
+	q *repository.Queries,
+	plan repository.MembershipPlan,
+	userID int32,
+	startsAt, endsAt time.Time,
+) error {
+	yearStart := time.Date(startsAt.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	usage, err := q.GetFreezeUsage(ctx, repository.GetFreezeUsageParams{
+		UserID:   userID,
+		FromTime: yearStart,
+		ToTime:   yearStart.AddDate(1, 0, 0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get freeze usage: %w", err)
+	}
+
+	days := int32((endsAt.Sub(startsAt) + 24*time.Hour - 1) / (24 * time.Hour))
+	if usage.Freezes+1 > plan.MaxFreezesPerYear {
+		return fmt.Errorf("%w: %d freezes per year allowed", ErrFreezeLimitReached, plan.MaxFreezesPerYear)
+	}
+	if usage.Days+days > plan.MaxFreezeDaysPerYear {
+		return fmt.Errorf(
+			"%w: %d of %d freeze days left this year",
+			ErrFreezeLimitReached, max(plan.MaxFreezeDaysPerYear-usage.Days, 0), plan.MaxFreezeDaysPerYear,
+		)
+	}
+
+	return nil
+}
+
+func toInterval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{Microseconds: d.Microseconds(), Valid: true}
+}
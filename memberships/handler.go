@@ -0,0 +1,349 @@
+package memberships
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type MembershipHandlers struct {
+	mService IMembershipService
+}
+
+func NewMembershipHandlers(mService IMembershipService) *MembershipHandlers {
+	return &MembershipHandlers{
+		mService: mService,
+	}
+}
+
+func (h *MembershipHandlers) GetPlans(w http.ResponseWriter, r *http.Request) error {
+	plans, err := h.mService.GetPlans(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]PlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		resp = append(resp, toPlanResponse(plan))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *MembershipHandlers) CreatePlan(w http.ResponseWriter, r *http.Request) error {
+	var createRequest CreatePlanRequest
+	if err := validate.DecodeJSON(w, r, &createRequest); err != nil {
+		return err
+	}
+
+	plan, err := h.mService.CreatePlan(r.Context(), NewPlan{
+		Name:                 createRequest.Name,
+		Description:          createRequest.Description,
+		PriceCents:           createRequest.PriceCents,
+		BillingPeriod:        BillingPeriod(createRequest.BillingPeriod),
+		ValidityDays:         createRequest.ValidityDays,
+		ClassAllowance:       createRequest.ClassAllowance,
+		MaxFreezesPerYear:    createRequest.MaxFreezesPerYear,
+		MaxFreezeDaysPerYear: createRequest.MaxFreezeDaysPerYear,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidPlan) {
+			return apperror.New(http.StatusBadRequest, "invalid_plan", err.Error()).Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toPlanResponse(*plan), http.StatusCreated)
+	return nil
+}
+
+func (h *MembershipHandlers) GetUserSubscriptions(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	subscriptions, err := h.mService.GetUserSubscriptions(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, toSubscriptionResponse(subscription))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *MembershipHandlers) AssignSubscription(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var assignRequest AssignSubscriptionRequest
+	if err := validate.DecodeJSON(w, r, &assignRequest); err != nil {
+		return err
+	}
+
+	startsAt := time.Now()
+	if assignRequest.StartsAt != nil {
+		startsAt = *assignRequest.StartsAt
+	}
+
+	subscription, err := h.mService.AssignSubscription(r.Context(), userID, assignRequest.PlanID, startsAt, staffID)
+	if err != nil {
+		if errors.Is(err, ErrPlanDoesntExist) {
+			return apperror.New(http.StatusNotFound, "plan_doesnt_exist", "Plan does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrPlanIsInactive) {
+			return apperror.New(http.StatusConflict, "plan_is_inactive", "Plan is no longer offered").Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSubscriptionResponse(*subscription), http.StatusCreated)
+	return nil
+}
+
+func (h *MembershipHandlers) PauseSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeSubscription(w, r, h.mService.PauseSubscription)
+}
+
+func (h *MembershipHandlers) ResumeSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeSubscription(w, r, h.mService.ResumeSubscription)
+}
+
+func (h *MembershipHandlers) CancelSubscription(w http.ResponseWriter, r *http.Request) error {
+	return h.changeSubscription(w, r, h.mService.CancelSubscription)
+}
+
+func (h *MembershipHandlers) RenewSubscription(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.changeSubscription(w, r, func(ctx context.Context, subscriptionID int32) (*repository.Subscription, error) {
+		return h.mService.RenewSubscription(ctx, subscriptionID, staffID)
+	})
+}
+
+func (h *MembershipHandlers) changeSubscription(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(ctx context.Context, subscriptionID int32) (*repository.Subscription, error),
+) error {
+	subscriptionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Subscription ID is invalid")
+	}
+
+	subscription, err := change(r.Context(), subscriptionID)
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionDoesntExist) {
+			return apperror.New(http.StatusNotFound, "subscription_doesnt_exist", "Subscription does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrInvalidSubscriptionTransition) {
+			return apperror.New(http.StatusConflict, "invalid_subscription_transition", "Subscription can't be changed from its current status").Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toSubscriptionResponse(*subscription), http.StatusOK)
+	return nil
+}
+
+func (h *MembershipHandlers) GetMyFreezes(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writeFreezes(w, r, userID)
+}
+
+func (h *MembershipHandlers) GetUserFreezes(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writeFreezes(w, r, userID)
+}
+
+func (h *MembershipHandlers) RequestMyFreeze(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.requestFreeze(w, r, userID, userID)
+}
+
+func (h *MembershipHandlers) RequestUserFreeze(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.requestFreeze(w, r, userID, staffID)
+}
+
+func (h *MembershipHandlers) EndMyFreeze(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	freezeID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Freeze ID is invalid")
+	}
+
+	freeze, err := h.mService.EndFreeze(r.Context(), freezeID, userID)
+	if err != nil {
+		return freezeError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toFreezeResponse(*freeze), http.StatusOK)
+	return nil
+}
+
+func (h *MembershipHandlers) requestFreeze(w http.ResponseWriter, r *http.Request, userID, requestedBy int32) error {
+	var freezeRequest FreezeRequest
+	if err := validate.DecodeJSON(w, r, &freezeRequest); err != nil {
+		return err
+	}
+
+	startsAt := time.Now()
+	if freezeRequest.StartsAt != nil {
+		startsAt = *freezeRequest.StartsAt
+	}
+
+	freeze, err := h.mService.RequestFreeze(r.Context(), userID, startsAt, freezeRequest.EndsAt, freezeRequest.Reason, requestedBy)
+	if err != nil {
+		return freezeError(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toFreezeResponse(*freeze), http.StatusCreated)
+	return nil
+}
+
+func (h *MembershipHandlers) writeFreezes(w http.ResponseWriter, r *http.Request, userID int32) error {
+	freezes, err := h.mService.GetFreezes(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]FreezeResponse, 0, len(freezes))
+	for _, freeze := range freezes {
+		resp = append(resp, toFreezeResponse(freeze))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func freezeError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidFreeze):
+		return apperror.New(http.StatusBadRequest, "invalid_freeze", err.Error()).Wrap(err)
+	case errors.Is(err, ErrNoActiveSubscription):
+		return apperror.New(http.StatusNotFound, "no_active_subscription", err.Error()).Wrap(err)
+	case errors.Is(err, ErrFreezeDoesntExist):
+		return apperror.New(http.StatusNotFound, "freeze_doesnt_exist", err.Error()).Wrap(err)
+	case errors.Is(err, ErrFreezeOverlaps):
+		return apperror.New(http.StatusConflict, "freeze_overlaps", err.Error()).Wrap(err)
+	case errors.Is(err, ErrFreezeLimitReached):
+		return apperror.New(http.StatusConflict, "freeze_limit_reached", err.Error()).Wrap(err)
+	case errors.Is(err, ErrFreezeAlreadyEnded):
+		return apperror.New(http.StatusConflict, "freeze_already_ended", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func toFreezeResponse(freeze repository.MembershipFreeze) FreezeResponse {
+	return FreezeResponse{
+		ID:             freeze.ID,
+		SubscriptionID: freeze.SubscriptionID,
+		UserID:         freeze.UserID,
+		Status:         freeze.Status,
+		StartsAt:       freeze.StartsAt,
+		EndsAt:         freeze.EndsAt,
+		EndedAt:        fromTimestamptz(freeze.EndedAt),
+		Reason:         freeze.Reason,
+	}
+}
+
+func toPlanResponse(plan repository.MembershipPlan) PlanResponse {
+	return PlanResponse{
+		ID:                   plan.ID,
+		Name:                 plan.Name,
+		Description:          plan.Description,
+		PriceCents:           plan.PriceCents,
+		BillingPeriod:        plan.BillingPeriod,
+		ValidityDays:         fromInt4(plan.ValidityDays),
+		ClassAllowance:       fromInt4(plan.ClassAllowance),
+		MaxFreezesPerYear:    plan.MaxFreezesPerYear,
+		MaxFreezeDaysPerYear: plan.MaxFreezeDaysPerYear,
+	}
+}
+
+func toSubscriptionResponse(subscription repository.Subscription) SubscriptionResponse {
+	return SubscriptionResponse{
+		ID:          subscription.ID,
+		UserID:      subscription.UserID,
+		PlanID:      subscription.PlanID,
+		Status:      subscription.Status,
+		StartsAt:    subscription.StartsAt,
+		EndsAt:      subscription.EndsAt,
+		RenewsAt:    fromTimestamptz(subscription.RenewsAt),
+		PausedAt:    fromTimestamptz(subscription.PausedAt),
+		CancelledAt: fromTimestamptz(subscription.CancelledAt),
+	}
+}
+
+func fromInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func fromTimestamptz(v pgtype.Timestamptz) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
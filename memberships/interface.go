@@ -0,0 +1,26 @@
+package memberships
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IMembershipService interface {
+	GetPlans(ctx context.Context) ([]repository.MembershipPlan, error)
+	CreatePlan(ctx context.Context, plan NewPlan) (*repository.MembershipPlan, error)
+	GetUserSubscriptions(ctx context.Context, userID int32) ([]repository.Subscription, error)
+	AssignSubscription(ctx context.Context, userID, planID int32, startsAt time.Time, assignedBy int32) (*repository.Subscription, error)
+	PauseSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error)
+	ResumeSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error)
+	CancelSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error)
+	RenewSubscription(ctx context.Context, subscriptionID int32, renewedBy int32) (*repository.Subscription, error)
+	ExpireSubscriptions(ctx context.Context) error
+	GetFreezes(ctx context.Context, userID int32) ([]repository.MembershipFreeze, error)
+	RequestFreeze(ctx context.Context, userID int32, startsAt, endsAt time.Time, reason string, requestedBy int32) (*repository.MembershipFreeze, error)
+	EndFreeze(ctx context.Context, freezeID, userID int32) (*repository.MembershipFreeze, error)
+	ApplyFreezes(ctx context.Context) error
+	FlagFrozen(ctx context.Context, userID int32) (context.Context, error)
+	RejectFrozen(ctx context.Context, userID int32) (context.Context, error)
+}
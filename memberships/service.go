@@ -0,0 +1,323 @@
+package memberships
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type BillingPeriod string
+
+const (
+	BillingPeriodMonthly BillingPeriod = "monthly"
+	BillingPeriodYearly  BillingPeriod = "yearly"
+	BillingPeriodOneTime BillingPeriod = "one_time"
+)
+
+const (
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusPaused    = "paused"
+	SubscriptionStatusFrozen    = "frozen"
+	SubscriptionStatusCancelled = "cancelled"
+	SubscriptionStatusExpired   = "expired"
+)
+
+// NewPlan holds the fields staff provide when creating a membership plan.
+type NewPlan struct {
+	Name          string
+	Description   string
+	PriceCents    int32
+	BillingPeriod BillingPeriod
+	// ValidityDays is required for one_time plans and ignored otherwise
+	ValidityDays *int32
+	// ClassAllowance is the number of classes per period, nil means unlimited
+	ClassAllowance *int32
+	// MaxFreezesPerYear and MaxFreezeDaysPerYear limit how often and for how
+	// long members can freeze, nil uses the defaults
+	MaxFreezesPerYear    *int32
+	MaxFreezeDaysPerYear *int32
+}
+
+type MembershipService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+}
+
+func NewMembershipService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+) *MembershipService {
+	return &MembershipService{
+		db:         db,
+		repository: repository,
+	}
+}
+
+var (
+	ErrInvalidPlan                   = errors.New("plan is invalid")
+	ErrPlanDoesntExist               = errors.New("plan does not exist")
+	ErrPlanIsInactive                = errors.New("plan is no longer offered")
+	ErrSubscriptionDoesntExist       = errors.New("subscription does not exist")
+	ErrInvalidSubscriptionTransition = errors.New("subscription can't be changed from its current status")
+)
+
+func (s *MembershipService) GetPlans(ctx context.Context) ([]repository.MembershipPlan, error) {
+	return s.repository.GetActiveMembershipPlans(ctx)
+}
+
+func (s *MembershipService) CreatePlan(ctx context.Context, plan NewPlan) (*repository.MembershipPlan, error) {
+	if plan.Name == "" || plan.PriceCents < 0 {
+		return nil, ErrInvalidPlan
+	}
+
+	switch plan.BillingPeriod {
+	case BillingPeriodMonthly, BillingPeriodYearly:
+		plan.ValidityDays = nil
+	case BillingPeriodOneTime:
+		if plan.ValidityDays == nil || *plan.ValidityDays <= 0 {
+			return nil, fmt.Errorf("%w: one_time plans need a validity", ErrInvalidPlan)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown billing period %q", ErrInvalidPlan, plan.BillingPeriod)
+	}
+
+	if plan.ClassAllowance != nil && *plan.ClassAllowance < 0 {
+		return nil, fmt.Errorf("%w: class allowance can't be negative", ErrInvalidPlan)
+	}
+
+	maxFreezes, maxFreezeDays := int32(defaultMaxFreezesPerYear), int32(defaultMaxFreezeDaysPerYear)
+	if plan.MaxFreezesPerYear != nil {
+		maxFreezes = *plan.MaxFreezesPerYear
+	}
+	if plan.MaxFreezeDaysPerYear != nil {
+		maxFreezeDays = *plan.MaxFreezeDaysPerYear
+	}
+	if maxFreezes < 0 || maxFreezeDays < 0 {
+		return nil, fmt.Errorf("%w: freeze limits can't be negative", ErrInvalidPlan)
+	}
+
+	created, err := s.repository.CreateMembershipPlan(ctx, repository.CreateMembershipPlanParams{
+		Name:                 plan.Name,
+		Description:          plan.Description,
+		PriceCents:           plan.PriceCents,
+		BillingPeriod:        string(plan.BillingPeriod),
+		ValidityDays:         toInt4(plan.ValidityDays),
+		ClassAllowance:       toInt4(plan.ClassAllowance),
+		MaxFreezesPerYear:    maxFreezes,
+		MaxFreezeDaysPerYear: maxFreezeDays,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create membership plan in db: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (s *MembershipService) GetUserSubscriptions(ctx context.Context, userID int32) ([]repository.Subscription, error) {
+	return s.repository.GetSubscriptionsByUserID(ctx, userID)
+}
+
+// AssignSubscription starts a member on a plan. Plans with a class allowance
+// add that many credits, valid until the end of the period.
+func (s *MembershipService) AssignSubscription(ctx context.Context, userID, planID int32, startsAt time.Time, assignedBy int32) (*repository.Subscription, error) {
+	plan, err := s.getPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, ErrPlanIsInactive
+	}
+
+	endsAt := periodEnd(plan, startsAt)
+
+	var subscription repository.Subscription
+	err = repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		subscription, err = q.CreateSubscription(ctx, repository.CreateSubscriptionParams{
+			UserID:    userID,
+			PlanID:    plan.ID,
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+			RenewsAt:  renewalDate(plan, endsAt),
+			CreatedBy: pgtype.Int4{Int32: assignedBy, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create subscription in db: %w", err)
+		}
+
+		return s.grantAllowance(ctx, q, plan, subscription, assignedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (s *MembershipService) PauseSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error) {
+	return s.transition(ctx, subscriptionID, s.repository.PauseSubscription)
+}
+
+func (s *MembershipService) ResumeSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error) {
+	return s.transition(ctx, subscriptionID, s.repository.ResumeSubscription)
+}
+
+func (s *MembershipService) CancelSubscription(ctx context.Context, subscriptionID int32) (*repository.Subscription, error) {
+	return s.transition(ctx, subscriptionID, s.repository.CancelSubscription)
+}
+
+// RenewSubscription extends a subscription by one period, adding the plan's
+// class allowance for it.
+func (s *MembershipService) RenewSubscription(ctx context.Context, subscriptionID int32, renewedBy int32) (*repository.Subscription, error) {
+	subscription, err := s.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.getPlan(ctx, subscription.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Renewing early extends the current period, renewing a lapsed
+	// subscription starts a new one today.
+	from := subscription.EndsAt
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	endsAt := periodEnd(plan, from)
+
+	var renewed repository.Subscription
+	err = repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		renewed, err = q.RenewSubscription(ctx, repository.RenewSubscriptionParams{
+			ID:       subscription.ID,
+			EndsAt:   endsAt,
+			RenewsAt: renewalDate(plan, endsAt),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidSubscriptionTransition
+			}
+			return fmt.Errorf("failed to renew subscription in db: %w", err)
+		}
+
+		return s.grantAllowance(ctx, q, plan, renewed, renewedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &renewed, nil
+}
+
+// ExpireSubscriptions expires the active subscriptions past their end date.
+// It is run periodically.
+func (s *MembershipService) ExpireSubscriptions(ctx context.Context) error {
+	if err := s.repository.ExpireLapsedSubscriptions(ctx); err != nil {
+		return fmt.Errorf("failed to expire subscriptions: %w", err)
+	}
+	return nil
+}
+
+// transition applies a status change query. The queries only match
+// subscriptions in a status the change is allowed from, so no rows means the
+// transition is invalid.
+func (s *MembershipService) transition(
+	ctx context.Context,
+	subscriptionID int32,
+	update func(ctx context.Context, id int32) (repository.Subscription, error),
+) (*repository.Subscription, error) {
+	if _, err := s.getSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	subscription, err := update(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidSubscriptionTransition
+		}
+		return nil, fmt.Errorf("failed to update subscription in db: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// grantAllowance adds a plan's class allowance to the member's credits. The
+// credits expire with the subscription period they were granted for.
+func (s *MembershipService) grantAllowance(
+	ctx context.Context,
+	q *repository.Queries,
+	plan repository.MembershipPlan,
+	subscription repository.Subscription,
+	grantedBy int32,
+) error {
+	if !plan.ClassAllowance.Valid || plan.ClassAllowance.Int32 == 0 {
+		return nil
+	}
+
+	_, err := credits.AddCredits(ctx, q, credits.Grant{
+		UserID:         subscription.UserID,
+		Kind:           credits.EntryKindPurchase,
+		Amount:         plan.ClassAllowance.Int32,
+		ExpiresAt:      &subscription.EndsAt,
+		SubscriptionID: &subscription.ID,
+		Reason:         plan.Name,
+		CreatedBy:      &grantedBy,
+	})
+	return err
+}
+
+func (s *MembershipService) getPlan(ctx context.Context, planID int32) (repository.MembershipPlan, error) {
+	plan, err := s.repository.GetMembershipPlanByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return plan, ErrPlanDoesntExist
+		}
+		return plan, err
+	}
+	return plan, nil
+}
+
+func (s *MembershipService) getSubscription(ctx context.Context, subscriptionID int32) (repository.Subscription, error) {
+	subscription, err := s.repository.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return subscription, ErrSubscriptionDoesntExist
+		}
+		return subscription, err
+	}
+	return subscription, nil
+}
+
+// periodEnd returns the end of a plan's period starting at from.
+func periodEnd(plan repository.MembershipPlan, from time.Time) time.Time {
+	switch BillingPeriod(plan.BillingPeriod) {
+	case BillingPeriodYearly:
+		return from.AddDate(1, 0, 0)
+	case BillingPeriodOneTime:
+		return from.AddDate(0, 0, int(plan.ValidityDays.Int32))
+	default:
+		return from.AddDate(0, 1, 0)
+	}
+}
+
+// renewalDate returns when a subscription ending at endsAt is due for
+// renewal. One-time plans don't renew.
+func renewalDate(plan repository.MembershipPlan, endsAt time.Time) pgtype.Timestamptz {
+	if BillingPeriod(plan.BillingPeriod) == BillingPeriodOneTime {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: endsAt, Valid: true}
+}
+
+func toInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
@@ -2,12 +2,16 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
 	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
 	"github.com/grez-lucas/boxer66-service/internal/repository"
 )
 
@@ -15,38 +19,90 @@ type ContextKey string
 
 var ContextUserKey ContextKey = "user"
 
-func Auth(next http.HandlerFunc) http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenStr := r.Header.Get("jwt-token")
+var (
+	errInvalidToken       = errors.New("token is invalid")
+	errInvalidClaims      = errors.New("token has invalid claims")
+	errInvalidUserIDClaim = errors.New("token has an invalid userID claim")
+)
 
-		token, err := ValidateJWT(tokenStr)
-		if err != nil {
-			slog.Error("Failed to validate JWT", slog.Any("error", err))
-			writeUnauthorized(w)
-			return
-		}
+// AccountCheck is run by Auth once the JWT has been validated. It can reject
+// the request by returning an error, or enrich the request context that is
+// handed to the next handler.
+type AccountCheck func(ctx context.Context, userID int32) (context.Context, error)
 
-		if !token.Valid {
-			slog.Error("Token is invalid", slog.String("token", tokenStr))
-			writeUnauthorized(w)
-			return
-		}
+// RejectError is returned by an AccountCheck to reject a request with a
+// specific status code. Any other error is reported as an internal error.
+type RejectError struct {
+	StatusCode int
+	Err        error
+}
 
-		// check claims
-		claims, ok := token.Claims.(jwt.MapClaims)
+func (e *RejectError) Error() string {
+	return e.Err.Error()
+}
 
-		if !ok {
-			slog.Error("Token has invalid claims")
-			writeUnauthorized(w)
-			return
-		}
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
 
-		// Add the userID to the request context for later use
-		userID := claims["userID"].(int32)
-		ctx := context.WithValue(context.Background(), ContextUserKey, userID)
+func Reject(statusCode int, err error) error {
+	return &RejectError{StatusCode: statusCode, Err: err}
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+func Auth(checks ...AccountCheck) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenStr := r.Header.Get("jwt-token")
+
+			token, err := ValidateJWT(tokenStr)
+			if err != nil {
+				apperror.Write(w, r, apperror.ErrUnauthorized.Wrap(fmt.Errorf("failed to validate JWT: %w", err)))
+				return
+			}
+
+			if !token.Valid {
+				apperror.Write(w, r, apperror.ErrUnauthorized.Wrap(errInvalidToken))
+				return
+			}
+
+			// check claims
+			claims, ok := token.Claims.(jwt.MapClaims)
+
+			if !ok {
+				apperror.Write(w, r, apperror.ErrUnauthorized.Wrap(errInvalidClaims))
+				return
+			}
+
+			// JSON numbers are decoded as float64
+			claimedID, ok := claims["userID"].(float64)
+			if !ok {
+				apperror.Write(w, r, apperror.ErrUnauthorized.Wrap(errInvalidUserIDClaim))
+				return
+			}
+			userID := int32(claimedID)
+
+			// Add the userID to the request context for later use
+			ctx := context.WithValue(r.Context(), ContextUserKey, userID)
+			ctx = logging.WithAttrs(ctx, slog.Int("user_id", int(userID)))
+
+			for _, check := range checks {
+				checked, err := check(ctx, userID)
+				if err != nil {
+					writeRejected(w, r.WithContext(ctx), err)
+					return
+				}
+				ctx = checked
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserIDFromContext returns the ID of the user authenticated by Auth.
+func UserIDFromContext(ctx context.Context) (int32, bool) {
+	userID, ok := ctx.Value(ContextUserKey).(int32)
+	return userID, ok
 }
 
 func CreateJWT(user *repository.User) (string, error) {
@@ -72,7 +128,15 @@ func ValidateJWT(tokenStr string) (*jwt.Token, error) {
 	})
 }
 
-func writeUnauthorized(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusUnauthorized)
-	w.Write([]byte(http.StatusText(http.StatusUnauthorized)))
+// writeRejected answers a failed AccountCheck. A RejectError keeps its status
+// code and message, anything else is an internal error.
+func writeRejected(w http.ResponseWriter, r *http.Request, err error) {
+	var rejectErr *RejectError
+	if !errors.As(err, &rejectErr) {
+		apperror.Write(w, r, err)
+		return
+	}
+
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(rejectErr.StatusCode)), " ", "_")
+	apperror.Write(w, r, apperror.New(rejectErr.StatusCode, code, rejectErr.Error()).Wrap(err))
 }
@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"slices"
 	"strings"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
 )
 
 var (
@@ -16,7 +17,7 @@ var (
 func Cors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if isPreflight(r) {
-			fmt.Println("Detected pre-flight request")
+			logging.FromContext(r.Context()).Debug("Detected pre-flight request")
 			origin := r.Header.Get("Origin")
 			method := r.Header.Get("Access-Control-Request-Method")
 			if slices.Contains(originAllowList, origin) && slices.Contains(methodAllowList, method) {
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+)
+
+// ClientIP returns the address of the client that sent the request, or the
+// zero netip.Addr if RemoteAddr can't be parsed.
+func ClientIP(r *http.Request) netip.Addr {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		addr, _ := netip.ParseAddr(r.RemoteAddr)
+		return addr
+	}
+	return addrPort.Addr()
+}
@@ -1,11 +1,11 @@
 package middleware
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
-	"os"
 	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
 )
 
 type loggingResponseWriter struct {
@@ -29,27 +29,36 @@ func (lrw *loggingResponseWriter) Header() http.Header {
 	return lrw.ResponseWriter.Header()
 }
 
+// unloggedRoutes are polled by the orchestrator and the metrics scraper and would drown out real
+// requests. They are matched on the route pattern, so they stay unlogged under /api too.
+var unloggedRoutes = map[string]bool{
+	"GET /healthz": true,
+	"GET /readyz":  true,
+	"GET /version": true,
+	"GET /metrics": true,
+}
+
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		logOpts := &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		}
-		handler := slog.NewJSONHandler(os.Stderr, logOpts)
-		logger := slog.New(handler)
-
 		lrw := &loggingResponseWriter{
 			ResponseWriter: w,
 			statusCode:     http.StatusOK, // Default status code
 		}
 
+		r, rt := withRoute(r)
 		next.ServeHTTP(lrw, r)
 
+		if unloggedRoutes[rt.pattern] {
+			return
+		}
+
 		logAttrs := []slog.Attr{
 			slog.Group("request",
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
+				slog.String("route", rt.pattern),
 				slog.String("query", r.URL.RawQuery),
 				slog.String("remote_addr", r.RemoteAddr),
 				slog.String("user_agent", r.UserAgent()),
@@ -61,10 +70,11 @@ func Logging(next http.Handler) http.Handler {
 			),
 		}
 
+		logger := logging.FromContext(r.Context())
 		if lrw.statusCode == http.StatusOK {
-			logger.LogAttrs(context.Background(), slog.LevelInfo, "API Request", logAttrs...)
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "API Request", logAttrs...)
 		} else {
-			logger.LogAttrs(context.Background(), slog.LevelWarn, "API Request Error", logAttrs...)
+			logger.LogAttrs(r.Context(), slog.LevelWarn, "API Request Error", logAttrs...)
 		}
 	})
 }
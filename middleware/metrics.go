@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/metrics"
+)
+
+// Metrics records request durations labeled by the route pattern the mux
+// matched, so paths with IDs don't each get their own series.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		lrw := &loggingResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		r, rt := withRoute(r)
+		next.ServeHTTP(lrw, r)
+
+		pattern := rt.pattern
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(r.Method, pattern, strconv.Itoa(lrw.statusCode)).
+			Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var ContextRequestIDKey ContextKey = "request_id"
+
+// maxRequestIDLength keeps clients from stuffing arbitrary data into every
+// log line.
+const maxRequestIDLength = 128
+
+// RequestID reuses the caller's X-Request-ID or generates one, echoes it in
+// the response and adds it to the request logger.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), ContextRequestIDKey, id)
+		ctx = logging.WithAttrs(ctx, slog.String("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the ID assigned by RequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ContextRequestIDKey).(string)
+	return id, ok
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
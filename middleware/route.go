@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+type routeKey struct{}
+
+// route holds the pattern of the route a request matched. It is shared
+// through the request context, so middlewares around the mux see the pattern
+// the innermost mux matched, even after http.StripPrefix replaced the request.
+type route struct {
+	pattern string
+}
+
+// withRoute returns a request that carries a route holder, reusing the one an
+// outer middleware already added.
+func withRoute(r *http.Request) (*http.Request, *route) {
+	if rt, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return r, rt
+	}
+	rt := &route{}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, rt)), rt
+}
+
+// SetRoute records the route pattern the request matched. The router calls it
+// once the mux has picked a handler. It does nothing if no middleware is
+// waiting for the pattern.
+func SetRoute(ctx context.Context, pattern string) {
+	if rt, ok := ctx.Value(routeKey{}).(*route); ok {
+		rt.pattern = pattern
+	}
+}
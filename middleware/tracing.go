@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/grez-lucas/boxer66-service/middleware")
+
+// Tracing starts a server span for each request, continuing the trace from an
+// incoming W3C traceparent header if there is one.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("user_agent.original", r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if spanCtx := span.SpanContext(); spanCtx.IsSampled() {
+			ctx = logging.WithAttrs(ctx, slog.String("trace_id", spanCtx.TraceID().String()))
+		}
+
+		// Hand the trace back so clients can look it up
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		lrw := &loggingResponseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		r, rt := withRoute(r.WithContext(ctx))
+		next.ServeHTTP(lrw, r)
+
+		if rt.pattern != "" {
+			span.SetName(rt.pattern)
+			span.SetAttributes(attribute.String("http.route", rt.pattern))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", lrw.statusCode))
+		if lrw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("responded with %d", lrw.statusCode))
+		}
+	})
+}
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_status_transitions;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS status_changed_at,
+  DROP COLUMN IF EXISTS status_reason,
+  DROP COLUMN IF EXISTS status;
@@ -0,0 +1,18 @@
+ALTER TABLE users
+  ADD COLUMN status VARCHAR NOT NULL DEFAULT 'active'
+    CHECK (status IN ('pending', 'active', 'suspended', 'banned', 'deleted')),
+  ADD COLUMN status_reason VARCHAR,
+  ADD COLUMN status_changed_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX ON users(status);
+
+CREATE TABLE IF NOT EXISTS user_status_transitions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  from_status VARCHAR,
+  to_status VARCHAR NOT NULL,
+  reason VARCHAR,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON user_status_transitions(user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pending_legal_acceptances;
+DROP TABLE IF EXISTS legal_acceptances;
+DROP TABLE IF EXISTS legal_documents;
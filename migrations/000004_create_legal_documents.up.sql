@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS legal_documents (
+  id SERIAL PRIMARY KEY,
+  kind VARCHAR NOT NULL CHECK (kind IN ('terms', 'waiver')),
+  version INTEGER NOT NULL,
+  title VARCHAR NOT NULL,
+  body TEXT NOT NULL,
+  published_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (kind, version)
+);
+
+CREATE TABLE IF NOT EXISTS legal_acceptances (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  document_id INTEGER NOT NULL REFERENCES legal_documents(id),
+  ip_address INET NOT NULL,
+  accepted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, document_id)
+);
+
+-- Acceptances given during registration, before the users row exists. They
+-- are moved to legal_acceptances once the email has been verified.
+CREATE TABLE IF NOT EXISTS pending_legal_acceptances (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR NOT NULL,
+  document_id INTEGER NOT NULL REFERENCES legal_documents(id),
+  ip_address INET NOT NULL,
+  accepted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (email, document_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+  ADD COLUMN role VARCHAR NOT NULL DEFAULT 'member'
+    CHECK (role IN ('member', 'coach', 'staff'));
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS membership_plans (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  price_cents INTEGER NOT NULL CHECK (price_cents >= 0),
+  billing_period VARCHAR NOT NULL CHECK (billing_period IN ('monthly', 'yearly', 'one_time')),
+  -- How long a one_time plan is valid for, unused for recurring plans
+  validity_days INTEGER CHECK (validity_days > 0),
+  -- Number of classes included per period, NULL means unlimited
+  class_allowance INTEGER CHECK (class_allowance >= 0),
+  is_active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (billing_period <> 'one_time' OR validity_days IS NOT NULL)
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  plan_id INTEGER NOT NULL REFERENCES membership_plans(id),
+  status VARCHAR NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'paused', 'cancelled', 'expired')),
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  -- When the next renewal is due, NULL for one_time plans and cancelled subscriptions
+  renews_at TIMESTAMPTZ,
+  paused_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX ON subscriptions(user_id);
+CREATE INDEX ON subscriptions(status);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS closures;
+DROP TABLE IF EXISTS class_schedule_exceptions;
+DROP TABLE IF EXISTS class_schedules;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS class_types;
@@ -0,0 +1,51 @@
+CREATE TABLE IF NOT EXISTS class_types (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS rooms (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR NOT NULL UNIQUE,
+  capacity INTEGER NOT NULL CHECK (capacity > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A recurring class. rrule holds an RFC 5545 RRULE (without DTSTART) that is
+-- expanded from dtstart in the schedule's IANA timezone, so a 7pm class stays
+-- at 7pm local time across DST changes.
+CREATE TABLE IF NOT EXISTS class_schedules (
+  id SERIAL PRIMARY KEY,
+  class_type_id INTEGER NOT NULL REFERENCES class_types(id),
+  room_id INTEGER NOT NULL REFERENCES rooms(id),
+  coach_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  dtstart TIMESTAMPTZ NOT NULL,
+  rrule VARCHAR NOT NULL,
+  timezone VARCHAR NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One-off cancellations of a single occurrence (RFC 5545 EXDATE).
+CREATE TABLE IF NOT EXISTS class_schedule_exceptions (
+  id SERIAL PRIMARY KEY,
+  schedule_id INTEGER NOT NULL REFERENCES class_schedules(id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  reason VARCHAR NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (schedule_id, starts_at)
+);
+
+-- Periods the whole gym is closed, such as holidays.
+CREATE TABLE IF NOT EXISTS closures (
+  id SERIAL PRIMARY KEY,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason VARCHAR NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX ON closures(starts_at, ends_at);
@@ -0,0 +1,30 @@
+-- A concrete occurrence of a class schedule that members can book.
+CREATE TABLE IF NOT EXISTS class_sessions (
+  id SERIAL PRIMARY KEY,
+  schedule_id INTEGER NOT NULL REFERENCES class_schedules(id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  capacity INTEGER NOT NULL CHECK (capacity > 0),
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (schedule_id, starts_at),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX ON class_sessions(starts_at);
+
+CREATE TABLE IF NOT EXISTS bookings (
+  id SERIAL PRIMARY KEY,
+  session_id INTEGER NOT NULL REFERENCES class_sessions(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR NOT NULL CHECK (status IN ('booked', 'waitlisted', 'cancelled')),
+  promoted_at TIMESTAMPTZ,
+  cancelled_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- A member holds at most one spot or waitlist entry per session
+CREATE UNIQUE INDEX ON bookings(session_id, user_id) WHERE status <> 'cancelled';
+CREATE INDEX ON bookings(session_id, status, created_at);
+CREATE INDEX ON bookings(user_id);
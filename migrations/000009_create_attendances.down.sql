@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS attendances;
+
+ALTER TABLE users DROP COLUMN IF EXISTS photo_url;
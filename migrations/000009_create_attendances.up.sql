@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN photo_url VARCHAR;
+
+CREATE TABLE IF NOT EXISTS attendances (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  session_id INTEGER REFERENCES class_sessions(id) ON DELETE SET NULL,
+  source VARCHAR NOT NULL CHECK (source IN ('qr', 'manual')),
+  recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  checked_in_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON attendances(user_id, checked_in_at);
+CREATE INDEX ON attendances(session_id);
@@ -0,0 +1 @@
+ALTER TABLE attendances DROP COLUMN IF EXISTS class_type_id;
@@ -0,0 +1,10 @@
+ALTER TABLE attendances
+  ADD COLUMN class_type_id INTEGER REFERENCES class_types(id) ON DELETE SET NULL;
+
+UPDATE attendances
+SET class_type_id = class_schedules.class_type_id
+FROM class_sessions
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+WHERE class_sessions.id = attendances.session_id;
+
+CREATE INDEX ON attendances(class_type_id);
@@ -0,0 +1,38 @@
+-- Append-only ledger of class credits. Positive entries without a lot_id are
+-- lots (purchases or credits given by staff). Every other entry draws from or
+-- gives back to a lot, so a lot's remaining credits are its own delta plus the
+-- deltas of the entries that reference it, and the balance is SUM(delta).
+CREATE TABLE IF NOT EXISTS credit_ledger (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind VARCHAR NOT NULL CHECK (kind IN ('purchase', 'consumption', 'refund', 'adjustment', 'expiration')),
+  delta INTEGER NOT NULL CHECK (delta <> 0),
+  lot_id INTEGER REFERENCES credit_ledger(id),
+  expires_at TIMESTAMPTZ,
+  subscription_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL,
+  attendance_id INTEGER REFERENCES attendances(id) ON DELETE SET NULL,
+  refund_of INTEGER UNIQUE REFERENCES credit_ledger(id),
+  reason VARCHAR NOT NULL DEFAULT '',
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((lot_id IS NULL) = (delta > 0 AND kind IN ('purchase', 'adjustment'))),
+  CHECK (kind <> 'refund' OR refund_of IS NOT NULL)
+);
+
+CREATE INDEX ON credit_ledger(user_id, created_at);
+CREATE INDEX ON credit_ledger(lot_id);
+
+-- Entries are never changed, corrections are new entries. Changes made by
+-- foreign key actions run inside a trigger and are let through.
+CREATE OR REPLACE FUNCTION credit_ledger_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  IF pg_trigger_depth() = 1 THEN
+    RAISE EXCEPTION 'credit_ledger is append-only';
+  END IF;
+  RETURN CASE WHEN TG_OP = 'DELETE' THEN OLD ELSE NEW END;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER credit_ledger_append_only
+BEFORE UPDATE OR DELETE ON credit_ledger
+FOR EACH ROW EXECUTE FUNCTION credit_ledger_append_only();
@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS penalties;
+
+ALTER TABLE credit_ledger DISABLE TRIGGER credit_ledger_append_only;
+DELETE FROM credit_ledger WHERE refund_of IN (SELECT id FROM credit_ledger WHERE kind = 'penalty');
+DELETE FROM credit_ledger WHERE kind = 'penalty';
+ALTER TABLE credit_ledger ENABLE TRIGGER credit_ledger_append_only;
+ALTER TABLE credit_ledger DROP CONSTRAINT credit_ledger_kind_check;
+ALTER TABLE credit_ledger
+  ADD CONSTRAINT credit_ledger_kind_check CHECK (kind IN ('purchase', 'consumption', 'refund', 'adjustment', 'expiration'));
+
+UPDATE bookings SET status = 'booked' WHERE status IN ('attended', 'no_show');
+ALTER TABLE bookings DROP CONSTRAINT bookings_status_check;
+ALTER TABLE bookings
+  DROP COLUMN checked_in_at,
+  DROP COLUMN late_cancellation,
+  ADD CONSTRAINT bookings_status_check CHECK (status IN ('booked', 'waitlisted', 'cancelled'));
+
+ALTER TABLE class_types
+  DROP COLUMN cancellation_window_minutes,
+  DROP COLUMN late_cancel_penalty,
+  DROP COLUMN no_show_penalty,
+  DROP COLUMN penalty_fee_cents;
@@ -0,0 +1,35 @@
+ALTER TABLE class_types
+  ADD COLUMN cancellation_window_minutes INTEGER NOT NULL DEFAULT 0 CHECK (cancellation_window_minutes >= 0),
+  ADD COLUMN late_cancel_penalty VARCHAR NOT NULL DEFAULT 'none' CHECK (late_cancel_penalty IN ('none', 'credit', 'fee')),
+  ADD COLUMN no_show_penalty VARCHAR NOT NULL DEFAULT 'none' CHECK (no_show_penalty IN ('none', 'credit', 'fee')),
+  ADD COLUMN penalty_fee_cents INTEGER NOT NULL DEFAULT 0 CHECK (penalty_fee_cents >= 0);
+
+ALTER TABLE bookings DROP CONSTRAINT bookings_status_check;
+ALTER TABLE bookings
+  ADD CONSTRAINT bookings_status_check CHECK (status IN ('booked', 'waitlisted', 'cancelled', 'attended', 'no_show')),
+  ADD COLUMN checked_in_at TIMESTAMPTZ,
+  ADD COLUMN late_cancellation BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE credit_ledger DROP CONSTRAINT credit_ledger_kind_check;
+ALTER TABLE credit_ledger
+  ADD CONSTRAINT credit_ledger_kind_check CHECK (kind IN ('purchase', 'consumption', 'refund', 'adjustment', 'expiration', 'penalty'));
+
+-- Penalties for late cancellations and no-shows. A warning records an offense
+-- that carried no penalty, it still counts towards a booking ban.
+CREATE TABLE IF NOT EXISTS penalties (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+  reason VARCHAR NOT NULL CHECK (reason IN ('late_cancellation', 'no_show')),
+  kind VARCHAR NOT NULL CHECK (kind IN ('warning', 'credit', 'fee', 'ban')),
+  fee_cents INTEGER NOT NULL DEFAULT 0 CHECK (fee_cents >= 0),
+  credit_entry_id INTEGER REFERENCES credit_ledger(id),
+  banned_until TIMESTAMPTZ,
+  waived_at TIMESTAMPTZ,
+  waived_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK ((kind = 'ban') = (banned_until IS NOT NULL))
+);
+
+CREATE INDEX ON penalties(user_id, created_at);
+CREATE INDEX ON penalties(user_id, banned_until) WHERE kind = 'ban';
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS membership_freezes;
+
+UPDATE subscriptions SET status = 'active' WHERE status = 'frozen';
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_status_check;
+ALTER TABLE subscriptions
+  ADD CONSTRAINT subscriptions_status_check CHECK (status IN ('active', 'paused', 'cancelled', 'expired'));
+
+ALTER TABLE membership_plans
+  DROP COLUMN max_freezes_per_year,
+  DROP COLUMN max_freeze_days_per_year;
@@ -0,0 +1,31 @@
+ALTER TABLE membership_plans
+  ADD COLUMN max_freezes_per_year INTEGER NOT NULL DEFAULT 2 CHECK (max_freezes_per_year >= 0),
+  ADD COLUMN max_freeze_days_per_year INTEGER NOT NULL DEFAULT 60 CHECK (max_freeze_days_per_year >= 0);
+
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_status_check;
+ALTER TABLE subscriptions
+  ADD CONSTRAINT subscriptions_status_check CHECK (status IN ('active', 'paused', 'frozen', 'cancelled', 'expired'));
+
+-- A member's request to freeze their subscription between two dates. The
+-- subscription end date is pushed back by the length of the freeze when it is
+-- requested, and pulled in again if the freeze is cancelled or ended early.
+CREATE TABLE IF NOT EXISTS membership_freezes (
+  id SERIAL PRIMARY KEY,
+  subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR NOT NULL DEFAULT 'scheduled'
+    CHECK (status IN ('scheduled', 'active', 'completed', 'cancelled')),
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  -- When the freeze actually ended, earlier than ends_at if it was cut short
+  ended_at TIMESTAMPTZ,
+  reason VARCHAR NOT NULL DEFAULT '',
+  requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX ON membership_freezes(user_id, starts_at);
+CREATE INDEX ON membership_freezes(status, starts_at);
+CREATE INDEX ON membership_freezes(subscription_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS coach_availability_exceptions;
+DROP TABLE IF EXISTS coach_availability;
+DROP TABLE IF EXISTS coach_profiles;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS coach_profiles (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  bio TEXT NOT NULL DEFAULT '',
+  specialties TEXT[] NOT NULL DEFAULT '{}',
+  certifications TEXT[] NOT NULL DEFAULT '{}',
+  -- Availability windows are wall-clock times in this timezone
+  timezone VARCHAR NOT NULL DEFAULT 'UTC',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Recurring weekly windows in which a coach takes 1:1 sessions. Weekdays
+-- follow Go's time.Weekday, 0 being Sunday.
+CREATE TABLE IF NOT EXISTS coach_availability (
+  id SERIAL PRIMARY KEY,
+  coach_id INTEGER NOT NULL REFERENCES coach_profiles(user_id) ON DELETE CASCADE,
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  start_time TIME NOT NULL,
+  end_time TIME NOT NULL,
+  CHECK (end_time > start_time)
+);
+
+CREATE INDEX ON coach_availability(coach_id, weekday);
+
+-- Time a coach is unavailable despite their weekly windows, e.g. holidays.
+CREATE TABLE IF NOT EXISTS coach_availability_exceptions (
+  id SERIAL PRIMARY KEY,
+  coach_id INTEGER NOT NULL REFERENCES coach_profiles(user_id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason VARCHAR NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX ON coach_availability_exceptions(coach_id, starts_at);
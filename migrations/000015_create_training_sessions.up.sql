@@ -0,0 +1,35 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- 1:1 training sessions between a member and a coach. A session starts out
+-- as a request the coach accepts or declines. Either side can propose a new
+-- time, which the other side accepts or declines.
+CREATE TABLE IF NOT EXISTS training_sessions (
+  id SERIAL PRIMARY KEY,
+  coach_id INTEGER NOT NULL REFERENCES coach_profiles(user_id) ON DELETE CASCADE,
+  member_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR NOT NULL DEFAULT 'requested'
+    CHECK (status IN ('requested', 'confirmed', 'declined', 'cancelled')),
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  decline_reason TEXT NOT NULL DEFAULT '',
+  proposed_starts_at TIMESTAMPTZ,
+  proposed_ends_at TIMESTAMPTZ,
+  proposed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  cancelled_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  reminder_sent_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (ends_at > starts_at),
+  CHECK (coach_id <> member_id),
+  CHECK ((proposed_starts_at IS NULL) = (proposed_ends_at IS NULL)),
+  CHECK (proposed_ends_at IS NULL OR proposed_ends_at > proposed_starts_at),
+  -- Requests hold the slot too, so two members can't ask for the same time
+  CONSTRAINT training_sessions_no_double_booking EXCLUDE USING gist (
+    coach_id WITH =,
+    tstzrange(starts_at, ends_at) WITH &&
+  ) WHERE (status IN ('requested', 'confirmed'))
+);
+
+CREATE INDEX ON training_sessions(member_id, starts_at);
+CREATE INDEX ON training_sessions(starts_at) WHERE status = 'confirmed' AND reminder_sent_at IS NULL;
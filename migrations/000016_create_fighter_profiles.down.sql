@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bouts;
+DROP TABLE IF EXISTS fighter_profiles;
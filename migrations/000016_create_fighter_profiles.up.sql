@@ -0,0 +1,43 @@
+-- Competition profiles of members who fight. Profiles are private unless the
+-- fighter lists them publicly, and even then measurements and individual
+-- bouts can be kept hidden. Record totals are always shown on listed profiles.
+CREATE TABLE IF NOT EXISTS fighter_profiles (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  display_name VARCHAR NOT NULL CHECK (display_name <> ''),
+  weight_class VARCHAR NOT NULL CHECK (weight_class IN (
+    'minimumweight', 'light_flyweight', 'flyweight', 'super_flyweight',
+    'bantamweight', 'super_bantamweight', 'featherweight', 'super_featherweight',
+    'lightweight', 'super_lightweight', 'welterweight', 'super_welterweight',
+    'middleweight', 'super_middleweight', 'light_heavyweight', 'cruiserweight',
+    'heavyweight'
+  )),
+  height_cm INTEGER CHECK (height_cm BETWEEN 100 AND 250),
+  reach_cm INTEGER CHECK (reach_cm BETWEEN 100 AND 250),
+  stance VARCHAR NOT NULL DEFAULT 'orthodox' CHECK (stance IN ('orthodox', 'southpaw', 'switch')),
+  level VARCHAR NOT NULL DEFAULT 'amateur' CHECK (level IN ('amateur', 'pro')),
+  public BOOLEAN NOT NULL DEFAULT false,
+  show_measurements BOOLEAN NOT NULL DEFAULT true,
+  show_bouts BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS bouts (
+  id SERIAL PRIMARY KEY,
+  fighter_id INTEGER NOT NULL REFERENCES fighter_profiles(user_id) ON DELETE CASCADE,
+  opponent VARCHAR NOT NULL CHECK (opponent <> ''),
+  event VARCHAR NOT NULL DEFAULT '',
+  bout_date DATE NOT NULL,
+  -- Whether the bout was fought as an amateur or a pro, which can differ
+  -- from the fighter's current level
+  level VARCHAR NOT NULL CHECK (level IN ('amateur', 'pro')),
+  result VARCHAR NOT NULL CHECK (result IN ('win', 'loss', 'draw', 'no_contest')),
+  method VARCHAR NOT NULL CHECK (method IN ('decision', 'ko', 'tko', 'rsc', 'dq', 'retirement', 'walkover')),
+  -- The round the bout ended in
+  round INTEGER CHECK (round BETWEEN 1 AND 12),
+  notes TEXT NOT NULL DEFAULT '',
+  recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON bouts(fighter_id, bout_date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS weight_targets;
+DROP TABLE IF EXISTS weigh_ins;
@@ -0,0 +1,26 @@
+-- Body metrics members log while making weight. Weights are stored in kg.
+CREATE TABLE IF NOT EXISTS weigh_ins (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  weight_kg DOUBLE PRECISION NOT NULL CHECK (weight_kg BETWEEN 20 AND 300),
+  body_fat_percent DOUBLE PRECISION CHECK (body_fat_percent BETWEEN 2 AND 70),
+  measured_at TIMESTAMPTZ NOT NULL,
+  source VARCHAR NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'smart_scale', 'coach')),
+  notes TEXT NOT NULL DEFAULT '',
+  recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ON weigh_ins(user_id, measured_at);
+
+-- The weight a member has to make for an upcoming fight. coach_id is alerted
+-- once when the projected cut on fight day becomes unsafe.
+CREATE TABLE IF NOT EXISTS weight_targets (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  target_kg DOUBLE PRECISION NOT NULL CHECK (target_kg BETWEEN 20 AND 300),
+  fight_date DATE NOT NULL,
+  coach_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  alerted_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
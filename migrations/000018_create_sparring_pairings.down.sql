@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sparring_pairings;
+
+ALTER TABLE fighter_profiles DROP COLUMN IF EXISTS experience;
@@ -0,0 +1,21 @@
+-- Sparring experience is how much a fighter has sparred, which a bout record
+-- doesn't capture
+ALTER TABLE fighter_profiles
+  ADD COLUMN experience VARCHAR NOT NULL DEFAULT 'beginner'
+    CHECK (experience IN ('beginner', 'intermediate', 'advanced'));
+
+-- Sparring pairs coaches used in a session, kept so the same fighters aren't
+-- paired over and over. fighter_a_id is always the lower ID.
+CREATE TABLE IF NOT EXISTS sparring_pairings (
+  id SERIAL PRIMARY KEY,
+  session_id INTEGER NOT NULL REFERENCES class_sessions(id) ON DELETE CASCADE,
+  fighter_a_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  fighter_b_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (fighter_a_id < fighter_b_id),
+  UNIQUE (session_id, fighter_a_id, fighter_b_id)
+);
+
+CREATE INDEX ON sparring_pairings(fighter_a_id, created_at);
+CREATE INDEX ON sparring_pairings(fighter_b_id, created_at);
@@ -0,0 +1,3 @@
+-- The original case of the emails is not restored.
+DROP INDEX IF EXISTS users_lower_email_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_idx ON users(email);
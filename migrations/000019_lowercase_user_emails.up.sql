@@ -0,0 +1,46 @@
+-- The API stores emails lowercased. Accounts whose emails only differ in case
+-- keep the oldest one; the others move to a placeholder address so staff can
+-- merge or remove them. Active duplicates are also suspended. Other statuses
+-- are kept, since they can't move to suspended: pending and banned accounts
+-- already can't log in, and deleted is terminal.
+CREATE TEMPORARY TABLE duplicate_users AS
+SELECT id FROM (
+  SELECT id, row_number() OVER (PARTITION BY lower(email) ORDER BY id) AS rank
+  FROM users
+) ranked
+WHERE rank > 1;
+
+INSERT INTO user_status_transitions (user_id, from_status, to_status, reason)
+SELECT users.id, users.status, 'suspended', 'duplicate email'
+FROM users
+JOIN duplicate_users ON duplicate_users.id = users.id
+WHERE users.status = 'active';
+
+UPDATE users
+SET status = 'suspended',
+  status_reason = 'duplicate email',
+  status_changed_at = now()
+FROM duplicate_users
+WHERE duplicate_users.id = users.id AND users.status = 'active';
+
+UPDATE users
+SET email = 'duplicate-' || users.id || '+' || lower(users.email),
+  updated_at = now()
+FROM duplicate_users
+WHERE duplicate_users.id = users.id;
+
+DROP TABLE duplicate_users;
+
+UPDATE users SET email = lower(email) WHERE email <> lower(email);
+
+DROP INDEX IF EXISTS users_email_idx;
+CREATE UNIQUE INDEX users_lower_email_idx ON users(lower(email));
+
+UPDATE email_verification_tokens SET email = lower(email) WHERE email <> lower(email);
+
+DELETE FROM pending_legal_acceptances
+WHERE id NOT IN (
+  SELECT min(id) FROM pending_legal_acceptances
+  GROUP BY lower(email), document_id
+);
+UPDATE pending_legal_acceptances SET email = lower(email) WHERE email <> lower(email);
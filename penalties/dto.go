@@ -0,0 +1,16 @@
+package penalties
+
+import "time"
+
+type PenaltyResponse struct {
+	ID            int32      `json:"id"`
+	UserID        int32      `json:"user_id"`
+	BookingID     int32      `json:"booking_id"`
+	Reason        string     `json:"reason"`
+	Kind          string     `json:"kind"`
+	FeeCents      int32      `json:"fee_cents"`
+	CreditEntryID *int32     `json:"credit_entry_id"`
+	BannedUntil   *time.Time `json:"banned_until"`
+	WaivedAt      *time.Time `json:"waived_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
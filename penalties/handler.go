@@ -0,0 +1,105 @@
+package penalties
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type PenaltyHandlers struct {
+	pService IPenaltyService
+}
+
+func NewPenaltyHandlers(pService IPenaltyService) *PenaltyHandlers {
+	return &PenaltyHandlers{
+		pService: pService,
+	}
+}
+
+func (h *PenaltyHandlers) GetMyPenalties(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writePenalties(w, r, userID)
+}
+
+func (h *PenaltyHandlers) GetUserPenalties(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writePenalties(w, r, userID)
+}
+
+func (h *PenaltyHandlers) WaivePenalty(w http.ResponseWriter, r *http.Request) error {
+	staffID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	penaltyID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Penalty ID is invalid")
+	}
+
+	penalty, err := h.pService.Waive(r.Context(), penaltyID, staffID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrPenaltyDoesntExist):
+			return apperror.New(http.StatusNotFound, "penalty_doesnt_exist", "Penalty does not exist").Wrap(err)
+		case errors.Is(err, ErrAlreadyWaived):
+			return apperror.New(http.StatusConflict, "already_waived", "Penalty is already waived").Wrap(err)
+		default:
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toPenaltyResponse(*penalty), http.StatusOK)
+	return nil
+}
+
+func (h *PenaltyHandlers) writePenalties(w http.ResponseWriter, r *http.Request, userID int32) error {
+	penalties, err := h.pService.GetPenalties(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]PenaltyResponse, 0, len(penalties))
+	for _, penalty := range penalties {
+		resp = append(resp, toPenaltyResponse(penalty))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func toPenaltyResponse(penalty repository.Penalty) PenaltyResponse {
+	resp := PenaltyResponse{
+		ID:        penalty.ID,
+		UserID:    penalty.UserID,
+		BookingID: penalty.BookingID,
+		Reason:    penalty.Reason,
+		Kind:      penalty.Kind,
+		FeeCents:  penalty.FeeCents,
+		CreatedAt: penalty.CreatedAt,
+	}
+	if penalty.CreditEntryID.Valid {
+		resp.CreditEntryID = &penalty.CreditEntryID.Int32
+	}
+	if penalty.BannedUntil.Valid {
+		resp.BannedUntil = &penalty.BannedUntil.Time
+	}
+	if penalty.WaivedAt.Valid {
+		resp.WaivedAt = &penalty.WaivedAt.Time
+	}
+	return resp
+}
@@ -0,0 +1,14 @@
+package penalties
+
+import (
+	"context"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IPenaltyService interface {
+	MarkNoShows(ctx context.Context) ([]Notice, error)
+	GetPenalties(ctx context.Context, userID int32) ([]repository.Penalty, error)
+	Waive(ctx context.Context, penaltyID, staffID int32) (*repository.Penalty, error)
+	RejectBanned(ctx context.Context, userID int32) (context.Context, error)
+}
@@ -0,0 +1,36 @@
+package penalties
+
+import (
+	"context"
+
+	"github.com/grez-lucas/boxer66-service/smtp"
+)
+
+// NoShowJob marks no-shows for sessions that have ended and emails the members
+// about their penalties.
+type NoShowJob struct {
+	pService    IPenaltyService
+	smtpService smtp.ISMTPService
+}
+
+func NewNoShowJob(
+	pService IPenaltyService,
+	smtpService smtp.ISMTPService,
+) *NoShowJob {
+	return &NoShowJob{
+		pService:    pService,
+		smtpService: smtpService,
+	}
+}
+
+func (j *NoShowJob) Run(ctx context.Context) error {
+	notices, err := j.pService.MarkNoShows(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, notice := range notices {
+		Notify(ctx, j.smtpService, notice)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package penalties
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/smtp"
+)
+
+// Notify emails a member about their penalties. Failures are logged, the
+// penalties stand and are listed in the app either way.
+func Notify(ctx context.Context, smtpService smtp.ISMTPService, notice Notice) {
+	if err := smtpService.SendPenaltyEmail(ctx, notice.Email, notice.ClassName, notice.StartsAt, Explain(notice)); err != nil {
+		logging.FromContext(ctx).Error("Failed to send penalty email", slog.Any("error", err))
+	}
+}
+
+// Explain describes an offense and its penalties in plain words, one sentence
+// per line.
+func Explain(notice Notice) []string {
+	var lines []string
+
+	switch notice.Reason {
+	case ReasonLateCancellation:
+		lines = append(lines, "You cancelled after the cancellation window for this class had closed, so the spot could not be offered to someone else in time.")
+	case ReasonNoShow:
+		lines = append(lines, "You were booked for this class but didn't check in.")
+	}
+
+	for _, penalty := range notice.Penalties {
+		lines = append(lines, explainPenalty(penalty))
+	}
+
+	return lines
+}
+
+func explainPenalty(penalty repository.Penalty) string {
+	switch penalty.Kind {
+	case KindCredit:
+		return "One class credit was deducted from your balance."
+	case KindFee:
+		return fmt.Sprintf("A fee of %d.%02d was added to your account.", penalty.FeeCents/100, penalty.FeeCents%100)
+	case KindBan:
+		return fmt.Sprintf(
+			"Because of repeated no-shows you can't book classes until %s.",
+			penalty.BannedUntil.Time.UTC().Format(time.RFC1123),
+		)
+	default:
+		return "No penalty was applied this time, but repeated no-shows will suspend your bookings."
+	}
+}
@@ -0,0 +1,291 @@
+package penalties
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/credits"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Policy is what a class type charges for a late cancellation or a no-show.
+type Policy string
+
+const (
+	PolicyNone   Policy = "none"
+	PolicyCredit Policy = "credit"
+	PolicyFee    Policy = "fee"
+)
+
+func (p Policy) IsValid() bool {
+	switch p {
+	case PolicyNone, PolicyCredit, PolicyFee:
+		return true
+	}
+	return false
+}
+
+type Reason string
+
+const (
+	ReasonLateCancellation Reason = "late_cancellation"
+	ReasonNoShow           Reason = "no_show"
+)
+
+const (
+	KindWarning = "warning"
+	KindCredit  = "credit"
+	KindFee     = "fee"
+	KindBan     = "ban"
+)
+
+// Offense is a late cancellation or no-show, penalized according to the
+// class type's policy.
+type Offense struct {
+	UserID    int32
+	BookingID int32
+	Reason    Reason
+	Policy    Policy
+	FeeCents  int32
+}
+
+// Notice tells a member about the penalties they got for a booking.
+type Notice struct {
+	Email     string
+	ClassName string
+	// StartsAt is in the timezone of the class schedule
+	StartsAt  time.Time
+	Reason    Reason
+	Penalties []repository.Penalty
+}
+
+// BanPolicy bans members from booking for Duration once they have Threshold
+// no-shows within Window. A zero Threshold disables bans.
+type BanPolicy struct {
+	Threshold int
+	Window    time.Duration
+	Duration  time.Duration
+}
+
+type PenaltyService struct {
+	db         repository.TxBeginner
+	repository *repository.Queries
+	banPolicy  BanPolicy
+}
+
+func NewPenaltyService(
+	db repository.TxBeginner,
+	repository *repository.Queries,
+	banPolicy BanPolicy,
+) *PenaltyService {
+	return &PenaltyService{
+		db:         db,
+		repository: repository,
+		banPolicy:  banPolicy,
+	}
+}
+
+var (
+	ErrPenaltyDoesntExist = errors.New("penalty does not exist")
+	ErrAlreadyWaived      = errors.New("penalty is already waived")
+	ErrBookingBanned      = errors.New("booking is suspended after repeated no-shows")
+)
+
+// Penalize records the penalty for an offense. It must run inside a
+// transaction. A credit penalty for a member without credits left is
+// recorded as a warning.
+func Penalize(ctx context.Context, q *repository.Queries, offense Offense) (repository.Penalty, error) {
+	params := repository.CreatePenaltyParams{
+		UserID:    offense.UserID,
+		BookingID: offense.BookingID,
+		Reason:    string(offense.Reason),
+		Kind:      KindWarning,
+	}
+
+	switch offense.Policy {
+	case PolicyCredit:
+		entry, err := credits.TakePenaltyCredit(ctx, q, offense.UserID, string(offense.Reason))
+		if err != nil && !errors.Is(err, credits.ErrInsufficientCredits) {
+			return repository.Penalty{}, err
+		}
+		if err == nil {
+			params.Kind = KindCredit
+			params.CreditEntryID = pgtype.Int4{Int32: entry.ID, Valid: true}
+		}
+	case PolicyFee:
+		if offense.FeeCents > 0 {
+			params.Kind = KindFee
+			params.FeeCents = offense.FeeCents
+		}
+	}
+
+	penalty, err := q.CreatePenalty(ctx, params)
+	if err != nil {
+		return penalty, fmt.Errorf("failed to create penalty in db: %w", err)
+	}
+
+	return penalty, nil
+}
+
+// MarkNoShows marks bookings of ended sessions without a check-in as no-shows
+// and penalizes them. Members who reach the no-show threshold are banned from
+// booking. The returned notices are meant to be emailed to the members.
+func (s *PenaltyService) MarkNoShows(ctx context.Context) ([]Notice, error) {
+	var notices []Notice
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		noShows, err := q.MarkNoShows(ctx, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to mark no-shows: %w", err)
+		}
+
+		for _, noShow := range noShows {
+			penalty, err := Penalize(ctx, q, Offense{
+				UserID:    noShow.UserID,
+				BookingID: noShow.ID,
+				Reason:    ReasonNoShow,
+				Policy:    Policy(noShow.NoShowPenalty),
+				FeeCents:  noShow.PenaltyFeeCents,
+			})
+			if err != nil {
+				return err
+			}
+
+			notice := Notice{
+				Email:     noShow.Email,
+				ClassName: noShow.ClassTypeName,
+				StartsAt:  noShow.StartsAt,
+				Reason:    ReasonNoShow,
+				Penalties: []repository.Penalty{penalty},
+			}
+			if loc, err := time.LoadLocation(noShow.Timezone); err == nil {
+				notice.StartsAt = notice.StartsAt.In(loc)
+			}
+
+			ban, err := s.banIfOverThreshold(ctx, q, noShow.UserID, noShow.ID)
+			if err != nil {
+				return err
+			}
+			if ban != nil {
+				notice.Penalties = append(notice.Penalties, *ban)
+			}
+
+			notices = append(notices, notice)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notices, nil
+}
+
+func (s *PenaltyService) GetPenalties(ctx context.Context, userID int32) ([]repository.Penalty, error) {
+	return s.repository.GetPenaltiesByUserID(ctx, userID)
+}
+
+// Waive lifts a penalty. Bans stop applying and credits taken are given back.
+func (s *PenaltyService) Waive(ctx context.Context, penaltyID, staffID int32) (*repository.Penalty, error) {
+	var penalty repository.Penalty
+
+	err := repository.RunInTx(ctx, s.db, s.repository, func(q *repository.Queries) error {
+		if _, err := q.GetPenaltyByID(ctx, penaltyID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrPenaltyDoesntExist
+			}
+			return fmt.Errorf("failed to get penalty: %w", err)
+		}
+
+		var err error
+		penalty, err = q.WaivePenalty(ctx, repository.WaivePenaltyParams{
+			ID:       penaltyID,
+			WaivedBy: pgtype.Int4{Int32: staffID, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrAlreadyWaived
+			}
+			return fmt.Errorf("failed to waive penalty in db: %w", err)
+		}
+
+		if penalty.CreditEntryID.Valid {
+			if _, err := credits.RefundEntry(ctx, q, penalty.CreditEntryID.Int32, "penalty waived", staffID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &penalty, nil
+}
+
+// RejectBanned is an AccountCheck that keeps members with an active booking
+// ban from making new bookings.
+func (s *PenaltyService) RejectBanned(ctx context.Context, userID int32) (context.Context, error) {
+	ban, err := s.repository.GetActiveBan(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ctx, nil
+		}
+		return ctx, fmt.Errorf("failed to get active ban: %w", err)
+	}
+
+	return ctx, middleware.Reject(
+		http.StatusForbidden,
+		fmt.Errorf("%w until %s", ErrBookingBanned, ban.BannedUntil.Time.Format(time.RFC3339)),
+	)
+}
+
+// banIfOverThreshold bans a member from booking once they reach the no-show
+// threshold. Only no-shows since the member's last ban count, so a ban wipes
+// the slate clean.
+func (s *PenaltyService) banIfOverThreshold(ctx context.Context, q *repository.Queries, userID, bookingID int32) (*repository.Penalty, error) {
+	if s.banPolicy.Threshold <= 0 {
+		return nil, nil
+	}
+
+	since := time.Now().Add(-s.banPolicy.Window)
+	latest, err := q.GetLatestBan(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get latest ban: %w", err)
+	}
+	if err == nil && latest.CreatedAt.After(since) {
+		since = latest.CreatedAt
+	}
+
+	noShows, err := q.CountNoShowsSince(ctx, repository.CountNoShowsSinceParams{
+		UserID: userID,
+		Since:  since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count no-shows: %w", err)
+	}
+	if noShows < int64(s.banPolicy.Threshold) {
+		return nil, nil
+	}
+
+	ban, err := q.CreatePenalty(ctx, repository.CreatePenaltyParams{
+		UserID:      userID,
+		BookingID:   bookingID,
+		Reason:      string(ReasonNoShow),
+		Kind:        KindBan,
+		BannedUntil: pgtype.Timestamptz{Time: time.Now().Add(s.banPolicy.Duration), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ban in db: %w", err)
+	}
+
+	return &ban, nil
+}
@@ -7,17 +7,65 @@ WHERE id = $1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1;
+WHERE lower(email) = lower(sqlc.arg(email));
 
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
 
 -- name: CreateUser :one
-INSERT INTO users (email, password, updated_at)
-VALUES ($1, $2, NOW())
+INSERT INTO users (email, password, status, updated_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING *;
+
+-- name: UpdateUserStatus :one
+WITH updated AS (
+  UPDATE users
+  SET status = sqlc.arg(to_status),
+      status_reason = sqlc.narg(reason),
+      status_changed_at = NOW(),
+      updated_at = NOW()
+  WHERE users.id = sqlc.arg(id) AND users.status = sqlc.arg(from_status)
+  RETURNING *
+), transition AS (
+  INSERT INTO user_status_transitions (user_id, from_status, to_status, reason)
+  SELECT updated.id, sqlc.arg(from_status)::VARCHAR, updated.status, updated.status_reason
+  FROM updated
+)
+SELECT * FROM updated;
+
+-- name: UpdateUserRole :one
+-- Staff can't be demoted while they are the only active staff, so someone is
+-- always left to manage accounts.
+UPDATE users
+SET role = sqlc.arg(role), updated_at = NOW()
+WHERE users.id = sqlc.arg(id)
+  AND (
+    users.role <> 'staff' OR sqlc.arg(role)::VARCHAR = 'staff' OR EXISTS (
+      SELECT 1 FROM users AS other
+      WHERE other.role = 'staff' AND other.status = 'active' AND other.id <> sqlc.arg(id)
+    )
+  )
 RETURNING *;
 
+-- name: PromoteFirstStaff :execrows
+-- Makes the active user with the given email staff, unless there is staff
+-- already.
+UPDATE users
+SET role = 'staff', updated_at = NOW()
+WHERE lower(email) = lower(sqlc.arg(email)) AND status = 'active'
+  AND NOT EXISTS (SELECT 1 FROM users WHERE role = 'staff');
+
+-- name: CreateUserStatusTransition :one
+INSERT INTO user_status_transitions (user_id, from_status, to_status, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserStatusTransitions :many
+SELECT * FROM user_status_transitions
+WHERE user_id = $1
+ORDER BY created_at;
+
 -- name: CreateEmailVerificationToken :one
 INSERT INTO email_verification_tokens (email, verification_token, hashed_password_cache_key, expires_at)
 VALUES ($1, $2, $3, $4)
@@ -30,3 +78,902 @@ WHERE email = $1;
 -- name: DeleteEmailVerificationTokenByID :exec
 DELETE FROM email_verification_tokens
 WHERE id = $1;
+
+-- name: GetCurrentLegalDocuments :many
+SELECT DISTINCT ON (kind) * FROM legal_documents
+WHERE published_at <= NOW()
+ORDER BY kind, version DESC;
+
+-- name: GetOutstandingLegalDocuments :many
+SELECT * FROM legal_documents
+WHERE id IN (
+  SELECT DISTINCT ON (kind) id FROM legal_documents
+  WHERE published_at <= NOW()
+  ORDER BY kind, version DESC
+)
+AND NOT EXISTS (
+  SELECT 1 FROM legal_acceptances
+  WHERE legal_acceptances.document_id = legal_documents.id
+    AND legal_acceptances.user_id = $1
+)
+ORDER BY kind;
+
+-- name: CreateLegalAcceptance :exec
+INSERT INTO legal_acceptances (user_id, document_id, ip_address)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, document_id) DO NOTHING;
+
+-- name: CreatePendingLegalAcceptance :exec
+INSERT INTO pending_legal_acceptances (email, document_id, ip_address)
+VALUES ($1, $2, $3)
+ON CONFLICT (email, document_id) DO UPDATE
+SET ip_address = EXCLUDED.ip_address, accepted_at = NOW();
+
+-- name: ClaimPendingLegalAcceptances :exec
+WITH claimed AS (
+  DELETE FROM pending_legal_acceptances
+  WHERE email = sqlc.arg(email)
+  RETURNING document_id, ip_address, accepted_at
+)
+INSERT INTO legal_acceptances (user_id, document_id, ip_address, accepted_at)
+SELECT sqlc.arg(user_id)::INTEGER, claimed.document_id, claimed.ip_address, claimed.accepted_at
+FROM claimed
+ON CONFLICT (user_id, document_id) DO NOTHING;
+
+-- name: CreateMembershipPlan :one
+INSERT INTO membership_plans (name, description, price_cents, billing_period, validity_days, class_allowance, max_freezes_per_year, max_freeze_days_per_year)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetActiveMembershipPlans :many
+SELECT * FROM membership_plans
+WHERE is_active
+ORDER BY price_cents;
+
+-- name: GetMembershipPlanByID :one
+SELECT * FROM membership_plans
+WHERE id = $1;
+
+-- name: CreateSubscription :one
+INSERT INTO subscriptions (user_id, plan_id, starts_at, ends_at, renews_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSubscriptionByID :one
+SELECT * FROM subscriptions
+WHERE id = $1;
+
+-- name: GetSubscriptionsByUserID :many
+SELECT * FROM subscriptions
+WHERE user_id = $1
+ORDER BY starts_at DESC;
+
+-- name: PauseSubscription :one
+UPDATE subscriptions
+SET status = 'paused', paused_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ResumeSubscription :one
+-- The time spent paused is added to the end and renewal dates.
+UPDATE subscriptions
+SET status = 'active',
+    ends_at = ends_at + (NOW() - paused_at),
+    renews_at = renews_at + (NOW() - paused_at),
+    paused_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'paused'
+RETURNING *;
+
+-- name: CancelSubscription :one
+UPDATE subscriptions
+SET status = 'cancelled', cancelled_at = NOW(), renews_at = NULL, paused_at = NULL, updated_at = NOW()
+WHERE id = $1 AND status IN ('active', 'paused', 'frozen')
+RETURNING *;
+
+-- name: RenewSubscription :one
+UPDATE subscriptions
+SET status = 'active', ends_at = $2, renews_at = $3, updated_at = NOW()
+WHERE id = $1 AND status IN ('active', 'expired')
+RETURNING *;
+
+-- name: ExpireLapsedSubscriptions :exec
+-- Active subscriptions that reached their end date without being renewed
+-- expire. Paused and frozen ones have their end date moved when they resume.
+UPDATE subscriptions
+SET status = 'expired', updated_at = NOW()
+WHERE status = 'active' AND ends_at <= NOW();
+
+-- name: GetSubscriptionForUpdate :one
+SELECT * FROM subscriptions
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ShiftSubscriptionEnd :one
+-- Moves the end and renewal dates of a subscription by a freeze's length.
+UPDATE subscriptions
+SET ends_at = ends_at + sqlc.arg(shift)::INTERVAL,
+    renews_at = renews_at + sqlc.arg(shift)::INTERVAL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateClassType :one
+INSERT INTO class_types (name, description, duration_minutes, cancellation_window_minutes, late_cancel_penalty, no_show_penalty, penalty_fee_cents)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateClassTypePenaltyPolicy :one
+UPDATE class_types
+SET cancellation_window_minutes = $2, late_cancel_penalty = $3, no_show_penalty = $4, penalty_fee_cents = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: GetClassTypes :many
+SELECT * FROM class_types
+ORDER BY name;
+
+-- name: CreateRoom :one
+INSERT INTO rooms (name, capacity)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetRooms :many
+SELECT * FROM rooms
+ORDER BY name;
+
+-- name: CreateClassSchedule :one
+INSERT INTO class_schedules (class_type_id, room_id, coach_id, dtstart, rrule, timezone)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetClassScheduleByID :one
+SELECT * FROM class_schedules
+WHERE id = $1;
+
+-- name: GetScheduledClasses :many
+-- Schedules that may have occurrences before the given time, joined with what
+-- the timetable needs to display them.
+SELECT
+  class_schedules.*,
+  class_types.name AS class_type_name,
+  class_types.duration_minutes,
+  rooms.name AS room_name,
+  rooms.capacity
+FROM class_schedules
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+JOIN rooms ON rooms.id = class_schedules.room_id
+WHERE class_schedules.dtstart < sqlc.arg(before)
+ORDER BY class_schedules.id;
+
+-- name: CreateClassScheduleException :one
+INSERT INTO class_schedule_exceptions (schedule_id, starts_at, reason)
+VALUES ($1, $2, $3)
+ON CONFLICT (schedule_id, starts_at) DO UPDATE
+SET reason = EXCLUDED.reason
+RETURNING *;
+
+-- name: GetClassScheduleExceptionsBetween :many
+SELECT * FROM class_schedule_exceptions
+WHERE starts_at >= sqlc.arg(from_time) AND starts_at < sqlc.arg(to_time);
+
+-- name: CreateClosure :one
+INSERT INTO closures (starts_at, ends_at, reason)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetClosuresBetween :many
+SELECT * FROM closures
+WHERE starts_at < sqlc.arg(to_time) AND ends_at > sqlc.arg(from_time)
+ORDER BY starts_at;
+
+-- name: CreateClassSessions :exec
+-- Materializes schedule occurrences so they can be booked. Existing sessions
+-- are left untouched.
+INSERT INTO class_sessions (schedule_id, starts_at, ends_at, capacity)
+SELECT
+  unnest(sqlc.arg(schedule_ids)::INTEGER[]),
+  unnest(sqlc.arg(starts_ats)::TIMESTAMPTZ[]),
+  unnest(sqlc.arg(ends_ats)::TIMESTAMPTZ[]),
+  unnest(sqlc.arg(capacities)::INTEGER[])
+ON CONFLICT (schedule_id, starts_at) DO NOTHING;
+
+-- name: GetClassSessionsBetween :many
+SELECT * FROM class_sessions
+WHERE starts_at >= sqlc.arg(from_time) AND starts_at < sqlc.arg(to_time)
+ORDER BY starts_at;
+
+-- name: CancelClassSession :exec
+UPDATE class_sessions
+SET cancelled_at = NOW()
+WHERE schedule_id = $1 AND starts_at = $2 AND cancelled_at IS NULL;
+
+-- name: CancelClassSessionsBetween :exec
+UPDATE class_sessions
+SET cancelled_at = NOW()
+WHERE starts_at < sqlc.arg(to_time) AND ends_at > sqlc.arg(from_time) AND cancelled_at IS NULL;
+
+-- name: GetClassSessionForUpdate :one
+SELECT class_sessions.*, class_types.name AS class_type_name, class_schedules.timezone,
+  class_types.cancellation_window_minutes, class_types.late_cancel_penalty, class_types.penalty_fee_cents
+FROM class_sessions
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+WHERE class_sessions.id = $1
+FOR UPDATE OF class_sessions;
+
+-- name: CountBookedInSession :one
+-- Members who checked in keep their seat.
+SELECT COUNT(*) FROM bookings
+WHERE session_id = $1 AND status IN ('booked', 'attended');
+
+-- name: GetActiveBooking :one
+SELECT * FROM bookings
+WHERE session_id = $1 AND user_id = $2 AND status <> 'cancelled';
+
+-- name: CreateBooking :one
+INSERT INTO bookings (session_id, user_id, status)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: CancelBooking :one
+UPDATE bookings
+SET status = 'cancelled', late_cancellation = $2, cancelled_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND status <> 'cancelled'
+RETURNING *;
+
+-- name: MarkBookingAttended :exec
+UPDATE bookings
+SET status = 'attended', checked_in_at = $3, updated_at = NOW()
+WHERE session_id = $1 AND user_id = $2 AND status = 'booked';
+
+-- name: MarkNoShows :many
+-- Marks bookings of sessions that ended before the cutoff without the member
+-- checking in.
+WITH no_shows AS (
+  UPDATE bookings
+  SET status = 'no_show', updated_at = NOW()
+  FROM class_sessions
+  WHERE class_sessions.id = bookings.session_id
+    AND bookings.status = 'booked'
+    AND class_sessions.cancelled_at IS NULL
+    AND class_sessions.ends_at < sqlc.arg(cutoff)
+    AND NOT EXISTS (
+      SELECT 1 FROM attendances
+      WHERE attendances.session_id = bookings.session_id AND attendances.user_id = bookings.user_id
+    )
+  RETURNING bookings.id, bookings.user_id, bookings.session_id
+)
+SELECT no_shows.id, no_shows.user_id, users.email, class_sessions.starts_at,
+  class_types.name AS class_type_name, class_schedules.timezone,
+  class_types.no_show_penalty, class_types.penalty_fee_cents
+FROM no_shows
+JOIN users ON users.id = no_shows.user_id
+JOIN class_sessions ON class_sessions.id = no_shows.session_id
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+JOIN class_types ON class_types.id = class_schedules.class_type_id
+ORDER BY no_shows.user_id, class_sessions.starts_at;
+
+-- name: PromoteNextWaitlisted :one
+-- Gives the freed spot to whoever has been waiting the longest among the
+-- members who could book it themselves. Banned, frozen and inactive members
+-- stay on the waitlist.
+UPDATE bookings
+SET status = 'booked', promoted_at = NOW(), updated_at = NOW()
+WHERE bookings.id = (
+  SELECT waiting.id FROM bookings AS waiting
+  JOIN users ON users.id = waiting.user_id
+  WHERE waiting.session_id = $1 AND waiting.status = 'waitlisted'
+    AND users.status = 'active'
+    AND NOT EXISTS (
+      SELECT 1 FROM penalties
+      WHERE penalties.user_id = waiting.user_id AND penalties.kind = 'ban'
+        AND penalties.waived_at IS NULL AND penalties.banned_until > NOW()
+    )
+    AND NOT EXISTS (
+      SELECT 1 FROM membership_freezes
+      WHERE membership_freezes.user_id = waiting.user_id AND membership_freezes.status = 'active'
+    )
+  ORDER BY waiting.created_at, waiting.id
+  LIMIT 1
+)
+RETURNING *;
+
+-- name: GetWaitlistPosition :one
+SELECT COUNT(*) FROM bookings
+WHERE session_id = $1 AND status = 'waitlisted'
+  AND (created_at, id) <= (sqlc.arg(created_at)::TIMESTAMPTZ, sqlc.arg(id)::INTEGER);
+
+-- name: GetSessionBookings :many
+SELECT bookings.*, users.email
+FROM bookings
+JOIN users ON users.id = bookings.user_id
+WHERE bookings.session_id = $1 AND bookings.status <> 'cancelled'
+ORDER BY bookings.status, bookings.created_at, bookings.id;
+
+-- name: GetCurrentSubscription :one
+SELECT * FROM subscriptions
+WHERE user_id = $1 AND status = 'active' AND starts_at <= NOW() AND ends_at > NOW()
+ORDER BY ends_at DESC
+LIMIT 1;
+
+-- name: CreateAttendance :one
+-- The class type defaults to the one of the session, if any.
+INSERT INTO attendances (user_id, session_id, class_type_id, source, recorded_by, checked_in_at)
+VALUES (
+  sqlc.arg(user_id),
+  sqlc.narg(session_id),
+  COALESCE(sqlc.narg(class_type_id)::INTEGER, (
+    SELECT class_schedules.class_type_id
+    FROM class_sessions
+    JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+    WHERE class_sessions.id = sqlc.narg(session_id)
+  )),
+  sqlc.arg(source),
+  sqlc.narg(recorded_by),
+  COALESCE(sqlc.narg(checked_in_at)::TIMESTAMPTZ, NOW())
+)
+RETURNING *;
+
+-- name: GetLatestAttendance :one
+SELECT * FROM attendances
+WHERE user_id = $1
+ORDER BY checked_in_at DESC
+LIMIT 1;
+
+-- name: GetAttendanceLog :many
+SELECT attendances.*, class_types.name AS class_type_name
+FROM attendances
+LEFT JOIN class_types ON class_types.id = attendances.class_type_id
+WHERE attendances.user_id = sqlc.arg(user_id)
+  AND attendances.checked_in_at >= sqlc.arg(from_time)
+  AND attendances.checked_in_at < sqlc.arg(to_time)
+ORDER BY attendances.checked_in_at DESC;
+
+-- name: GetAttendanceCountsByWeek :many
+SELECT
+  date_trunc('week', checked_in_at AT TIME ZONE sqlc.arg(timezone)::TEXT)::DATE AS week,
+  COUNT(*) AS attendances
+FROM attendances
+WHERE user_id = sqlc.arg(user_id)
+  AND checked_in_at >= sqlc.arg(from_time)
+  AND checked_in_at < sqlc.arg(to_time)
+GROUP BY week
+ORDER BY week;
+
+-- name: GetAttendanceCountsByMonth :many
+SELECT
+  date_trunc('month', checked_in_at AT TIME ZONE sqlc.arg(timezone)::TEXT)::DATE AS month,
+  COUNT(*) AS attendances
+FROM attendances
+WHERE user_id = sqlc.arg(user_id)
+  AND checked_in_at >= sqlc.arg(from_time)
+  AND checked_in_at < sqlc.arg(to_time)
+GROUP BY month
+ORDER BY month;
+
+-- name: GetAttendanceCountsByClassType :many
+SELECT
+  attendances.class_type_id,
+  class_types.name AS class_type_name,
+  COUNT(*) AS attendances
+FROM attendances
+LEFT JOIN class_types ON class_types.id = attendances.class_type_id
+WHERE attendances.user_id = sqlc.arg(user_id)
+  AND attendances.checked_in_at >= sqlc.arg(from_time)
+  AND attendances.checked_in_at < sqlc.arg(to_time)
+GROUP BY attendances.class_type_id, class_types.name
+ORDER BY attendances DESC;
+
+-- name: GetAttendanceStreaks :one
+-- A streak is a run of consecutive weeks with at least one attendance. Weeks
+-- are numbered with ROW_NUMBER so that consecutive weeks share the same
+-- week - n*7 anchor (gaps and islands). The current streak is the one that
+-- includes this week or, if nothing was recorded yet this week, last week.
+WITH weeks AS (
+  SELECT DISTINCT date_trunc('week', checked_in_at AT TIME ZONE sqlc.arg(timezone)::TEXT)::DATE AS week
+  FROM attendances
+  WHERE user_id = sqlc.arg(user_id)
+), islands AS (
+  SELECT week, week - (ROW_NUMBER() OVER (ORDER BY week) * 7)::INTEGER AS anchor
+  FROM weeks
+), streaks AS (
+  SELECT MAX(week) AS last_week, COUNT(*) AS weeks
+  FROM islands
+  GROUP BY anchor
+)
+SELECT
+  COALESCE(MAX(weeks), 0)::INTEGER AS longest_streak,
+  COALESCE(MAX(weeks) FILTER (
+    WHERE last_week >= date_trunc('week', NOW() AT TIME ZONE sqlc.arg(timezone)::TEXT)::DATE - 7
+  ), 0)::INTEGER AS current_streak
+FROM streaks;
+
+-- name: LockUserForUpdate :one
+-- Serializes writes to a user's credit ledger.
+SELECT id FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CreateCreditLedgerEntry :one
+INSERT INTO credit_ledger (user_id, kind, delta, lot_id, expires_at, subscription_id, attendance_id, refund_of, reason, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetCreditLedgerEntryByID :one
+SELECT * FROM credit_ledger
+WHERE id = $1;
+
+-- name: GetCreditLedger :many
+SELECT * FROM credit_ledger
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetCreditBalance :one
+SELECT COALESCE(SUM(delta), 0)::INTEGER AS balance
+FROM credit_ledger
+WHERE user_id = $1;
+
+-- name: GetOpenCreditLots :many
+-- Lots that still have credits left, soonest to expire first.
+SELECT lot.id, lot.expires_at, (lot.delta + COALESCE(SUM(entry.delta), 0))::INTEGER AS remaining
+FROM credit_ledger AS lot
+LEFT JOIN credit_ledger AS entry ON entry.lot_id = lot.id
+WHERE lot.user_id = $1 AND lot.lot_id IS NULL
+GROUP BY lot.id
+HAVING lot.delta + COALESCE(SUM(entry.delta), 0) > 0
+ORDER BY lot.expires_at NULLS LAST, lot.id;
+
+-- name: HasUnlimitedMembership :one
+SELECT EXISTS (
+  SELECT 1 FROM subscriptions
+  JOIN membership_plans ON membership_plans.id = subscriptions.plan_id
+  WHERE subscriptions.user_id = $1
+    AND subscriptions.status = 'active'
+    AND subscriptions.starts_at <= NOW()
+    AND subscriptions.ends_at > NOW()
+    AND membership_plans.class_allowance IS NULL
+);
+
+-- name: CreatePenalty :one
+INSERT INTO penalties (user_id, booking_id, reason, kind, fee_cents, credit_entry_id, banned_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetPenaltyByID :one
+SELECT * FROM penalties
+WHERE id = $1;
+
+-- name: GetPenaltiesByUserID :many
+SELECT * FROM penalties
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: WaivePenalty :one
+UPDATE penalties
+SET waived_at = NOW(), waived_by = $2
+WHERE id = $1 AND waived_at IS NULL
+RETURNING *;
+
+-- name: GetActiveBan :one
+SELECT * FROM penalties
+WHERE user_id = $1 AND kind = 'ban' AND waived_at IS NULL AND banned_until > NOW()
+ORDER BY banned_until DESC
+LIMIT 1;
+
+-- name: GetLatestBan :one
+SELECT * FROM penalties
+WHERE user_id = $1 AND kind = 'ban'
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: CountNoShowsSince :one
+SELECT COUNT(*) FROM penalties
+WHERE user_id = $1 AND reason = 'no_show' AND kind <> 'ban' AND waived_at IS NULL AND created_at > sqlc.arg(since);
+
+-- name: CreateMembershipFreeze :one
+INSERT INTO membership_freezes (subscription_id, user_id, starts_at, ends_at, reason, requested_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetMembershipFreezeForUpdate :one
+SELECT * FROM membership_freezes
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetMembershipFreezesByUserID :many
+SELECT * FROM membership_freezes
+WHERE user_id = $1
+ORDER BY starts_at DESC;
+
+-- name: GetActiveMembershipFreeze :one
+SELECT * FROM membership_freezes
+WHERE user_id = $1 AND status = 'active'
+ORDER BY ends_at DESC
+LIMIT 1;
+
+-- name: HasOverlappingFreeze :one
+SELECT EXISTS (
+  SELECT 1 FROM membership_freezes
+  WHERE subscription_id = $1
+    AND status IN ('scheduled', 'active')
+    AND starts_at < sqlc.arg(ends_at)
+    AND ends_at > sqlc.arg(starts_at)
+);
+
+-- name: GetFreezeUsage :one
+-- Counts a member's freezes starting in [from, to) and the days they cover.
+SELECT COUNT(*)::INTEGER AS freezes,
+  COALESCE(CEIL(SUM(EXTRACT(EPOCH FROM COALESCE(ended_at, ends_at) - starts_at)) / 86400), 0)::INTEGER AS days
+FROM membership_freezes
+WHERE user_id = $1
+  AND status <> 'cancelled'
+  AND starts_at >= sqlc.arg(from_time)
+  AND starts_at < sqlc.arg(to_time);
+
+-- name: SetMembershipFreezeStatus :one
+UPDATE membership_freezes
+SET status = $2, ended_at = $3, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: StartDueFreezes :many
+-- Starts the scheduled freezes of active subscriptions and freezes the
+-- subscriptions.
+WITH started AS (
+  UPDATE membership_freezes
+  SET status = 'active', updated_at = NOW()
+  FROM subscriptions
+  WHERE subscriptions.id = membership_freezes.subscription_id
+    AND subscriptions.status = 'active'
+    AND membership_freezes.status = 'scheduled'
+    AND membership_freezes.starts_at <= NOW()
+  RETURNING membership_freezes.subscription_id
+)
+UPDATE subscriptions
+SET status = 'frozen', updated_at = NOW()
+WHERE id IN (SELECT subscription_id FROM started)
+RETURNING *;
+
+-- name: EndDueFreezes :many
+-- Completes the freezes that reached their end date and unfreezes the
+-- subscriptions.
+WITH ended AS (
+  UPDATE membership_freezes
+  SET status = 'completed', ended_at = ends_at, updated_at = NOW()
+  WHERE status = 'active' AND ends_at <= NOW()
+  RETURNING subscription_id
+)
+UPDATE subscriptions
+SET status = 'active', updated_at = NOW()
+WHERE id IN (SELECT subscription_id FROM ended) AND status = 'frozen'
+RETURNING *;
+
+-- name: FreezeSubscription :exec
+UPDATE subscriptions
+SET status = 'frozen', updated_at = NOW()
+WHERE id = $1 AND status = 'active';
+
+-- name: UnfreezeSubscription :exec
+UPDATE subscriptions
+SET status = 'active', updated_at = NOW()
+WHERE id = $1 AND status = 'frozen';
+
+-- name: CancelStaleFreezes :exec
+-- Scheduled freezes of subscriptions that ended or were cancelled before the
+-- freeze started can't apply anymore.
+UPDATE membership_freezes
+SET status = 'cancelled', updated_at = NOW()
+FROM subscriptions
+WHERE subscriptions.id = membership_freezes.subscription_id
+  AND membership_freezes.status = 'scheduled'
+  AND subscriptions.status IN ('cancelled', 'expired');
+
+-- name: UpsertCoachProfile :one
+INSERT INTO coach_profiles (user_id, bio, specialties, certifications, timezone)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET bio = EXCLUDED.bio,
+    specialties = EXCLUDED.specialties,
+    certifications = EXCLUDED.certifications,
+    timezone = EXCLUDED.timezone,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetCoaches :many
+SELECT coach_profiles.*, users.email
+FROM coach_profiles
+JOIN users ON users.id = coach_profiles.user_id
+WHERE users.role IN ('coach', 'staff') AND users.status = 'active'
+ORDER BY users.email;
+
+-- name: GetCoach :one
+SELECT coach_profiles.*, users.email
+FROM coach_profiles
+JOIN users ON users.id = coach_profiles.user_id
+WHERE coach_profiles.user_id = $1 AND users.role IN ('coach', 'staff') AND users.status = 'active';
+
+-- name: DeleteCoachAvailability :exec
+DELETE FROM coach_availability
+WHERE coach_id = $1;
+
+-- name: CreateCoachAvailability :many
+INSERT INTO coach_availability (coach_id, weekday, start_time, end_time)
+SELECT sqlc.arg(coach_id),
+  unnest(sqlc.arg(weekdays)::SMALLINT[]),
+  unnest(sqlc.arg(start_times)::TIME[]),
+  unnest(sqlc.arg(end_times)::TIME[])
+RETURNING *;
+
+-- name: GetCoachAvailability :many
+SELECT * FROM coach_availability
+WHERE coach_id = $1
+ORDER BY weekday, start_time;
+
+-- name: CreateCoachAvailabilityException :one
+INSERT INTO coach_availability_exceptions (coach_id, starts_at, ends_at, reason)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteCoachAvailabilityException :execrows
+DELETE FROM coach_availability_exceptions
+WHERE id = $1 AND coach_id = $2;
+
+-- name: GetCoachAvailabilityExceptionsBetween :many
+SELECT * FROM coach_availability_exceptions
+WHERE coach_id = $1 AND starts_at < sqlc.arg(to_time) AND ends_at > sqlc.arg(from_time)
+ORDER BY starts_at;
+
+-- name: GetCoachClassSessionsBetween :many
+-- Group classes the coach teaches count as booked time.
+SELECT class_sessions.starts_at, class_sessions.ends_at
+FROM class_sessions
+JOIN class_schedules ON class_schedules.id = class_sessions.schedule_id
+WHERE class_schedules.coach_id = $1
+  AND class_sessions.cancelled_at IS NULL
+  AND class_sessions.starts_at < sqlc.arg(to_time)
+  AND class_sessions.ends_at > sqlc.arg(from_time)
+ORDER BY class_sessions.starts_at;
+
+-- name: CreateTrainingSession :one
+INSERT INTO training_sessions (coach_id, member_id, starts_at, ends_at, note)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetTrainingSessionForUpdate :one
+SELECT * FROM training_sessions
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetTrainingSessionsByUserID :many
+-- Sessions the user takes as a member or gives as a coach.
+SELECT * FROM training_sessions
+WHERE member_id = sqlc.arg(user_id) OR coach_id = sqlc.arg(user_id)
+ORDER BY starts_at DESC;
+
+-- name: GetCoachTrainingSessionsBetween :many
+-- Requested and confirmed sessions count as booked time.
+SELECT id, starts_at, ends_at
+FROM training_sessions
+WHERE coach_id = $1
+  AND status IN ('requested', 'confirmed')
+  AND starts_at < sqlc.arg(to_time)
+  AND ends_at > sqlc.arg(from_time)
+ORDER BY starts_at;
+
+-- name: SetTrainingSessionStatus :one
+UPDATE training_sessions
+SET status = $2,
+    decline_reason = $3,
+    cancelled_by = $4,
+    proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ProposeTrainingSessionTime :one
+UPDATE training_sessions
+SET proposed_starts_at = $2,
+    proposed_ends_at = $3,
+    proposed_by = $4,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ClearTrainingSessionProposal :one
+UPDATE training_sessions
+SET proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MoveTrainingSession :one
+-- Moves a session to its proposed time. A new reminder goes out for the new time.
+UPDATE training_sessions
+SET starts_at = proposed_starts_at,
+    ends_at = proposed_ends_at,
+    proposed_starts_at = NULL,
+    proposed_ends_at = NULL,
+    proposed_by = NULL,
+    reminder_sent_at = NULL,
+    updated_at = NOW()
+WHERE id = $1 AND proposed_starts_at IS NOT NULL
+RETURNING *;
+
+-- name: GetTrainingSessionParticipants :one
+SELECT members.email AS member_email, coaches.email AS coach_email, coach_profiles.timezone
+FROM training_sessions
+JOIN users members ON members.id = training_sessions.member_id
+JOIN users coaches ON coaches.id = training_sessions.coach_id
+JOIN coach_profiles ON coach_profiles.user_id = training_sessions.coach_id
+WHERE training_sessions.id = $1;
+
+-- name: ClaimTrainingSessionReminders :many
+-- Marks confirmed sessions starting before the cutoff as reminded and returns
+-- them, so each reminder goes out once.
+UPDATE training_sessions
+SET reminder_sent_at = NOW()
+FROM users members, users coaches, coach_profiles
+WHERE members.id = training_sessions.member_id
+  AND coaches.id = training_sessions.coach_id
+  AND coach_profiles.user_id = training_sessions.coach_id
+  AND training_sessions.status = 'confirmed'
+  AND training_sessions.reminder_sent_at IS NULL
+  AND training_sessions.starts_at > NOW()
+  AND training_sessions.starts_at <= sqlc.arg(cutoff)
+RETURNING training_sessions.id, training_sessions.starts_at, training_sessions.ends_at,
+  members.email AS member_email, coaches.email AS coach_email, coach_profiles.timezone;
+
+-- name: UpsertFighterProfile :one
+INSERT INTO fighter_profiles (
+  user_id, display_name, weight_class, height_cm, reach_cm, stance, level,
+  experience, public, show_measurements, show_bouts
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (user_id) DO UPDATE
+SET display_name = EXCLUDED.display_name,
+    weight_class = EXCLUDED.weight_class,
+    height_cm = EXCLUDED.height_cm,
+    reach_cm = EXCLUDED.reach_cm,
+    stance = EXCLUDED.stance,
+    level = EXCLUDED.level,
+    experience = EXCLUDED.experience,
+    public = EXCLUDED.public,
+    show_measurements = EXCLUDED.show_measurements,
+    show_bouts = EXCLUDED.show_bouts,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetFighterProfile :one
+SELECT * FROM fighter_profiles
+WHERE user_id = $1;
+
+-- name: GetPublicFighterProfile :one
+SELECT fighter_profiles.*
+FROM fighter_profiles
+JOIN users ON users.id = fighter_profiles.user_id
+WHERE fighter_profiles.user_id = $1 AND fighter_profiles.public AND users.status = 'active';
+
+-- name: GetPublicFighterProfiles :many
+SELECT fighter_profiles.*
+FROM fighter_profiles
+JOIN users ON users.id = fighter_profiles.user_id
+WHERE fighter_profiles.public AND users.status = 'active'
+  AND (sqlc.narg(weight_class)::VARCHAR IS NULL OR fighter_profiles.weight_class = sqlc.narg(weight_class))
+ORDER BY fighter_profiles.display_name;
+
+-- name: CreateBout :one
+INSERT INTO bouts (fighter_id, opponent, event, bout_date, level, result, method, round, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: DeleteBout :execrows
+DELETE FROM bouts
+WHERE id = $1;
+
+-- name: GetBoutsByFighterID :many
+SELECT * FROM bouts
+WHERE fighter_id = $1
+ORDER BY bout_date DESC, id DESC;
+
+-- name: GetFighterRecords :many
+-- W-L-D totals per level for the given fighters.
+SELECT fighter_id, level,
+  COUNT(*) FILTER (WHERE result = 'win')::INTEGER AS wins,
+  COUNT(*) FILTER (WHERE result = 'loss')::INTEGER AS losses,
+  COUNT(*) FILTER (WHERE result = 'draw')::INTEGER AS draws,
+  COUNT(*) FILTER (WHERE result = 'no_contest')::INTEGER AS no_contests,
+  COUNT(*) FILTER (WHERE result = 'win' AND method IN ('ko', 'tko', 'rsc'))::INTEGER AS ko_wins
+FROM bouts
+WHERE fighter_id = ANY(sqlc.arg(fighter_ids)::INTEGER[])
+GROUP BY fighter_id, level;
+
+-- name: CreateWeighIn :one
+INSERT INTO weigh_ins (user_id, weight_kg, body_fat_percent, measured_at, source, notes, recorded_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetWeighInsBetween :many
+SELECT * FROM weigh_ins
+WHERE user_id = $1 AND measured_at >= sqlc.arg(from_time) AND measured_at < sqlc.arg(to_time)
+ORDER BY measured_at;
+
+-- name: DeleteWeighIn :execrows
+DELETE FROM weigh_ins
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpsertWeightTarget :one
+-- A new or changed target can trigger a new alert.
+INSERT INTO weight_targets (user_id, target_kg, fight_date, coach_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET target_kg = EXCLUDED.target_kg,
+    fight_date = EXCLUDED.fight_date,
+    coach_id = EXCLUDED.coach_id,
+    alerted_at = NULL,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetWeightTarget :one
+SELECT * FROM weight_targets
+WHERE user_id = $1;
+
+-- name: DeleteWeightTarget :execrows
+DELETE FROM weight_targets
+WHERE user_id = $1;
+
+-- name: ClaimWeightCutAlert :one
+-- Marks a target as alerted and returns who to alert, so each target alerts
+-- its coach once.
+UPDATE weight_targets
+SET alerted_at = NOW()
+FROM users fighters, users coaches
+WHERE weight_targets.user_id = $1
+  AND weight_targets.alerted_at IS NULL
+  AND fighters.id = weight_targets.user_id
+  AND coaches.id = weight_targets.coach_id
+RETURNING coaches.email AS coach_email,
+  COALESCE(
+    (SELECT display_name FROM fighter_profiles WHERE fighter_profiles.user_id = weight_targets.user_id),
+    fighters.email
+  )::VARCHAR AS fighter_name;
+
+-- name: GetClassSession :one
+SELECT * FROM class_sessions
+WHERE id = $1;
+
+-- name: GetCheckedInFighters :many
+-- Members checked in to a session, with their fighter profile if they have one.
+SELECT DISTINCT ON (users.id)
+  users.id, users.email,
+  fighter_profiles.display_name, fighter_profiles.weight_class,
+  fighter_profiles.stance, fighter_profiles.experience
+FROM attendances
+JOIN users ON users.id = attendances.user_id
+LEFT JOIN fighter_profiles ON fighter_profiles.user_id = users.id
+WHERE attendances.session_id = $1
+ORDER BY users.id;
+
+-- name: GetLatestWeighIns :many
+SELECT DISTINCT ON (user_id) user_id, weight_kg, measured_at
+FROM weigh_ins
+WHERE user_id = ANY(sqlc.arg(user_ids)::INTEGER[]) AND measured_at >= sqlc.arg(since)
+ORDER BY user_id, measured_at DESC;
+
+-- name: GetRecentSparringPairs :many
+SELECT fighter_a_id, fighter_b_id, COUNT(*)::INTEGER AS times
+FROM sparring_pairings
+WHERE fighter_a_id = ANY(sqlc.arg(fighter_ids)::INTEGER[])
+  AND fighter_b_id = ANY(sqlc.arg(fighter_ids)::INTEGER[])
+  AND created_at >= sqlc.arg(since)
+GROUP BY fighter_a_id, fighter_b_id;
+
+-- name: CreateSparringPairings :many
+INSERT INTO sparring_pairings (session_id, fighter_a_id, fighter_b_id, recorded_by)
+SELECT sqlc.arg(session_id), unnest(sqlc.arg(fighter_a_ids)::INTEGER[]), unnest(sqlc.arg(fighter_b_ids)::INTEGER[]), sqlc.arg(recorded_by)
+ON CONFLICT DO NOTHING
+RETURNING *;
@@ -0,0 +1,95 @@
+package schedule
+
+import "time"
+
+type PenaltyPolicyRequest struct {
+	CancellationWindowMinutes int32 `json:"cancellation_window_minutes"`
+	// LateCancelPenalty and NoShowPenalty are none, credit or fee
+	LateCancelPenalty string `json:"late_cancel_penalty"`
+	NoShowPenalty     string `json:"no_show_penalty"`
+	PenaltyFeeCents   int32  `json:"penalty_fee_cents"`
+}
+
+type CreateClassTypeRequest struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	DurationMinutes int32  `json:"duration_minutes"`
+	PenaltyPolicyRequest
+}
+
+type ClassTypeResponse struct {
+	ID                        int32  `json:"id"`
+	Name                      string `json:"name"`
+	Description               string `json:"description"`
+	DurationMinutes           int32  `json:"duration_minutes"`
+	CancellationWindowMinutes int32  `json:"cancellation_window_minutes"`
+	LateCancelPenalty         string `json:"late_cancel_penalty"`
+	NoShowPenalty             string `json:"no_show_penalty"`
+	PenaltyFeeCents           int32  `json:"penalty_fee_cents"`
+}
+
+type CreateRoomRequest struct {
+	Name     string `json:"name"`
+	Capacity int32  `json:"capacity"`
+}
+
+type RoomResponse struct {
+	ID       int32  `json:"id"`
+	Name     string `json:"name"`
+	Capacity int32  `json:"capacity"`
+}
+
+type CreateScheduleRequest struct {
+	ClassTypeID int32  `json:"class_type_id"`
+	RoomID      int32  `json:"room_id"`
+	CoachID     *int32 `json:"coach_id"`
+	// StartsAt is the local wall time of the first class, e.g. 2025-06-02T19:00
+	StartsAt string `json:"starts_at"`
+	// RRule is an RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO
+	RRule    string `json:"rrule"`
+	Timezone string `json:"timezone"`
+}
+
+type ScheduleResponse struct {
+	ID          int32     `json:"id"`
+	ClassTypeID int32     `json:"class_type_id"`
+	RoomID      int32     `json:"room_id"`
+	CoachID     *int32    `json:"coach_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	RRule       string    `json:"rrule"`
+	Timezone    string    `json:"timezone"`
+}
+
+type CancelOccurrenceRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	Reason   string    `json:"reason"`
+}
+
+type CreateClosureRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type ClosureResponse struct {
+	ID       int32     `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+}
+
+type OccurrenceResponse struct {
+	SessionID          *int32    `json:"session_id"`
+	ScheduleID         int32     `json:"schedule_id"`
+	ClassTypeID        int32     `json:"class_type_id"`
+	ClassTypeName      string    `json:"class_type_name"`
+	RoomID             int32     `json:"room_id"`
+	RoomName           string    `json:"room_name"`
+	CoachID            *int32    `json:"coach_id"`
+	StartsAt           time.Time `json:"starts_at"`
+	EndsAt             time.Time `json:"ends_at"`
+	Timezone           string    `json:"timezone"`
+	Capacity           int32     `json:"capacity"`
+	Cancelled          bool      `json:"cancelled"`
+	CancellationReason string    `json:"cancellation_reason,omitempty"`
+}
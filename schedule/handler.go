@@ -0,0 +1,345 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/penalties"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultScheduleRange = 7 * 24 * time.Hour
+	localDateTimeLayout  = "2006-01-02T15:04"
+	dateLayout           = "2006-01-02"
+)
+
+type ScheduleHandlers struct {
+	sService IScheduleService
+}
+
+func NewScheduleHandlers(sService IScheduleService) *ScheduleHandlers {
+	return &ScheduleHandlers{
+		sService: sService,
+	}
+}
+
+func (h *ScheduleHandlers) GetSchedule(w http.ResponseWriter, r *http.Request) error {
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return apperror.BadRequest("Timezone is invalid")
+		}
+	}
+
+	from := time.Now()
+	if v := r.URL.Query().Get("from"); v != "" {
+		var err error
+		if from, err = ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("from must be an RFC 3339 time or a date")
+		}
+	}
+
+	to := from.Add(defaultScheduleRange)
+	if v := r.URL.Query().Get("to"); v != "" {
+		var err error
+		if to, err = ParseTimeParam(v, loc); err != nil {
+			return apperror.BadRequest("to must be an RFC 3339 time or a date")
+		}
+	}
+
+	occurrences, err := h.sService.GetSchedule(r.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) || errors.Is(err, ErrRangeTooLarge) {
+			return apperror.BadRequest(err.Error())
+		}
+		return err
+	}
+
+	resp := make([]OccurrenceResponse, 0, len(occurrences))
+	for _, o := range occurrences {
+		var sessionID *int32
+		if o.SessionID != 0 {
+			sessionID = &o.SessionID
+		}
+		resp = append(resp, OccurrenceResponse{
+			SessionID:          sessionID,
+			ScheduleID:         o.ScheduleID,
+			ClassTypeID:        o.ClassTypeID,
+			ClassTypeName:      o.ClassTypeName,
+			RoomID:             o.RoomID,
+			RoomName:           o.RoomName,
+			CoachID:            fromInt4(o.CoachID),
+			StartsAt:           o.StartsAt,
+			EndsAt:             o.EndsAt,
+			Timezone:           o.Timezone,
+			Capacity:           o.Capacity,
+			Cancelled:          o.Cancelled,
+			CancellationReason: o.CancellationReason,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *ScheduleHandlers) GetClassTypes(w http.ResponseWriter, r *http.Request) error {
+	classTypes, err := h.sService.GetClassTypes(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]ClassTypeResponse, 0, len(classTypes))
+	for _, classType := range classTypes {
+		resp = append(resp, toClassTypeResponse(classType))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *ScheduleHandlers) CreateClassType(w http.ResponseWriter, r *http.Request) error {
+	var createRequest CreateClassTypeRequest
+	if err := validate.DecodeJSON(w, r, &createRequest); err != nil {
+		return err
+	}
+
+	classType, err := h.sService.CreateClassType(
+		r.Context(),
+		createRequest.Name,
+		createRequest.Description,
+		createRequest.DurationMinutes,
+		toPenaltyPolicy(createRequest.PenaltyPolicyRequest),
+	)
+	if err != nil {
+		if errors.Is(err, ErrInvalidClassType) {
+			return apperror.New(http.StatusBadRequest, "invalid_class_type", "A name and a positive duration are required").Wrap(err)
+		}
+		if errors.Is(err, ErrInvalidPenaltyPolicy) {
+			return apperror.New(http.StatusBadRequest, "invalid_penalty_policy", err.Error()).Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toClassTypeResponse(*classType), http.StatusCreated)
+	return nil
+}
+
+func (h *ScheduleHandlers) UpdatePenaltyPolicy(w http.ResponseWriter, r *http.Request) error {
+	classTypeID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Class type ID is invalid")
+	}
+
+	var policyRequest PenaltyPolicyRequest
+	if err := validate.DecodeJSON(w, r, &policyRequest); err != nil {
+		return err
+	}
+
+	classType, err := h.sService.UpdatePenaltyPolicy(r.Context(), classTypeID, toPenaltyPolicy(policyRequest))
+	if err != nil {
+		if errors.Is(err, ErrClassTypeDoesntExist) {
+			return apperror.New(http.StatusNotFound, "class_type_doesnt_exist", "Class type does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrInvalidPenaltyPolicy) {
+			return apperror.New(http.StatusBadRequest, "invalid_penalty_policy", err.Error()).Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toClassTypeResponse(*classType), http.StatusOK)
+	return nil
+}
+
+func (h *ScheduleHandlers) GetRooms(w http.ResponseWriter, r *http.Request) error {
+	rooms, err := h.sService.GetRooms(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]RoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		resp = append(resp, toRoomResponse(room))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *ScheduleHandlers) CreateRoom(w http.ResponseWriter, r *http.Request) error {
+	var createRequest CreateRoomRequest
+	if err := validate.DecodeJSON(w, r, &createRequest); err != nil {
+		return err
+	}
+
+	room, err := h.sService.CreateRoom(r.Context(), createRequest.Name, createRequest.Capacity)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRoom) {
+			return apperror.New(http.StatusBadRequest, "invalid_room", "A name and a positive capacity are required").Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toRoomResponse(*room), http.StatusCreated)
+	return nil
+}
+
+func (h *ScheduleHandlers) CreateSchedule(w http.ResponseWriter, r *http.Request) error {
+	var createRequest CreateScheduleRequest
+	if err := validate.DecodeJSON(w, r, &createRequest); err != nil {
+		return err
+	}
+
+	loc, err := time.LoadLocation(createRequest.Timezone)
+	if err != nil {
+		return apperror.BadRequest("Timezone is invalid")
+	}
+
+	startsAt, err := time.ParseInLocation(localDateTimeLayout, createRequest.StartsAt, loc)
+	if err != nil {
+		return apperror.BadRequest("starts_at must be a local time like 2025-06-02T19:00")
+	}
+
+	schedule, err := h.sService.CreateSchedule(r.Context(), NewSchedule{
+		ClassTypeID: createRequest.ClassTypeID,
+		RoomID:      createRequest.RoomID,
+		CoachID:     createRequest.CoachID,
+		StartsAt:    startsAt,
+		RRule:       createRequest.RRule,
+		Timezone:    createRequest.Timezone,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidRRule) || errors.Is(err, ErrInvalidTimezone) || errors.Is(err, ErrInvalidSchedule) {
+			return apperror.BadRequest(err.Error())
+		}
+		return err
+	}
+
+	resp := ScheduleResponse{
+		ID:          schedule.ID,
+		ClassTypeID: schedule.ClassTypeID,
+		RoomID:      schedule.RoomID,
+		CoachID:     fromInt4(schedule.CoachID),
+		StartsAt:    schedule.Dtstart.In(loc),
+		RRule:       schedule.Rrule,
+		Timezone:    schedule.Timezone,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+func (h *ScheduleHandlers) CancelOccurrence(w http.ResponseWriter, r *http.Request) error {
+	scheduleID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Schedule ID is invalid")
+	}
+
+	var cancelRequest CancelOccurrenceRequest
+	if err := validate.DecodeJSON(w, r, &cancelRequest); err != nil {
+		return err
+	}
+
+	if _, err := h.sService.CancelOccurrence(r.Context(), scheduleID, cancelRequest.StartsAt, cancelRequest.Reason); err != nil {
+		if errors.Is(err, ErrScheduleDoesntExist) {
+			return apperror.New(http.StatusNotFound, "schedule_doesnt_exist", "Schedule does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrNotAnOccurrence) {
+			return apperror.New(http.StatusBadRequest, "not_an_occurrence", "The schedule has no class at this time").Wrap(err)
+		}
+		return err
+	}
+
+	users.WriteSuccess(w, "Class cancelled", http.StatusCreated)
+	return nil
+}
+
+func (h *ScheduleHandlers) CreateClosure(w http.ResponseWriter, r *http.Request) error {
+	var createRequest CreateClosureRequest
+	if err := validate.DecodeJSON(w, r, &createRequest); err != nil {
+		return err
+	}
+
+	closure, err := h.sService.CreateClosure(r.Context(), createRequest.StartsAt, createRequest.EndsAt, createRequest.Reason)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			return apperror.New(http.StatusBadRequest, "invalid_range", "ends_at must be after starts_at").Wrap(err)
+		}
+		return err
+	}
+
+	resp := ClosureResponse{
+		ID:       closure.ID,
+		StartsAt: closure.StartsAt,
+		EndsAt:   closure.EndsAt,
+		Reason:   closure.Reason,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+// ParseTimeParam parses a query parameter holding either an RFC 3339 time or
+// a date, which is taken as midnight in loc.
+func ParseTimeParam(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", v, err)
+	}
+	return t, nil
+}
+
+func toClassTypeResponse(classType repository.ClassType) ClassTypeResponse {
+	return ClassTypeResponse{
+		ID:                        classType.ID,
+		Name:                      classType.Name,
+		Description:               classType.Description,
+		DurationMinutes:           classType.DurationMinutes,
+		CancellationWindowMinutes: classType.CancellationWindowMinutes,
+		LateCancelPenalty:         classType.LateCancelPenalty,
+		NoShowPenalty:             classType.NoShowPenalty,
+		PenaltyFeeCents:           classType.PenaltyFeeCents,
+	}
+}
+
+func toPenaltyPolicy(req PenaltyPolicyRequest) PenaltyPolicy {
+	return PenaltyPolicy{
+		CancellationWindowMinutes: req.CancellationWindowMinutes,
+		LateCancelPenalty:         penalties.Policy(req.LateCancelPenalty),
+		NoShowPenalty:             penalties.Policy(req.NoShowPenalty),
+		FeeCents:                  req.PenaltyFeeCents,
+	}
+}
+
+func toRoomResponse(room repository.Room) RoomResponse {
+	return RoomResponse{
+		ID:       room.ID,
+		Name:     room.Name,
+		Capacity: room.Capacity,
+	}
+}
+
+func fromInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
@@ -0,0 +1,21 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IScheduleService interface {
+	GetClassTypes(ctx context.Context) ([]repository.ClassType, error)
+	CreateClassType(ctx context.Context, name, description string, durationMinutes int32, policy PenaltyPolicy) (*repository.ClassType, error)
+	UpdatePenaltyPolicy(ctx context.Context, classTypeID int32, policy PenaltyPolicy) (*repository.ClassType, error)
+	GetRooms(ctx context.Context) ([]repository.Room, error)
+	CreateRoom(ctx context.Context, name string, capacity int32) (*repository.Room, error)
+	CreateSchedule(ctx context.Context, schedule NewSchedule) (*repository.ClassSchedule, error)
+	CancelOccurrence(ctx context.Context, scheduleID int32, startsAt time.Time, reason string) (*repository.ClassScheduleException, error)
+	CreateClosure(ctx context.Context, startsAt, endsAt time.Time, reason string) (*repository.Closure, error)
+	GetSchedule(ctx context.Context, from, to time.Time) ([]Occurrence, error)
+	CreateSessions(ctx context.Context) error
+}
@@ -0,0 +1,473 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/penalties"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/teambition/rrule-go"
+)
+
+// maxScheduleRange bounds how many days of the timetable can be expanded in a
+// single request.
+const maxScheduleRange = 62 * 24 * time.Hour
+
+// sessionHorizon is how far ahead CreateSessions creates class sessions, and
+// so how far ahead classes can be booked.
+const sessionHorizon = 28 * 24 * time.Hour
+
+// NewSchedule holds the fields staff provide when adding a recurring class.
+type NewSchedule struct {
+	ClassTypeID int32
+	RoomID      int32
+	CoachID     *int32
+	// StartsAt is the first occurrence, in the schedule's timezone
+	StartsAt time.Time
+	RRule    string
+	Timezone string
+}
+
+// PenaltyPolicy is how a class type handles late cancellations and no-shows.
+type PenaltyPolicy struct {
+	// CancellationWindowMinutes is how long before the class cancelling
+	// counts as late, zero means never
+	CancellationWindowMinutes int32
+	LateCancelPenalty         penalties.Policy
+	NoShowPenalty             penalties.Policy
+	// FeeCents is charged by the fee policies
+	FeeCents int32
+}
+
+// Occurrence is a concrete session of a recurring class.
+type Occurrence struct {
+	// SessionID identifies the bookable class session, it is zero for
+	// cancelled occurrences and ones beyond sessionHorizon
+	SessionID          int32
+	ScheduleID         int32
+	ClassTypeID        int32
+	ClassTypeName      string
+	RoomID             int32
+	RoomName           string
+	CoachID            pgtype.Int4
+	StartsAt           time.Time
+	EndsAt             time.Time
+	Timezone           string
+	Capacity           int32
+	Cancelled          bool
+	CancellationReason string
+}
+
+type ScheduleService struct {
+	repository *repository.Queries
+}
+
+func NewScheduleService(
+	repository *repository.Queries,
+) *ScheduleService {
+	return &ScheduleService{
+		repository: repository,
+	}
+}
+
+var (
+	ErrInvalidClassType     = errors.New("class type is invalid")
+	ErrClassTypeDoesntExist = errors.New("class type does not exist")
+	ErrInvalidPenaltyPolicy = errors.New("penalty policy is invalid")
+	ErrInvalidRoom          = errors.New("room is invalid")
+	ErrInvalidRRule         = errors.New("recurrence rule is invalid")
+	ErrInvalidTimezone      = errors.New("timezone is invalid")
+	ErrInvalidRange         = errors.New("time range is invalid")
+	ErrRangeTooLarge        = errors.New("time range is too large")
+	ErrScheduleDoesntExist  = errors.New("schedule does not exist")
+	ErrNotAnOccurrence      = errors.New("the schedule has no class at this time")
+	ErrInvalidSchedule      = errors.New("class type, room or coach does not exist")
+)
+
+func (s *ScheduleService) GetClassTypes(ctx context.Context) ([]repository.ClassType, error) {
+	return s.repository.GetClassTypes(ctx)
+}
+
+func (s *ScheduleService) CreateClassType(ctx context.Context, name, description string, durationMinutes int32, policy PenaltyPolicy) (*repository.ClassType, error) {
+	if name == "" || durationMinutes <= 0 {
+		return nil, ErrInvalidClassType
+	}
+	policy, err := validatePenaltyPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	classType, err := s.repository.CreateClassType(ctx, repository.CreateClassTypeParams{
+		Name:                      name,
+		Description:               description,
+		DurationMinutes:           durationMinutes,
+		CancellationWindowMinutes: policy.CancellationWindowMinutes,
+		LateCancelPenalty:         string(policy.LateCancelPenalty),
+		NoShowPenalty:             string(policy.NoShowPenalty),
+		PenaltyFeeCents:           policy.FeeCents,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create class type in db: %w", err)
+	}
+
+	return &classType, nil
+}
+
+func (s *ScheduleService) UpdatePenaltyPolicy(ctx context.Context, classTypeID int32, policy PenaltyPolicy) (*repository.ClassType, error) {
+	policy, err := validatePenaltyPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	classType, err := s.repository.UpdateClassTypePenaltyPolicy(ctx, repository.UpdateClassTypePenaltyPolicyParams{
+		ID:                        classTypeID,
+		CancellationWindowMinutes: policy.CancellationWindowMinutes,
+		LateCancelPenalty:         string(policy.LateCancelPenalty),
+		NoShowPenalty:             string(policy.NoShowPenalty),
+		PenaltyFeeCents:           policy.FeeCents,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClassTypeDoesntExist
+		}
+		return nil, fmt.Errorf("failed to update class type in db: %w", err)
+	}
+
+	return &classType, nil
+}
+
+// validatePenaltyPolicy checks a policy, defaulting missing penalties to none.
+func validatePenaltyPolicy(policy PenaltyPolicy) (PenaltyPolicy, error) {
+	if policy.LateCancelPenalty == "" {
+		policy.LateCancelPenalty = penalties.PolicyNone
+	}
+	if policy.NoShowPenalty == "" {
+		policy.NoShowPenalty = penalties.PolicyNone
+	}
+
+	if !policy.LateCancelPenalty.IsValid() || !policy.NoShowPenalty.IsValid() {
+		return policy, fmt.Errorf("%w: penalties must be none, credit or fee", ErrInvalidPenaltyPolicy)
+	}
+	if policy.CancellationWindowMinutes < 0 || policy.FeeCents < 0 {
+		return policy, fmt.Errorf("%w: window and fee can't be negative", ErrInvalidPenaltyPolicy)
+	}
+	usesFee := policy.LateCancelPenalty == penalties.PolicyFee || policy.NoShowPenalty == penalties.PolicyFee
+	if usesFee && policy.FeeCents == 0 {
+		return policy, fmt.Errorf("%w: fee penalties need a fee", ErrInvalidPenaltyPolicy)
+	}
+
+	return policy, nil
+}
+
+func (s *ScheduleService) GetRooms(ctx context.Context) ([]repository.Room, error) {
+	return s.repository.GetRooms(ctx)
+}
+
+func (s *ScheduleService) CreateRoom(ctx context.Context, name string, capacity int32) (*repository.Room, error) {
+	if name == "" || capacity <= 0 {
+		return nil, ErrInvalidRoom
+	}
+
+	room, err := s.repository.CreateRoom(ctx, repository.CreateRoomParams{
+		Name:     name,
+		Capacity: capacity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create room in db: %w", err)
+	}
+
+	return &room, nil
+}
+
+func (s *ScheduleService) CreateSchedule(ctx context.Context, schedule NewSchedule) (*repository.ClassSchedule, error) {
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+
+	if _, err := rrule.StrToROption(schedule.RRule); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRRule, err)
+	}
+
+	var coachID pgtype.Int4
+	if schedule.CoachID != nil {
+		coachID = pgtype.Int4{Int32: *schedule.CoachID, Valid: true}
+	}
+
+	created, err := s.repository.CreateClassSchedule(ctx, repository.CreateClassScheduleParams{
+		ClassTypeID: schedule.ClassTypeID,
+		RoomID:      schedule.RoomID,
+		CoachID:     coachID,
+		Dtstart:     schedule.StartsAt,
+		Rrule:       schedule.RRule,
+		Timezone:    schedule.Timezone,
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, ErrInvalidSchedule
+		}
+		return nil, fmt.Errorf("failed to create class schedule in db: %w", err)
+	}
+
+	// The schedule exists either way, the next run of the job creates the
+	// sessions if this fails
+	if err := s.CreateSessions(ctx); err != nil {
+		logging.FromContext(ctx).Error("Failed to create class sessions", slog.Any("error", err))
+	}
+
+	return &created, nil
+}
+
+func (s *ScheduleService) CancelOccurrence(ctx context.Context, scheduleID int32, startsAt time.Time, reason string) (*repository.ClassScheduleException, error) {
+	schedule, err := s.repository.GetClassScheduleByID(ctx, scheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScheduleDoesntExist
+		}
+		return nil, err
+	}
+
+	// Only cancel times the schedule actually produces, otherwise the
+	// exception would never match an occurrence.
+	occurrences, err := expand(schedule, startsAt, startsAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(occurrences) == 0 {
+		return nil, ErrNotAnOccurrence
+	}
+
+	exception, err := s.repository.CreateClassScheduleException(ctx, repository.CreateClassScheduleExceptionParams{
+		ScheduleID: scheduleID,
+		StartsAt:   startsAt,
+		Reason:     reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule exception in db: %w", err)
+	}
+
+	if err := s.repository.CancelClassSession(ctx, repository.CancelClassSessionParams{
+		ScheduleID: scheduleID,
+		StartsAt:   startsAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to cancel class session in db: %w", err)
+	}
+
+	return &exception, nil
+}
+
+func (s *ScheduleService) CreateClosure(ctx context.Context, startsAt, endsAt time.Time, reason string) (*repository.Closure, error) {
+	if !endsAt.After(startsAt) {
+		return nil, ErrInvalidRange
+	}
+
+	closure, err := s.repository.CreateClosure(ctx, repository.CreateClosureParams{
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Reason:   reason,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create closure in db: %w", err)
+	}
+
+	if err := s.repository.CancelClassSessionsBetween(ctx, repository.CancelClassSessionsBetweenParams{
+		FromTime: startsAt,
+		ToTime:   endsAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to cancel class sessions in db: %w", err)
+	}
+
+	return &closure, nil
+}
+
+// GetSchedule expands every recurring class into the sessions starting in
+// [from, to). Sessions that fall on a closure or were cancelled are included
+// and flagged as cancelled. It only reads, sessions are created by
+// CreateSessions.
+func (s *ScheduleService) GetSchedule(ctx context.Context, from, to time.Time) ([]Occurrence, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	if to.Sub(from) > maxScheduleRange {
+		return nil, ErrRangeTooLarge
+	}
+
+	occurrences, err := s.expandSchedules(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repository.GetClassSessionsBetween(ctx, repository.GetClassSessionsBetweenParams{
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class sessions: %w", err)
+	}
+
+	for i := range occurrences {
+		o := &occurrences[i]
+		if o.Cancelled {
+			continue
+		}
+		for _, session := range sessions {
+			if session.ScheduleID == o.ScheduleID && session.StartsAt.Equal(o.StartsAt) {
+				o.SessionID = session.ID
+				o.Capacity = session.Capacity
+				break
+			}
+		}
+	}
+
+	return occurrences, nil
+}
+
+// CreateSessions creates the bookable class sessions of every occurrence that
+// is still on within sessionHorizon. Existing sessions are kept as they are.
+// It is run periodically and whenever a schedule is added.
+func (s *ScheduleService) CreateSessions(ctx context.Context) error {
+	from := time.Now()
+	occurrences, err := s.expandSchedules(ctx, from, from.Add(sessionHorizon))
+	if err != nil {
+		return err
+	}
+
+	var params repository.CreateClassSessionsParams
+	for _, o := range occurrences {
+		if o.Cancelled {
+			continue
+		}
+		params.ScheduleIds = append(params.ScheduleIds, o.ScheduleID)
+		params.StartsAts = append(params.StartsAts, o.StartsAt)
+		params.EndsAts = append(params.EndsAts, o.EndsAt)
+		params.Capacities = append(params.Capacities, o.Capacity)
+	}
+	if len(params.ScheduleIds) == 0 {
+		return nil
+	}
+
+	if err := s.repository.CreateClassSessions(ctx, params); err != nil {
+		return fmt.Errorf("failed to create class sessions in db: %w", err)
+	}
+	return nil
+}
+
+// expandSchedules expands every recurring class into the occurrences starting
+// in [from, to), sorted by start time.
+func (s *ScheduleService) expandSchedules(ctx context.Context, from, to time.Time) ([]Occurrence, error) {
+	schedules, err := s.repository.GetScheduledClasses(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get class schedules: %w", err)
+	}
+
+	exceptions, err := s.repository.GetClassScheduleExceptionsBetween(ctx, repository.GetClassScheduleExceptionsBetweenParams{
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule exceptions: %w", err)
+	}
+
+	closures, err := s.repository.GetClosuresBetween(ctx, repository.GetClosuresBetweenParams{
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closures: %w", err)
+	}
+
+	occurrences := []Occurrence{}
+	for _, row := range schedules {
+		schedule := repository.ClassSchedule{
+			ID:          row.ID,
+			ClassTypeID: row.ClassTypeID,
+			RoomID:      row.RoomID,
+			CoachID:     row.CoachID,
+			Dtstart:     row.Dtstart,
+			Rrule:       row.Rrule,
+			Timezone:    row.Timezone,
+		}
+
+		startTimes, err := expand(schedule, from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := time.Duration(row.DurationMinutes) * time.Minute
+		for _, startsAt := range startTimes {
+			occurrence := Occurrence{
+				ScheduleID:    row.ID,
+				ClassTypeID:   row.ClassTypeID,
+				ClassTypeName: row.ClassTypeName,
+				RoomID:        row.RoomID,
+				RoomName:      row.RoomName,
+				CoachID:       row.CoachID,
+				StartsAt:      startsAt,
+				EndsAt:        startsAt.Add(duration),
+				Timezone:      row.Timezone,
+				Capacity:      row.Capacity,
+			}
+			cancelOccurrence(&occurrence, exceptions, closures)
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	slices.SortFunc(occurrences, func(a, b Occurrence) int {
+		return a.StartsAt.Compare(b.StartsAt)
+	})
+
+	return occurrences, nil
+}
+
+// expand returns the start times of a schedule's occurrences in [from, to),
+// or exactly at from when from and to are equal. The rule is evaluated in the
+// schedule's timezone so the local start time is kept across DST changes.
+func expand(schedule repository.ClassSchedule, from, to time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule %d: %w", ErrInvalidTimezone, schedule.ID, err)
+	}
+
+	opt, err := rrule.StrToROption(schedule.Rrule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule %d: %w", ErrInvalidRRule, schedule.ID, err)
+	}
+	opt.Dtstart = schedule.Dtstart.In(loc)
+
+	rule, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule %d: %w", ErrInvalidRRule, schedule.ID, err)
+	}
+
+	startTimes := rule.Between(from, to, true)
+	if from.Equal(to) {
+		return startTimes, nil
+	}
+	return slices.DeleteFunc(startTimes, func(t time.Time) bool {
+		return !t.Before(to)
+	}), nil
+}
+
+func cancelOccurrence(occurrence *Occurrence, exceptions []repository.ClassScheduleException, closures []repository.Closure) {
+	for _, exception := range exceptions {
+		if exception.ScheduleID == occurrence.ScheduleID && exception.StartsAt.Equal(occurrence.StartsAt) {
+			occurrence.Cancelled = true
+			occurrence.CancellationReason = exception.Reason
+			return
+		}
+	}
+
+	for _, closure := range closures {
+		if occurrence.StartsAt.Before(closure.EndsAt) && occurrence.EndsAt.After(closure.StartsAt) {
+			occurrence.Cancelled = true
+			occurrence.CancellationReason = closure.Reason
+			return
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package schedule
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+func TestExpand(t *testing.T) {
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	// A 19:00 class on Wednesdays in Madrid, starting the week before clocks go
+	// forward on 29 March
+	evening := repository.ClassSchedule{
+		ID:       1,
+		Dtstart:  time.Date(2026, time.March, 18, 19, 0, 0, 0, madrid),
+		Rrule:    "FREQ=WEEKLY;BYDAY=WE",
+		Timezone: "Europe/Madrid",
+	}
+	withRule := func(schedule repository.ClassSchedule, rule string) repository.ClassSchedule {
+		schedule.Rrule = rule
+		return schedule
+	}
+	withTimezone := func(schedule repository.ClassSchedule, timezone string) repository.ClassSchedule {
+		schedule.Timezone = timezone
+		return schedule
+	}
+
+	tests := []struct {
+		name     string
+		schedule repository.ClassSchedule
+		from, to time.Time
+		want     []time.Time
+		wantErr  error
+	}{
+		{
+			name:     "start of summer time",
+			schedule: evening,
+			from:     utc(time.March, 18, 0),
+			to:       utc(time.April, 2, 0),
+			want:     []time.Time{utc(time.March, 18, 18), utc(time.March, 25, 18), utc(time.April, 1, 17)},
+		},
+		{
+			// Clocks go back on 25 October
+			name: "end of summer time",
+			schedule: repository.ClassSchedule{
+				Dtstart:  time.Date(2026, time.October, 21, 19, 0, 0, 0, madrid),
+				Rrule:    "FREQ=WEEKLY;BYDAY=WE",
+				Timezone: "Europe/Madrid",
+			},
+			from: utc(time.October, 21, 0),
+			to:   utc(time.November, 5, 0),
+			want: []time.Time{utc(time.October, 21, 17), utc(time.October, 28, 18), utc(time.November, 4, 18)},
+		},
+		{
+			name:     "from is inclusive",
+			schedule: evening,
+			from:     utc(time.March, 25, 18),
+			to:       utc(time.March, 26, 0),
+			want:     []time.Time{utc(time.March, 25, 18)},
+		},
+		{
+			name:     "to is exclusive",
+			schedule: evening,
+			from:     utc(time.March, 19, 0),
+			to:       utc(time.March, 25, 18),
+		},
+		{
+			name:     "from and to at an occurrence",
+			schedule: evening,
+			from:     utc(time.March, 25, 18),
+			to:       utc(time.March, 25, 18),
+			want:     []time.Time{utc(time.March, 25, 18)},
+		},
+		{
+			name:     "from and to between occurrences",
+			schedule: evening,
+			from:     utc(time.March, 25, 17),
+			to:       utc(time.March, 25, 17),
+		},
+		{
+			name:     "before the first occurrence",
+			schedule: evening,
+			from:     utc(time.March, 1, 0),
+			to:       utc(time.March, 18, 0),
+		},
+		{
+			name:     "rule with a count",
+			schedule: withRule(evening, "FREQ=WEEKLY;BYDAY=WE;COUNT=2"),
+			from:     utc(time.March, 1, 0),
+			to:       utc(time.May, 1, 0),
+			want:     []time.Time{utc(time.March, 18, 18), utc(time.March, 25, 18)},
+		},
+		{
+			name:     "invalid rule",
+			schedule: withRule(evening, "FREQ=SOMETIMES"),
+			from:     utc(time.March, 1, 0),
+			to:       utc(time.May, 1, 0),
+			wantErr:  ErrInvalidRRule,
+		},
+		{
+			name:     "invalid timezone",
+			schedule: withTimezone(evening, "Europe/Atlantis"),
+			from:     utc(time.March, 1, 0),
+			to:       utc(time.May, 1, 0),
+			wantErr:  ErrInvalidTimezone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expand(tt.schedule, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expand() error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("expand() = %v, want %v", got, tt.want)
+			}
+			for _, startsAt := range got {
+				if local := startsAt.In(madrid); local.Hour() != 19 || local.Minute() != 0 {
+					t.Errorf("occurrence at %v local time, want 19:00", local)
+				}
+			}
+		})
+	}
+}
+
+func TestCancelOccurrence(t *testing.T) {
+	startsAt := time.Date(2026, time.March, 25, 18, 0, 0, 0, time.UTC)
+	exceptions := []repository.ClassScheduleException{
+		{ScheduleID: 2, StartsAt: startsAt, Reason: "Other class"},
+		{ScheduleID: 1, StartsAt: startsAt.AddDate(0, 0, 7), Reason: "Other week"},
+		{ScheduleID: 1, StartsAt: startsAt, Reason: "Coach is away"},
+	}
+
+	tests := []struct {
+		name       string
+		exceptions []repository.ClassScheduleException
+		closures   []repository.Closure
+		wantReason string
+	}{
+		{name: "nothing cancelled"},
+		{name: "exception for the occurrence", exceptions: exceptions, wantReason: "Coach is away"},
+		{name: "exceptions for other occurrences", exceptions: exceptions[:2]},
+		{
+			name: "closure overlapping the start",
+			closures: []repository.Closure{
+				{StartsAt: startsAt.Add(-time.Hour), EndsAt: startsAt.Add(time.Minute), Reason: "Flooded"},
+			},
+			wantReason: "Flooded",
+		},
+		{
+			name: "closure overlapping the end",
+			closures: []repository.Closure{
+				{StartsAt: startsAt.Add(59 * time.Minute), EndsAt: startsAt.Add(2 * time.Hour), Reason: "Flooded"},
+			},
+			wantReason: "Flooded",
+		},
+		{
+			name: "closures touching the occurrence",
+			closures: []repository.Closure{
+				{StartsAt: startsAt.Add(-time.Hour), EndsAt: startsAt, Reason: "Before"},
+				{StartsAt: startsAt.Add(time.Hour), EndsAt: startsAt.Add(2 * time.Hour), Reason: "After"},
+			},
+		},
+		{
+			name:       "exception before a closure",
+			exceptions: exceptions,
+			closures: []repository.Closure{
+				{StartsAt: startsAt.AddDate(0, 0, -1), EndsAt: startsAt.AddDate(0, 0, 1), Reason: "Holiday"},
+			},
+			wantReason: "Coach is away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrence := Occurrence{ScheduleID: 1, StartsAt: startsAt, EndsAt: startsAt.Add(time.Hour)}
+			cancelOccurrence(&occurrence, tt.exceptions, tt.closures)
+
+			if occurrence.Cancelled != (tt.wantReason != "") {
+				t.Errorf("Cancelled = %v, want %v", occurrence.Cancelled, tt.wantReason != "")
+			}
+			if occurrence.CancellationReason != tt.wantReason {
+				t.Errorf("CancellationReason = %q, want %q", occurrence.CancellationReason, tt.wantReason)
+			}
+		})
+	}
+}
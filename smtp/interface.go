@@ -1,5 +1,15 @@
 package smtp
 
+import (
+	"context"
+	"time"
+)
+
 type ISMTPService interface {
-	SendVerificationEmail(to, verificationCode string) error
+	SendVerificationEmail(ctx context.Context, to, verificationCode string) error
+	SendWaitlistPromotionEmail(ctx context.Context, to, className string, startsAt time.Time) error
+	SendPenaltyEmail(ctx context.Context, to, className string, startsAt time.Time, explanation []string) error
+	SendTrainingConfirmationEmail(ctx context.Context, to, with string, startsAt, endsAt time.Time) error
+	SendTrainingReminderEmail(ctx context.Context, to, with string, startsAt, endsAt time.Time) error
+	SendWeightCutAlertEmail(ctx context.Context, to, fighterName string, fightDate time.Time, targetKg, projectedKg, cutPercent float64) error
 }
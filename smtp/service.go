@@ -2,14 +2,34 @@ package smtp
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"html"
+	"net"
 	"net/smtp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/grez-lucas/boxer66-service/internal/config"
+	"github.com/grez-lucas/boxer66-service/internal/metrics"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var ErrClosed = errors.New("smtp service is shut down")
+
+var tracer = otel.Tracer("github.com/grez-lucas/boxer66-service/smtp")
+
 type SMTPService struct {
 	cfg config.SMTPConfig
+
+	mu       sync.RWMutex
+	closed   bool
+	inFlight sync.WaitGroup
 }
 
 func NewSMTPService(cfg config.SMTPConfig) *SMTPService {
@@ -18,7 +38,7 @@ func NewSMTPService(cfg config.SMTPConfig) *SMTPService {
 	}
 }
 
-func (s *SMTPService) SendVerificationEmail(to, verificationCode string) error {
+func (s *SMTPService) SendVerificationEmail(ctx context.Context, to, verificationCode string) error {
 	subject := "Boxer66 Verification Code"
 	body := fmt.Sprintf(`
 		<html>
@@ -36,13 +56,156 @@ func (s *SMTPService) SendVerificationEmail(to, verificationCode string) error {
 		</html>
 		`, subject, verificationCode)
 
-	if err := s.SendEmail(to, subject, body); err != nil {
-		return fmt.Errorf("failed to send email to recipient %s: %w", to, err)
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPService) SendWaitlistPromotionEmail(ctx context.Context, to, className string, startsAt time.Time) error {
+	subject := "Boxer66 - You're off the waitlist"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<title>%s</title>
+		</head>
+		<body>
+			<p> Hi there,</p>
+			<p>A spot opened up and you are now booked for:</p>
+			<h3>%s</h3>
+			<p>%s</p>
+			<p>If you can no longer make it, please cancel your booking so someone else can take the spot.</p>
+			<p>Thanks,</p>
+			<p>Boxer66 Team</p>
+		</body>
+		</html>
+		`, subject, className, startsAt.Format("Monday, January 2 at 15:04 MST"))
+
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPService) SendPenaltyEmail(ctx context.Context, to, className string, startsAt time.Time, explanation []string) error {
+	subject := "Boxer66 - About your booking"
+
+	var paragraphs strings.Builder
+	for _, line := range explanation {
+		paragraphs.WriteString(fmt.Sprintf("<p>%s</p>\n", html.EscapeString(line)))
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<title>%s</title>
+		</head>
+		<body>
+			<p> Hi there,</p>
+			<p>This is about your booking for:</p>
+			<h3>%s</h3>
+			<p>%s</p>
+			%s
+			<p>If you think this is a mistake, please talk to the front desk.</p>
+			<p>Thanks,</p>
+			<p>Boxer66 Team</p>
+		</body>
+		</html>
+		`, subject, className, startsAt.Format("Monday, January 2 at 15:04 MST"), paragraphs.String())
+
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
 	}
 	return nil
 }
 
-func (s *SMTPService) SendEmail(to, subject, body string) error {
+func (s *SMTPService) SendTrainingConfirmationEmail(ctx context.Context, to, with string, startsAt, endsAt time.Time) error {
+	subject := "Boxer66 - Training session confirmed"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<title>%s</title>
+		</head>
+		<body>
+			<p> Hi there,</p>
+			<p>Your training session with %s is confirmed for:</p>
+			<h3>%s - %s</h3>
+			<p>If you can no longer make it, please cancel or reschedule the session in the app.</p>
+			<p>Thanks,</p>
+			<p>Boxer66 Team</p>
+		</body>
+		</html>
+		`, subject, html.EscapeString(with), startsAt.Format("Monday, January 2 at 15:04"), endsAt.Format("15:04 MST"))
+
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPService) SendTrainingReminderEmail(ctx context.Context, to, with string, startsAt, endsAt time.Time) error {
+	subject := "Boxer66 - Training session reminder"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<title>%s</title>
+		</head>
+		<body>
+			<p> Hi there,</p>
+			<p>This is a reminder of your upcoming training session with %s:</p>
+			<h3>%s - %s</h3>
+			<p>See you there,</p>
+			<p>Boxer66 Team</p>
+		</body>
+		</html>
+		`, subject, html.EscapeString(with), startsAt.Format("Monday, January 2 at 15:04"), endsAt.Format("15:04 MST"))
+
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPService) SendWeightCutAlertEmail(ctx context.Context, to, fighterName string, fightDate time.Time, targetKg, projectedKg, cutPercent float64) error {
+	subject := "Boxer66 - Unsafe weight cut ahead"
+	body := fmt.Sprintf(`
+		<html>
+		<head>
+			<title>%s</title>
+		</head>
+		<body>
+			<p> Hi there,</p>
+			<p>Based on their recent weigh-ins, %s is on track to weigh %.1f kg on %s, against a target of %.1f kg.</p>
+			<h3>That leaves %.1f%% of their body weight to cut on fight day.</h3>
+			<p>Please check in with them about their weight plan.</p>
+			<p>Thanks,</p>
+			<p>Boxer66 Team</p>
+		</body>
+		</html>
+		`, subject, html.EscapeString(fighterName), projectedKg, fightDate.Format("Monday, January 2"), targetKg, cutPercent)
+
+	if err := s.SendEmail(ctx, to, subject, body); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPService) SendEmail(ctx context.Context, to, subject, body string) (err error) {
+	_, span := tracer.Start(ctx, "SMTPService.SendEmail",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("email.subject", subject)),
+	)
+	defer tracing.End(span, &err)
+
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return ErrClosed
+	}
+	s.inFlight.Add(1)
+	s.mu.RUnlock()
+	defer s.inFlight.Done()
+
 	var msg bytes.Buffer
 
 	msg.WriteString(fmt.Sprintf("To: %s\r\n", to))
@@ -54,5 +217,41 @@ func (s *SMTPService) SendEmail(to, subject, body string) error {
 	msg.WriteString(body)
 
 	auth := smtp.PlainAuth("", s.cfg.User, s.cfg.Password, s.cfg.Host)
-	return smtp.SendMail(s.cfg.Host+":"+s.cfg.Port, auth, s.cfg.User, []string{to}, msg.Bytes())
+	if err := smtp.SendMail(s.cfg.Host+":"+s.cfg.Port, auth, s.cfg.User, []string{to}, msg.Bytes()); err != nil {
+		metrics.Emails.WithLabelValues(metrics.ResultFailure).Inc()
+		return err
+	}
+	metrics.Emails.WithLabelValues(metrics.ResultSuccess).Inc()
+	return nil
+}
+
+// Close rejects new emails and waits for the ones being sent until ctx is
+// done.
+func (s *SMTPService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("emails still being sent: %w", ctx.Err())
+	}
+}
+
+// Ping checks that the mail server accepts connections.
+func (s *SMTPService) Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.cfg.Host, s.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("failed to reach smtp server: %w", err)
+	}
+	return conn.Close()
 }
@@ -0,0 +1,44 @@
+package sparring
+
+import "time"
+
+type FighterResponse struct {
+	ID              int32    `json:"id"`
+	Name            string   `json:"name"`
+	WeightKg        *float64 `json:"weight_kg,omitempty"`
+	WeightEstimated bool     `json:"weight_estimated,omitempty"`
+	Experience      string   `json:"experience,omitempty"`
+	Stance          string   `json:"stance,omitempty"`
+}
+
+type PairingResponse struct {
+	Fighters    [2]FighterResponse `json:"fighters"`
+	WeightGapKg float64            `json:"weight_gap_kg"`
+	// Cost ranks the pairings, lower is better
+	Cost        float64  `json:"cost"`
+	Explanation []string `json:"explanation"`
+}
+
+type UnpairedResponse struct {
+	Fighter FighterResponse `json:"fighter"`
+	Reason  string          `json:"reason"`
+}
+
+type SuggestionResponse struct {
+	SessionID        int32              `json:"session_id"`
+	MaxWeightGapKg   float64            `json:"max_weight_gap_kg"`
+	MaxExperienceGap int                `json:"max_experience_gap"`
+	Pairings         []PairingResponse  `json:"pairings"`
+	Unpaired         []UnpairedResponse `json:"unpaired"`
+}
+
+type RecordPairingsRequest struct {
+	// Pairs are pairs of user IDs
+	Pairs [][2]int32 `json:"pairs"`
+}
+
+type RecordedPairingResponse struct {
+	ID         int32     `json:"id"`
+	FighterIDs [2]int32  `json:"fighter_ids"`
+	CreatedAt  time.Time `json:"created_at"`
+}
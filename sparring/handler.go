@@ -0,0 +1,144 @@
+package sparring
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+type SparringHandlers struct {
+	sService ISparringService
+}
+
+func NewSparringHandlers(sService ISparringService) *SparringHandlers {
+	return &SparringHandlers{
+		sService: sService,
+	}
+}
+
+func (h *SparringHandlers) SuggestPairings(w http.ResponseWriter, r *http.Request) error {
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Session ID is invalid")
+	}
+
+	query := r.URL.Query()
+	constraints := Constraints{
+		MaxWeightGapKg:   DefaultMaxWeightGapKg,
+		MaxExperienceGap: DefaultMaxExperienceGap,
+	}
+	if v := query.Get("max_weight_gap_kg"); v != "" {
+		if constraints.MaxWeightGapKg, err = strconv.ParseFloat(v, 64); err != nil {
+			return apperror.BadRequest("max_weight_gap_kg must be a number")
+		}
+	}
+	if v := query.Get("max_experience_gap"); v != "" {
+		if constraints.MaxExperienceGap, err = strconv.Atoi(v); err != nil {
+			return apperror.BadRequest("max_experience_gap must be a whole number")
+		}
+	}
+
+	suggestion, err := h.sService.SuggestPairings(r.Context(), sessionID, constraints)
+	if err != nil {
+		return sparringError(err)
+	}
+
+	resp := SuggestionResponse{
+		SessionID:        suggestion.SessionID,
+		MaxWeightGapKg:   suggestion.Constraints.MaxWeightGapKg,
+		MaxExperienceGap: suggestion.Constraints.MaxExperienceGap,
+		Pairings:         make([]PairingResponse, 0, len(suggestion.Pairings)),
+		Unpaired:         make([]UnpairedResponse, 0, len(suggestion.Unpaired)),
+	}
+	for _, pairing := range suggestion.Pairings {
+		resp.Pairings = append(resp.Pairings, PairingResponse{
+			Fighters:    [2]FighterResponse{toFighterResponse(pairing.A), toFighterResponse(pairing.B)},
+			WeightGapKg: round(pairing.WeightGapKg),
+			Cost:        round(pairing.Cost),
+			Explanation: pairing.Reasons,
+		})
+	}
+	for _, unpaired := range suggestion.Unpaired {
+		resp.Unpaired = append(resp.Unpaired, UnpairedResponse{
+			Fighter: toFighterResponse(unpaired.Fighter),
+			Reason:  unpaired.Reason,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *SparringHandlers) RecordPairings(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	sessionID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Session ID is invalid")
+	}
+
+	var recordRequest RecordPairingsRequest
+	if err := validate.DecodeJSON(w, r, &recordRequest); err != nil {
+		return err
+	}
+
+	pairings, err := h.sService.RecordPairings(r.Context(), sessionID, recordRequest.Pairs, coachID)
+	if err != nil {
+		return sparringError(err)
+	}
+
+	resp := make([]RecordedPairingResponse, 0, len(pairings))
+	for _, pairing := range pairings {
+		resp = append(resp, RecordedPairingResponse{
+			ID:         pairing.ID,
+			FighterIDs: [2]int32{pairing.FighterAID, pairing.FighterBID},
+			CreatedAt:  pairing.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusCreated)
+	return nil
+}
+
+func sparringError(err error) error {
+	switch {
+	case errors.Is(err, ErrSessionDoesntExist):
+		return apperror.New(http.StatusNotFound, "session_doesnt_exist", "Session does not exist").Wrap(err)
+	case errors.Is(err, ErrInvalidConstraints):
+		return apperror.New(http.StatusBadRequest, "invalid_constraints", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidPairing):
+		return apperror.New(http.StatusBadRequest, "invalid_pairing", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func toFighterResponse(f Fighter) FighterResponse {
+	resp := FighterResponse{
+		ID:              f.UserID,
+		Name:            f.Name,
+		WeightEstimated: f.WeightEstimated,
+		Experience:      f.Experience,
+		Stance:          f.Stance,
+	}
+	if f.HasWeight {
+		weight := round(f.WeightKg)
+		resp.WeightKg = &weight
+	}
+	return resp
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,12 @@
+package sparring
+
+import (
+	"context"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type ISparringService interface {
+	SuggestPairings(ctx context.Context, sessionID int32, constraints Constraints) (*Suggestion, error)
+	RecordPairings(ctx context.Context, sessionID int32, pairs [][2]int32, coachID int32) ([]repository.SparringPairing, error)
+}
@@ -0,0 +1,191 @@
+package sparring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/grez-lucas/boxer66-service/fighters"
+)
+
+// Weights of the soft criteria in a pairing's cost. A full MaxWeightGapKg
+// costs as much as weightCost, each experience level apart as much as
+// experienceCost and each recent pairing as much as repeatCost.
+const (
+	weightCost       = 3.0
+	experienceCost   = 2.0
+	stanceCost       = 0.5
+	repeatCost       = 1.5
+	historyRangeDays = 14
+)
+
+var experienceRanks = map[string]int{
+	fighters.ExperienceBeginner:     0,
+	fighters.ExperienceIntermediate: 1,
+	fighters.ExperienceAdvanced:     2,
+}
+
+// Fighter is a checked-in member as seen by matchmaking.
+type Fighter struct {
+	UserID     int32
+	Name       string
+	HasProfile bool
+	// WeightKg is only set when HasWeight is. It comes from a recent weigh-in,
+	// or failing that is estimated from the weight class limit.
+	WeightKg        float64
+	HasWeight       bool
+	WeightEstimated bool
+	Experience      string
+	Stance          string
+}
+
+// Constraints are the hard limits a pairing must stay within.
+type Constraints struct {
+	MaxWeightGapKg   float64
+	MaxExperienceGap int
+}
+
+type Pairing struct {
+	A           Fighter
+	B           Fighter
+	WeightGapKg float64
+	// Cost is lower for better pairings
+	Cost    float64
+	Reasons []string
+}
+
+type Unpaired struct {
+	Fighter Fighter
+	Reason  string
+}
+
+// pairKey identifies a pair of fighters regardless of order.
+type pairKey struct {
+	low, high int32
+}
+
+func newPairKey(a, b int32) pairKey {
+	if a > b {
+		a, b = b, a
+	}
+	return pairKey{low: a, high: b}
+}
+
+// match pairs up fighters, cheapest pairings first. history counts how often
+// each pair sparred recently. Fighters that can't be paired are returned with
+// the reason why.
+func match(candidates []Fighter, constraints Constraints, history map[pairKey]int) ([]Pairing, []Unpaired) {
+	var eligible []Fighter
+	var unpaired []Unpaired
+	for _, f := range candidates {
+		switch {
+		case !f.HasProfile:
+			unpaired = append(unpaired, Unpaired{Fighter: f, Reason: "No fighter profile to match on"})
+		case !f.HasWeight:
+			unpaired = append(unpaired, Unpaired{Fighter: f, Reason: "No recent weigh-in and no weight class limit to estimate from"})
+		default:
+			eligible = append(eligible, f)
+		}
+	}
+
+	var options []Pairing
+	hasOption := make(map[int32]bool)
+	for i := range eligible {
+		for j := i + 1; j < len(eligible); j++ {
+			pairing, ok := evaluate(eligible[i], eligible[j], constraints, history)
+			if !ok {
+				continue
+			}
+			options = append(options, pairing)
+			hasOption[eligible[i].UserID] = true
+			hasOption[eligible[j].UserID] = true
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Cost < options[j].Cost })
+
+	paired := make(map[int32]bool)
+	var pairings []Pairing
+	for _, option := range options {
+		if paired[option.A.UserID] || paired[option.B.UserID] {
+			continue
+		}
+		paired[option.A.UserID] = true
+		paired[option.B.UserID] = true
+		pairings = append(pairings, option)
+	}
+
+	for _, f := range eligible {
+		switch {
+		case paired[f.UserID]:
+		case !hasOption[f.UserID]:
+			unpaired = append(unpaired, Unpaired{Fighter: f, Reason: fmt.Sprintf(
+				"Nobody checked in is within %.1f kg and %d experience level(s)",
+				constraints.MaxWeightGapKg, constraints.MaxExperienceGap,
+			)})
+		default:
+			unpaired = append(unpaired, Unpaired{Fighter: f, Reason: "Every suitable partner is already paired"})
+		}
+	}
+
+	return pairings, unpaired
+}
+
+// evaluate checks a pairing against the constraints and explains its cost.
+func evaluate(a, b Fighter, constraints Constraints, history map[pairKey]int) (Pairing, bool) {
+	pairing := Pairing{A: a, B: b, WeightGapKg: math.Abs(a.WeightKg - b.WeightKg)}
+
+	if pairing.WeightGapKg > constraints.MaxWeightGapKg {
+		return pairing, false
+	}
+	experienceGap := abs(experienceRanks[a.Experience] - experienceRanks[b.Experience])
+	if experienceGap > constraints.MaxExperienceGap {
+		return pairing, false
+	}
+
+	if constraints.MaxWeightGapKg > 0 {
+		pairing.Cost += weightCost * pairing.WeightGapKg / constraints.MaxWeightGapKg
+	}
+	weightReason := fmt.Sprintf("%.1f kg apart", pairing.WeightGapKg)
+	if a.WeightEstimated || b.WeightEstimated {
+		weightReason += " (estimated from weight class, no recent weigh-in)"
+	}
+	pairing.Reasons = append(pairing.Reasons, weightReason)
+
+	pairing.Cost += experienceCost * float64(experienceGap)
+	if experienceGap == 0 {
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("Both %s", a.Experience))
+	} else {
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("%s with %s", a.Experience, b.Experience))
+	}
+
+	switch {
+	case a.Stance == b.Stance:
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("Both %s", a.Stance))
+	case a.Stance == fighters.StanceSwitch || b.Stance == fighters.StanceSwitch:
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("%s against %s", a.Stance, b.Stance))
+	case a.Experience == fighters.ExperienceBeginner || b.Experience == fighters.ExperienceBeginner:
+		// Opposite stances are good practice, but awkward for beginners
+		pairing.Cost += stanceCost
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("%s against %s, awkward for a beginner", a.Stance, b.Stance))
+	default:
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("%s against %s, good opposite-stance practice", a.Stance, b.Stance))
+	}
+
+	times := history[newPairKey(a.UserID, b.UserID)]
+	pairing.Cost += repeatCost * float64(times)
+	if times == 0 {
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("Haven't sparred each other in the last %d days", historyRangeDays))
+	} else {
+		pairing.Reasons = append(pairing.Reasons, fmt.Sprintf("Sparred each other %d time(s) in the last %d days", times, historyRangeDays))
+	}
+
+	return pairing, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,280 @@
+package sparring
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/grez-lucas/boxer66-service/fighters"
+)
+
+var constraints = Constraints{MaxWeightGapKg: 5, MaxExperienceGap: 1}
+
+func fighter(id int32, kg float64, experience, stance string) Fighter {
+	return Fighter{
+		UserID:     id,
+		HasProfile: true,
+		WeightKg:   kg,
+		HasWeight:  true,
+		Experience: experience,
+		Stance:     stance,
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	const (
+		beginner     = fighters.ExperienceBeginner
+		intermediate = fighters.ExperienceIntermediate
+		advanced     = fighters.ExperienceAdvanced
+		orthodox     = fighters.StanceOrthodox
+		southpaw     = fighters.StanceSouthpaw
+		switchStance = fighters.StanceSwitch
+	)
+	estimated := fighter(2, 72, intermediate, orthodox)
+	estimated.WeightEstimated = true
+
+	tests := []struct {
+		name string
+		a, b Fighter
+		// constraints replace the default ones if set
+		constraints *Constraints
+		history     map[pairKey]int
+		wantOK      bool
+		wantCost    float64
+		wantReasons []string
+	}{
+		{
+			name:     "close match",
+			a:        fighter(1, 70, intermediate, orthodox),
+			b:        fighter(2, 72, intermediate, orthodox),
+			wantOK:   true,
+			wantCost: 1.2,
+			wantReasons: []string{
+				"2.0 kg apart", "Both intermediate", "Both orthodox", "Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name:     "weight gap at the limit",
+			a:        fighter(1, 70, intermediate, orthodox),
+			b:        fighter(2, 75, intermediate, orthodox),
+			wantOK:   true,
+			wantCost: 3,
+			wantReasons: []string{
+				"5.0 kg apart", "Both intermediate", "Both orthodox", "Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name: "weight gap over the limit",
+			a:    fighter(1, 70, intermediate, orthodox),
+			b:    fighter(2, 75.5, intermediate, orthodox),
+		},
+		{
+			name:     "experience gap at the limit",
+			a:        fighter(1, 70, beginner, orthodox),
+			b:        fighter(2, 70, intermediate, orthodox),
+			wantOK:   true,
+			wantCost: 2,
+			wantReasons: []string{
+				"0.0 kg apart", "beginner with intermediate", "Both orthodox", "Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name: "experience gap over the limit",
+			a:    fighter(1, 70, beginner, orthodox),
+			b:    fighter(2, 70, advanced, orthodox),
+		},
+		{
+			name:     "opposite stances",
+			a:        fighter(1, 70, advanced, orthodox),
+			b:        fighter(2, 70, advanced, southpaw),
+			wantOK:   true,
+			wantCost: 0,
+			wantReasons: []string{
+				"0.0 kg apart",
+				"Both advanced",
+				"orthodox against southpaw, good opposite-stance practice",
+				"Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name:     "opposite stances with a beginner",
+			a:        fighter(1, 70, beginner, southpaw),
+			b:        fighter(2, 70, beginner, orthodox),
+			wantOK:   true,
+			wantCost: stanceCost,
+			wantReasons: []string{
+				"0.0 kg apart",
+				"Both beginner",
+				"southpaw against orthodox, awkward for a beginner",
+				"Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name:     "switch hitter with a beginner",
+			a:        fighter(1, 70, beginner, switchStance),
+			b:        fighter(2, 70, beginner, orthodox),
+			wantOK:   true,
+			wantCost: 0,
+			wantReasons: []string{
+				"0.0 kg apart", "Both beginner", "switch against orthodox", "Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name:     "sparred recently",
+			a:        fighter(1, 70, intermediate, orthodox),
+			b:        fighter(2, 70, intermediate, orthodox),
+			history:  map[pairKey]int{newPairKey(2, 1): 2},
+			wantOK:   true,
+			wantCost: 2 * repeatCost,
+			wantReasons: []string{
+				"0.0 kg apart", "Both intermediate", "Both orthodox", "Sparred each other 2 time(s) in the last 14 days",
+			},
+		},
+		{
+			name:     "estimated weight",
+			a:        fighter(1, 70, intermediate, orthodox),
+			b:        estimated,
+			wantOK:   true,
+			wantCost: 1.2,
+			wantReasons: []string{
+				"2.0 kg apart (estimated from weight class, no recent weigh-in)",
+				"Both intermediate",
+				"Both orthodox",
+				"Haven't sparred each other in the last 14 days",
+			},
+		},
+		{
+			name:        "no weight gap allowed",
+			a:           fighter(1, 70, intermediate, orthodox),
+			b:           fighter(2, 70, intermediate, orthodox),
+			constraints: &Constraints{MaxWeightGapKg: 0, MaxExperienceGap: 0},
+			wantOK:      true,
+			wantCost:    0,
+			wantReasons: []string{
+				"0.0 kg apart", "Both intermediate", "Both orthodox", "Haven't sparred each other in the last 14 days",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := constraints
+			if tt.constraints != nil {
+				c = *tt.constraints
+			}
+
+			pairing, ok := evaluate(tt.a, tt.b, c, tt.history)
+			if ok != tt.wantOK {
+				t.Fatalf("evaluate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(pairing.Cost-tt.wantCost) > 1e-9 {
+				t.Errorf("evaluate() cost = %v, want %v", pairing.Cost, tt.wantCost)
+			}
+			if !slices.Equal(pairing.Reasons, tt.wantReasons) {
+				t.Errorf("evaluate() reasons = %q, want %q", pairing.Reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	const (
+		beginner     = fighters.ExperienceBeginner
+		intermediate = fighters.ExperienceIntermediate
+		advanced     = fighters.ExperienceAdvanced
+		orthodox     = fighters.StanceOrthodox
+	)
+	const (
+		noPartner     = "Nobody checked in is within 5.0 kg and 1 experience level(s)"
+		partnersTaken = "Every suitable partner is already paired"
+	)
+	noProfile := Fighter{UserID: 5}
+	noWeight := Fighter{UserID: 6, HasProfile: true, Experience: intermediate, Stance: orthodox}
+
+	tests := []struct {
+		name         string
+		candidates   []Fighter
+		history      map[pairKey]int
+		wantPairs    [][2]int32
+		wantUnpaired map[int32]string
+	}{
+		{name: "nobody checked in"},
+		{
+			name: "odd count pairs the closest",
+			candidates: []Fighter{
+				fighter(1, 70, intermediate, orthodox),
+				fighter(3, 75, intermediate, orthodox),
+				fighter(2, 71, intermediate, orthodox),
+			},
+			wantPairs:    [][2]int32{{1, 2}},
+			wantUnpaired: map[int32]string{3: partnersTaken},
+		},
+		{
+			name: "recent partners are split up",
+			candidates: []Fighter{
+				fighter(1, 70, intermediate, orthodox),
+				fighter(2, 70, intermediate, orthodox),
+				fighter(3, 70, intermediate, orthodox),
+				fighter(4, 70, intermediate, orthodox),
+			},
+			history:   map[pairKey]int{newPairKey(1, 2): 1, newPairKey(3, 4): 1},
+			wantPairs: [][2]int32{{1, 3}, {2, 4}},
+		},
+		{
+			name: "too far apart in weight",
+			candidates: []Fighter{
+				fighter(1, 60, intermediate, orthodox),
+				fighter(2, 80, intermediate, orthodox),
+			},
+			wantUnpaired: map[int32]string{1: noPartner, 2: noPartner},
+		},
+		{
+			name: "too far apart in experience",
+			candidates: []Fighter{
+				fighter(1, 70, beginner, orthodox),
+				fighter(2, 70, advanced, orthodox),
+			},
+			wantUnpaired: map[int32]string{1: noPartner, 2: noPartner},
+		},
+		{
+			name: "fighters without a profile or weight",
+			candidates: []Fighter{
+				noProfile,
+				fighter(1, 70, intermediate, orthodox),
+				noWeight,
+				fighter(2, 70, intermediate, orthodox),
+			},
+			wantPairs: [][2]int32{{1, 2}},
+			wantUnpaired: map[int32]string{
+				5: "No fighter profile to match on",
+				6: "No recent weigh-in and no weight class limit to estimate from",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairings, unpaired := match(tt.candidates, constraints, tt.history)
+
+			var pairs [][2]int32
+			for _, p := range pairings {
+				pairs = append(pairs, [2]int32{p.A.UserID, p.B.UserID})
+			}
+			if !slices.Equal(pairs, tt.wantPairs) {
+				t.Errorf("match() pairs = %v, want %v", pairs, tt.wantPairs)
+			}
+
+			if len(unpaired) != len(tt.wantUnpaired) {
+				t.Fatalf("match() unpaired = %+v, want %d fighters", unpaired, len(tt.wantUnpaired))
+			}
+			for _, u := range unpaired {
+				if want := tt.wantUnpaired[u.Fighter.UserID]; u.Reason != want {
+					t.Errorf("fighter %d unpaired because %q, want %q", u.Fighter.UserID, u.Reason, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package sparring
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/fighters"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// weighInMaxAge is how old a weigh-in can be and still be trusted for
+	// matchmaking
+	weighInMaxAge = 14 * 24 * time.Hour
+
+	DefaultMaxWeightGapKg   = 8
+	DefaultMaxExperienceGap = 1
+)
+
+type Suggestion struct {
+	SessionID   int32
+	Constraints Constraints
+	Pairings    []Pairing
+	Unpaired    []Unpaired
+}
+
+type SparringService struct {
+	repository *repository.Queries
+}
+
+func NewSparringService(repository *repository.Queries) *SparringService {
+	return &SparringService{
+		repository: repository,
+	}
+}
+
+var (
+	ErrSessionDoesntExist = errors.New("session does not exist")
+	ErrInvalidConstraints = errors.New("matchmaking constraints are invalid")
+	ErrInvalidPairing     = errors.New("sparring pairing is invalid")
+)
+
+// SuggestPairings proposes sparring pairs among the fighters checked in to a
+// session.
+func (s *SparringService) SuggestPairings(ctx context.Context, sessionID int32, constraints Constraints) (*Suggestion, error) {
+	if constraints.MaxWeightGapKg <= 0 || constraints.MaxExperienceGap < 0 {
+		return nil, ErrInvalidConstraints
+	}
+
+	candidates, err := s.getFighters(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int32, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.UserID)
+	}
+	recent, err := s.repository.GetRecentSparringPairs(ctx, repository.GetRecentSparringPairsParams{
+		FighterIds: ids,
+		Since:      time.Now().AddDate(0, 0, -historyRangeDays),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent sparring pairs: %w", err)
+	}
+	history := make(map[pairKey]int, len(recent))
+	for _, r := range recent {
+		history[newPairKey(r.FighterAID, r.FighterBID)] = int(r.Times)
+	}
+
+	pairings, unpaired := match(candidates, constraints, history)
+	return &Suggestion{
+		SessionID:   sessionID,
+		Constraints: constraints,
+		Pairings:    pairings,
+		Unpaired:    unpaired,
+	}, nil
+}
+
+// RecordPairings stores the pairs a coach went with, so later suggestions
+// avoid repeating them. Both fighters of each pair must be checked in to the
+// session.
+func (s *SparringService) RecordPairings(ctx context.Context, sessionID int32, pairs [][2]int32, coachID int32) ([]repository.SparringPairing, error) {
+	candidates, err := s.getFighters(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	checkedIn := make(map[int32]bool, len(candidates))
+	for _, c := range candidates {
+		checkedIn[c.UserID] = true
+	}
+
+	params := repository.CreateSparringPairingsParams{
+		SessionID:  sessionID,
+		RecordedBy: pgtype.Int4{Int32: coachID, Valid: true},
+	}
+	for i, pair := range pairs {
+		if pair[0] == pair[1] {
+			return nil, fmt.Errorf("%w: pair %d has the same fighter twice", ErrInvalidPairing, i)
+		}
+		if !checkedIn[pair[0]] || !checkedIn[pair[1]] {
+			return nil, fmt.Errorf("%w: pair %d has a fighter who isn't checked in", ErrInvalidPairing, i)
+		}
+		key := newPairKey(pair[0], pair[1])
+		params.FighterAIds = append(params.FighterAIds, key.low)
+		params.FighterBIds = append(params.FighterBIds, key.high)
+	}
+
+	pairings, err := s.repository.CreateSparringPairings(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sparring pairings in db: %w", err)
+	}
+	return pairings, nil
+}
+
+// getFighters returns the members checked in to a session, with their weight
+// from a recent weigh-in or else estimated from their weight class.
+func (s *SparringService) getFighters(ctx context.Context, sessionID int32) ([]Fighter, error) {
+	if _, err := s.repository.GetClassSession(ctx, sessionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionDoesntExist
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	rows, err := s.repository.GetCheckedInFighters(ctx, pgtype.Int4{Int32: sessionID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checked-in fighters: %w", err)
+	}
+
+	ids := make([]int32, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	weighIns, err := s.repository.GetLatestWeighIns(ctx, repository.GetLatestWeighInsParams{
+		UserIds: ids,
+		Since:   time.Now().Add(-weighInMaxAge),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest weigh-ins: %w", err)
+	}
+	weights := make(map[int32]float64, len(weighIns))
+	for _, w := range weighIns {
+		weights[w.UserID] = w.WeightKg
+	}
+
+	candidates := make([]Fighter, 0, len(rows))
+	for _, row := range rows {
+		f := Fighter{
+			UserID:     row.ID,
+			Name:       row.Email,
+			HasProfile: row.DisplayName.Valid,
+			Experience: row.Experience.String,
+			Stance:     row.Stance.String,
+		}
+		if row.DisplayName.Valid {
+			f.Name = row.DisplayName.String
+		}
+
+		if weight, ok := weights[row.ID]; ok {
+			f.WeightKg, f.HasWeight = weight, true
+		} else if limit, ok := fighters.WeightClassLimitKg(row.WeightClass.String); ok {
+			f.WeightKg, f.HasWeight, f.WeightEstimated = limit, true, true
+		}
+
+		candidates = append(candidates, f)
+	}
+
+	return candidates, nil
+}
@@ -1,15 +1,16 @@
 package users
 
+import "time"
+
 type APIResponseStatus string
 
 const (
 	APIResponseStatusSuccess = "success"
-	APIResponseStatusError   = "error"
 )
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -18,13 +19,14 @@ type LoginResponse struct {
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email               string  `json:"email" validate:"required,email,length=:254"`
+	Password            string  `json:"password" validate:"required,length=8:,bytes=:72"`
+	AcceptedDocumentIDs []int32 `json:"accepted_document_ids"`
 }
 
 type VerifyEmailRequest struct {
-	Email string `json:"email"`
-	Token string `json:"token"`
+	Email string `json:"email" validate:"required,email"`
+	Token string `json:"token" validate:"required" pattern:"[A-Z0-9]{5}"`
 }
 
 type VerifyEmailResponse struct {
@@ -36,3 +38,23 @@ type StatusResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
 }
+
+type ChangeStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"length=:500"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// UserResponse is a user as shown to staff, without the password hash.
+type UserResponse struct {
+	ID              int32     `json:"id"`
+	Email           string    `json:"email"`
+	Role            string    `json:"role"`
+	Status          string    `json:"status"`
+	StatusReason    *string   `json:"status_reason"`
+	StatusChangedAt time.Time `json:"status_changed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
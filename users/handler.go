@@ -1,17 +1,23 @@
 package users
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/metrics"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
 	"github.com/grez-lucas/boxer66-service/smtp"
 )
 
 type UserHandlers struct {
-	ctx         context.Context
 	uService    IUserService
 	smtpService smtp.ISMTPService
 }
@@ -21,7 +27,6 @@ func NewUserHandlers(
 	smtpService smtp.ISMTPService,
 ) *UserHandlers {
 	return &UserHandlers{
-		ctx:         context.Background(),
 		uService:    uService,
 		smtpService: smtpService,
 	}
@@ -32,57 +37,62 @@ func WriteJSON(w http.ResponseWriter, v any, statusCode int) {
 	json.NewEncoder(w).Encode(v)
 }
 
-func WriteError(w http.ResponseWriter, message string, statusCode int) {
-	resp := StatusResponse{Status: APIResponseStatusError, Message: message}
-	WriteJSON(w, resp, statusCode)
-}
-
 func WriteSuccess(w http.ResponseWriter, mesg string, statusCode int) {
-	w.WriteHeader(statusCode)
+	w.Header().Set("Content-Type", "application/json")
 	resp := StatusResponse{Status: APIResponseStatusSuccess, Message: mesg}
 	WriteJSON(w, resp, statusCode)
 }
 
-func (h *UserHandlers) GetUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.uService.GetUsers()
+// PathID parses the named path wildcard as a database ID.
+func PathID(r *http.Request, name string) (int32, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s path value: %w", name, err)
+	}
+	return int32(id), nil
+}
+
+func (h *UserHandlers) GetUsers(w http.ResponseWriter, r *http.Request) error {
+	users, err := h.uService.GetUsers(r.Context())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		slog.Error("Failed to get users from service", slog.Any("error", err))
-		return
+		return err
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(users); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		slog.Error("Failed to encode users", slog.Any("error", err))
-		return
+	resp := make([]UserResponse, len(users))
+	for i := range users {
+		resp[i] = toUserResponse(&users[i])
 	}
-}
 
-func (h *UserHandlers) Login(w http.ResponseWriter, r *http.Request) {
-	decoder := json.NewDecoder(r.Body)
-	defer r.Body.Close()
+	w.Header().Set("Content-Type", "application/json")
+	WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
 
+func (h *UserHandlers) Login(w http.ResponseWriter, r *http.Request) error {
 	var loginRequest LoginRequest
-	if err := decoder.Decode(&loginRequest); err != nil {
-		slog.Error("Failed to decode LoginResponse", slog.Any("error", err))
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	if err := validate.DecodeJSON(w, r, &loginRequest); err != nil {
+		return err
 	}
 
-	user, token, err := h.uService.Login(loginRequest.Email, loginRequest.Password)
+	user, token, err := h.uService.Login(r.Context(), loginRequest.Email, loginRequest.Password)
 	if err != nil {
+		metrics.LoginFailures.WithLabelValues(loginFailureReason(err)).Inc()
 		if errors.Is(err, ErrInvalidPassword) {
-			WriteError(w, "Password is invalid", http.StatusBadRequest)
-			return
+			return apperror.New(http.StatusBadRequest, "invalid_password", "Password is invalid").Wrap(err)
 		}
 		if errors.Is(err, ErrUserDoesntExist) {
-			WriteError(w, "User does not exist", http.StatusNotFound)
-			return
+			return apperror.New(http.StatusNotFound, "user_doesnt_exist", "User does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrAccountPending) {
+			return apperror.New(http.StatusForbidden, "account_pending", "Account is pending activation").Wrap(err)
+		}
+		if errors.Is(err, ErrAccountSuspended) {
+			return apperror.New(http.StatusForbidden, "account_suspended", "Account is suspended").Wrap(err)
 		}
-		slog.Error("An error occured", slog.Any("error", err))
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		if errors.Is(err, ErrAccountBanned) {
+			return apperror.New(http.StatusForbidden, "account_banned", "Account is banned").Wrap(err)
+		}
+		return err
 	}
 
 	resp := LoginResponse{
@@ -92,64 +102,63 @@ func (h *UserHandlers) Login(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
+	return nil
 }
 
-func (h *UserHandlers) Register(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandlers) Register(w http.ResponseWriter, r *http.Request) error {
 	var registerRequest RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&registerRequest); err != nil {
-		slog.Error("Failed to decode registerRequest", slog.Any("error", err))
-		w.WriteHeader(http.StatusBadRequest)
-		return
+	if err := validate.DecodeJSON(w, r, &registerRequest); err != nil {
+		return err
 	}
 
-	token, err := h.uService.Register(registerRequest.Email, registerRequest.Password)
+	token, err := h.uService.Register(
+		r.Context(),
+		registerRequest.Email,
+		registerRequest.Password,
+		registerRequest.AcceptedDocumentIDs,
+		middleware.ClientIP(r),
+	)
 	if err != nil {
-		slog.Error("Failed to register user", slog.Any("error", err))
 		if errors.Is(err, ErrUserAlreadyExists) {
-			WriteError(w, "The provided email has already been taken", http.StatusConflict)
-			return
+			return apperror.New(http.StatusConflict, "user_already_exists", "The provided email has already been taken").Wrap(err)
+		}
+		if errors.Is(err, ErrLegalDocumentsNotAccepted) {
+			return apperror.New(http.StatusBadRequest, "legal_documents_not_accepted", "The current terms and waiver must be accepted").Wrap(err)
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
-	if err := h.smtpService.SendVerificationEmail(registerRequest.Email, token.VerificationToken); err != nil {
-		slog.Error("Failed to send verification Email", slog.Any("error", err))
+	if err := h.smtpService.SendVerificationEmail(r.Context(), registerRequest.Email, token.VerificationToken); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to send verification Email", slog.Any("error", err))
 		// TODO: Think about this flow... what do we do if the email fails to send?
 		// Just wait for the token to expire? Make it expire instantly? Attempt to send again?
 	}
 
+	metrics.Registrations.Inc()
 	WriteSuccess(w, "Verification code sent to email", http.StatusAccepted)
+	return nil
 }
 
-func (h *UserHandlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandlers) VerifyEmail(w http.ResponseWriter, r *http.Request) error {
 	var verifyEmailRequest VerifyEmailRequest
-	if err := json.NewDecoder(r.Body).Decode(&verifyEmailRequest); err != nil {
-		slog.Error("Failed to decode verifyEmailRequest", slog.Any("error", err))
-		// TODO: Check for missing required fields
-		w.WriteHeader(http.StatusBadRequest)
+	if err := validate.DecodeJSON(w, r, &verifyEmailRequest); err != nil {
+		return err
 	}
 
-	user, jwt, err := h.uService.VerifyEmailToken(verifyEmailRequest.Email, verifyEmailRequest.Token)
+	user, jwt, err := h.uService.VerifyEmailToken(r.Context(), verifyEmailRequest.Email, verifyEmailRequest.Token)
 	if err != nil {
-		slog.Error(
-			"Error verifying email",
-			slog.String("email", verifyEmailRequest.Email),
-			slog.String("token", verifyEmailRequest.Token),
-			slog.Any("error", err),
-		)
+		metrics.Verifications.WithLabelValues(metrics.ResultFailure).Inc()
 		if errors.Is(err, ErrTokenIsExpired) {
-			WriteError(w, "Token is expired", http.StatusBadRequest)
-			return
+			return apperror.New(http.StatusBadRequest, "token_is_expired", "Token is expired").Wrap(err)
 		}
 		if errors.Is(err, ErrInvalidToken) {
-			WriteError(w, "Token is invalid", http.StatusBadRequest)
-			return
+			return apperror.New(http.StatusBadRequest, "invalid_token", "Token is invalid").Wrap(err)
 		}
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+		return err
 	}
 
+	metrics.Verifications.WithLabelValues(metrics.ResultSuccess).Inc()
+
 	resp := VerifyEmailResponse{
 		Token:  jwt,
 		UserID: user.ID,
@@ -157,4 +166,104 @@ func (h *UserHandlers) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
+	return nil
+}
+
+// ChangeStatus moves a user to another status. Accounts created without a
+// verified email start pending and are activated here by staff.
+func (h *UserHandlers) ChangeStatus(w http.ResponseWriter, r *http.Request) error {
+	userID, err := PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var changeRequest ChangeStatusRequest
+	if err := validate.DecodeJSON(w, r, &changeRequest); err != nil {
+		return err
+	}
+
+	user, err := h.uService.ChangeUserStatus(r.Context(), userID, UserStatus(changeRequest.Status), changeRequest.Reason)
+	if err != nil {
+		if errors.Is(err, ErrInvalidStatus) {
+			return validate.ErrInvalidFields.WithFields(apperror.FieldError{
+				Field:   "status",
+				Message: "must be one of pending, active, suspended, banned or deleted",
+			}).Wrap(err)
+		}
+		if errors.Is(err, ErrUserDoesntExist) {
+			return apperror.New(http.StatusNotFound, "user_doesnt_exist", "User does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return apperror.New(http.StatusConflict, "invalid_status_transition", "User can't be moved to this status from its current one").Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	WriteJSON(w, toUserResponse(user), http.StatusOK)
+	return nil
+}
+
+// ChangeRole gives a user another role. The first staff account is made
+// through BOOTSTRAP_STAFF_EMAIL instead.
+func (h *UserHandlers) ChangeRole(w http.ResponseWriter, r *http.Request) error {
+	userID, err := PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	var changeRequest ChangeRoleRequest
+	if err := validate.DecodeJSON(w, r, &changeRequest); err != nil {
+		return err
+	}
+
+	user, err := h.uService.ChangeUserRole(r.Context(), userID, Role(changeRequest.Role))
+	if err != nil {
+		if errors.Is(err, ErrInvalidRole) {
+			return validate.ErrInvalidFields.WithFields(apperror.FieldError{
+				Field:   "role",
+				Message: "must be one of member, coach or staff",
+			}).Wrap(err)
+		}
+		if errors.Is(err, ErrUserDoesntExist) {
+			return apperror.New(http.StatusNotFound, "user_doesnt_exist", "User does not exist").Wrap(err)
+		}
+		if errors.Is(err, ErrLastStaff) {
+			return apperror.New(http.StatusConflict, "last_staff", "The last staff account can't be given another role").Wrap(err)
+		}
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	WriteJSON(w, toUserResponse(user), http.StatusOK)
+	return nil
+}
+
+func toUserResponse(user *repository.User) UserResponse {
+	resp := UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Role:            user.Role,
+		Status:          user.Status,
+		StatusChangedAt: user.StatusChangedAt,
+		CreatedAt:       user.CreatedAt,
+	}
+	if user.StatusReason.Valid {
+		resp.StatusReason = &user.StatusReason.String
+	}
+	return resp
+}
+
+// loginFailureReason keeps the login failure label to a small set of values.
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidPassword):
+		return "invalid_password"
+	case errors.Is(err, ErrUserDoesntExist):
+		return "unknown_user"
+	case errors.Is(err, ErrAccountPending), errors.Is(err, ErrAccountSuspended), errors.Is(err, ErrAccountBanned):
+		return "account_status"
+	default:
+		return "error"
+	}
 }
@@ -1,9 +1,12 @@
 package users
 
 import (
+	"context"
 	"net/http"
+	"net/netip"
 
 	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/middleware"
 )
 
 type IUserHanlders interface {
@@ -11,9 +14,13 @@ type IUserHanlders interface {
 }
 
 type IUserService interface {
-	GetUsers() ([]repository.User, error)
-	Login(email, requestPassword string) (user *repository.User, jwt string, err error)
-	Register(email, password string) (*repository.EmailVerificationToken, error)
-	VerifyEmailToken(email, token string) (user *repository.User, jwt string, err error)
-	CreateUser(email, requestPassword string) (*repository.User, error)
+	GetUsers(ctx context.Context) ([]repository.User, error)
+	Login(ctx context.Context, email, requestPassword string) (user *repository.User, jwt string, err error)
+	Register(ctx context.Context, email, password string, acceptedDocumentIDs []int32, ip netip.Addr) (*repository.EmailVerificationToken, error)
+	VerifyEmailToken(ctx context.Context, email, token string) (user *repository.User, jwt string, err error)
+	CreateUser(ctx context.Context, email, requestPassword string) (*repository.User, error)
+	ChangeUserStatus(ctx context.Context, userID int32, to UserStatus, reason string) (*repository.User, error)
+	ChangeUserRole(ctx context.Context, userID int32, role Role) (*repository.User, error)
+	CheckAccountStatus(ctx context.Context, userID int32) (context.Context, error)
+	RequireRole(role Role) middleware.AccountCheck
 }
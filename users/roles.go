@@ -0,0 +1,126 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
+	"github.com/grez-lucas/boxer66-service/middleware"
+)
+
+type Role string
+
+const (
+	RoleMember Role = "member"
+	RoleCoach  Role = "coach"
+	RoleStaff  Role = "staff"
+)
+
+// roleRanks orders roles so that a role is granted everything the roles below
+// it are. Staff can do anything a coach can, and a coach anything a member can.
+var roleRanks = map[Role]int{
+	RoleMember: 0,
+	RoleCoach:  1,
+	RoleStaff:  2,
+}
+
+var (
+	ErrInsufficientRole = errors.New("insufficient role")
+	ErrInvalidRole      = errors.New("role is invalid")
+	ErrLastStaff        = errors.New("the last staff account can't be given another role")
+)
+
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+func (r Role) Includes(other Role) bool {
+	rank, ok := roleRanks[r]
+	if !ok {
+		return false
+	}
+	return rank >= roleRanks[other]
+}
+
+type contextKey string
+
+const contextAccountKey contextKey = "account"
+
+// UserFromContext returns the account loaded by CheckAccountStatus.
+func UserFromContext(ctx context.Context) (*repository.User, bool) {
+	user, ok := ctx.Value(contextAccountKey).(*repository.User)
+	return user, ok
+}
+
+// RequireRole returns a middleware.AccountCheck that only lets through users
+// with at least the given role. It must run after CheckAccountStatus.
+func (s *UserService) RequireRole(role Role) middleware.AccountCheck {
+	return func(ctx context.Context, userID int32) (context.Context, error) {
+		user, ok := UserFromContext(ctx)
+		if !ok {
+			return ctx, errors.New("account not loaded, CheckAccountStatus must run before RequireRole")
+		}
+
+		if !Role(user.Role).Includes(role) {
+			return ctx, middleware.Reject(http.StatusForbidden, fmt.Errorf("%w: %s role required", ErrInsufficientRole, role))
+		}
+
+		return ctx, nil
+	}
+}
+
+// ChangeUserRole gives a user another role. The last active staff account
+// keeps its role so someone can still manage accounts.
+func (s *UserService) ChangeUserRole(ctx context.Context, userID int32, role Role) (_ *repository.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangeUserRole")
+	defer tracing.End(span, &err)
+
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	if _, err := s.repository.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserDoesntExist
+		}
+		return nil, err
+	}
+
+	user, err := s.repository.UpdateUserRole(ctx, repository.UpdateUserRoleParams{
+		ID:   userID,
+		Role: string(role),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLastStaff
+		}
+		return nil, fmt.Errorf("failed to update user role in db: %w", err)
+	}
+
+	return &user, nil
+}
+
+// BootstrapStaff makes the active account with the given email staff if
+// there is no staff yet, so the first staff account doesn't need the API.
+// It does nothing when email is empty.
+func (s *UserService) BootstrapStaff(ctx context.Context, email string) error {
+	if email == "" {
+		return nil
+	}
+
+	promoted, err := s.repository.PromoteFirstStaff(ctx, email)
+	if err != nil {
+		return fmt.Errorf("failed to promote the first staff account in db: %w", err)
+	}
+	if promoted > 0 {
+		logging.FromContext(ctx).Info("Promoted the first staff account", slog.Any("email", logging.Email(email)))
+	}
+	return nil
+}
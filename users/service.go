@@ -10,31 +10,34 @@ import (
 	"fmt"
 	"log/slog"
 	"math/big"
+	"net/netip"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/grez-lucas/boxer66-service/internal/logging"
 	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
 	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var tracer = otel.Tracer("github.com/grez-lucas/boxer66-service/users")
+
 const (
 	letterBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	tokenLength = 5
 )
 
 type UserService struct {
-	ctx           context.Context
 	repository    *repository.Queries
 	passwordCache sync.Map
 }
 
-func NewUserService(
-	ctx context.Context,
-	repository *repository.Queries,
-) *UserService {
+func NewUserService(repository *repository.Queries) *UserService {
 	return &UserService{
-		ctx:           ctx,
 		repository:    repository,
 		passwordCache: sync.Map{},
 	}
@@ -46,10 +49,15 @@ var (
 	ErrInvalidPassword   = errors.New("password is invalid")
 	ErrInvalidToken      = errors.New("token is invalid")
 	ErrTokenIsExpired    = errors.New("token is expired")
+
+	ErrLegalDocumentsNotAccepted = errors.New("the current legal documents have not been accepted")
 )
 
-func (s *UserService) GetUsers() ([]repository.User, error) {
-	users, err := s.repository.GetAllUsers(s.ctx)
+func (s *UserService) GetUsers(ctx context.Context) (_ []repository.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUsers")
+	defer tracing.End(span, &err)
+
+	users, err := s.repository.GetAllUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -57,8 +65,8 @@ func (s *UserService) GetUsers() ([]repository.User, error) {
 	return users, nil
 }
 
-func (s *UserService) GetUserByEmail(email string) (*repository.User, error) {
-	user, err := s.repository.GetUserByEmail(s.ctx, email)
+func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*repository.User, error) {
+	user, err := s.repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrUserDoesntExist
@@ -69,27 +77,38 @@ func (s *UserService) GetUserByEmail(email string) (*repository.User, error) {
 	return &user, nil
 }
 
-func (s *UserService) CreateUser(email, password string) (*repository.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, email, password string) (_ *repository.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer tracing.End(span, &err)
+
 	// Encrypt the password
-	hashedPassword, err := hashPassword(password)
+	hashedPassword, err := hashPassword(ctx, password)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.repository.CreateUser(s.ctx, repository.CreateUserParams{
+	user, err := s.repository.CreateUser(ctx, repository.CreateUserParams{
 		Email:    email,
 		Password: hashedPassword,
+		Status:   string(UserStatusPending),
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.recordInitialStatus(ctx, &user, "account created"); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
-func (s *UserService) Login(email string, requestPassword string) (*repository.User, string, error) {
+func (s *UserService) Login(ctx context.Context, email string, requestPassword string) (_ *repository.User, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Login")
+	defer tracing.End(span, &err)
+
 	// Get the user
-	user, err := s.repository.GetUserByEmail(s.ctx, email)
+	user, err := s.repository.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, "", ErrUserDoesntExist
@@ -98,10 +117,15 @@ func (s *UserService) Login(email string, requestPassword string) (*repository.U
 	}
 
 	// Compare his request's password vs the hashedpassword
-	if err := comparePassword(user.Password, requestPassword); err != nil {
+	if err := comparePassword(ctx, user.Password, requestPassword); err != nil {
 		return nil, "", ErrInvalidPassword
 	}
 
+	// Only tell the status apart once the password has been checked
+	if err := accountStatusError(UserStatus(user.Status)); err != nil {
+		return nil, "", err
+	}
+
 	token, err := middleware.CreateJWT(&user)
 	if err != nil {
 		return nil, "", err
@@ -110,16 +134,30 @@ func (s *UserService) Login(email string, requestPassword string) (*repository.U
 	return &user, token, nil
 }
 
-func (s *UserService) Register(email, password string) (*repository.EmailVerificationToken, error) {
+func (s *UserService) Register(ctx context.Context, email, password string, acceptedDocumentIDs []int32, ip netip.Addr) (_ *repository.EmailVerificationToken, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Register")
+	defer tracing.End(span, &err)
+
 	// Check if the email is already taken
-	existingUser, err := s.repository.GetUserByEmail(s.ctx, email)
+	existingUser, err := s.repository.GetUserByEmail(ctx, email)
 	// TODO: May 8 - Too broad, must use a more specific way to check for existence
 	if !errors.Is(err, sql.ErrNoRows) {
-		slog.Error("A user with this email already exists", slog.String("email", email), slog.Int("user_id", int(existingUser.ID)))
+		logging.FromContext(ctx).Error("A user with this email already exists", slog.Any("email", logging.Email(email)), slog.Int("user_id", int(existingUser.ID)))
 		return nil, ErrUserAlreadyExists
 	}
 
-	hashedPassword, err := hashPassword(password)
+	// Every fighter must accept the current terms and waiver to sign up
+	currentDocuments, err := s.repository.GetCurrentLegalDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current legal documents: %w", err)
+	}
+	for _, document := range currentDocuments {
+		if !slices.Contains(acceptedDocumentIDs, document.ID) {
+			return nil, ErrLegalDocumentsNotAccepted
+		}
+	}
+
+	hashedPassword, err := hashPassword(ctx, password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -131,7 +169,7 @@ func (s *UserService) Register(email, password string) (*repository.EmailVerific
 	cacheKey := generateCacheKey(email)
 
 	// Save token in DB
-	token, err := s.repository.CreateEmailVerificationToken(s.ctx, repository.CreateEmailVerificationTokenParams{
+	token, err := s.repository.CreateEmailVerificationToken(ctx, repository.CreateEmailVerificationTokenParams{
 		Email:                  email,
 		VerificationToken:      verificationToken,
 		HashedPasswordCacheKey: cacheKey,
@@ -141,21 +179,36 @@ func (s *UserService) Register(email, password string) (*repository.EmailVerific
 		return nil, fmt.Errorf("failed to create email verification token in db: %w", err)
 	}
 
+	// Acceptances are kept by email until the users row exists
+	for _, document := range currentDocuments {
+		err := s.repository.CreatePendingLegalAcceptance(ctx, repository.CreatePendingLegalAcceptanceParams{
+			Email:      email,
+			DocumentID: document.ID,
+			IpAddress:  ip,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record legal acceptance in db: %w", err)
+		}
+	}
+
 	s.passwordCache.Store(cacheKey, hashedPassword)
 
 	return &token, nil
 }
 
-func (s *UserService) VerifyEmailToken(email, token string) (*repository.User, string, error) {
+func (s *UserService) VerifyEmailToken(ctx context.Context, email, token string) (_ *repository.User, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.VerifyEmailToken")
+	defer tracing.End(span, &err)
+
 	// 1. Query the email_verification_tokens table for a matching email and verification_token_key
-	dbToken, err := s.repository.GetEmailVerificationTokenByEmail(s.ctx, email)
+	dbToken, err := s.repository.GetEmailVerificationTokenByEmail(ctx, email)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get email verification token for email %s: %w", email, err)
+		return nil, "", fmt.Errorf("failed to get email verification token: %w", err)
 	}
 
 	// 2.1 Check that the token is valid
 	if token != dbToken.VerificationToken {
-		slog.Error("Token is invalid", slog.String("user_token", token), slog.String("db_token", dbToken.VerificationToken))
+		logging.FromContext(ctx).Warn("Token is invalid", slog.Any("email", logging.Email(email)))
 		return nil, "", ErrInvalidToken
 	}
 
@@ -179,16 +232,29 @@ func (s *UserService) VerifyEmailToken(email, token string) (*repository.User, s
 	}
 
 	// 4. Create a user in the users table with the memory password
-	user, err := s.repository.CreateUser(s.ctx, repository.CreateUserParams{
+	user, err := s.repository.CreateUser(ctx, repository.CreateUserParams{
 		Email:    email,
 		Password: hashedPasswordBytes,
+		Status:   string(UserStatusActive),
 	})
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create user in db: %w", err)
 	}
 
+	if err := s.recordInitialStatus(ctx, &user, "email verified"); err != nil {
+		return nil, "", err
+	}
+
+	// 4.1 Attach the legal acceptances given during registration to the user
+	if err := s.repository.ClaimPendingLegalAcceptances(ctx, repository.ClaimPendingLegalAcceptancesParams{
+		UserID: user.ID,
+		Email:  email,
+	}); err != nil {
+		return nil, "", fmt.Errorf("failed to claim legal acceptances in db: %w", err)
+	}
+
 	// 4. Delete the record from the email_verification_tokens table.
-	if err := s.repository.DeleteEmailVerificationTokenByID(s.ctx, dbToken.ID); err != nil {
+	if err := s.repository.DeleteEmailVerificationTokenByID(ctx, dbToken.ID); err != nil {
 		return nil, "", fmt.Errorf("failed to delete email verification token from db: %w", err)
 	}
 
@@ -203,7 +269,24 @@ func (s *UserService) VerifyEmailToken(email, token string) (*repository.User, s
 	return &user, jwt, nil
 }
 
-func hashPassword(password string) ([]byte, error) {
+// recordInitialStatus stores the status a user was created with as the first
+// entry of its status history.
+func (s *UserService) recordInitialStatus(ctx context.Context, user *repository.User, reason string) error {
+	_, err := s.repository.CreateUserStatusTransition(ctx, repository.CreateUserStatusTransitionParams{
+		UserID:   user.ID,
+		ToStatus: user.Status,
+		Reason:   pgtype.Text{String: reason, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record user status in db: %w", err)
+	}
+	return nil
+}
+
+func hashPassword(ctx context.Context, password string) ([]byte, error) {
+	_, span := tracer.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -211,7 +294,10 @@ func hashPassword(password string) ([]byte, error) {
 	return hashedPassword, nil
 }
 
-func comparePassword(hashedPassword []byte, password string) error {
+func comparePassword(ctx context.Context, hashedPassword []byte, password string) error {
+	_, span := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
 	err := bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
 	return err
 }
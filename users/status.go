@@ -0,0 +1,133 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/tracing"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type UserStatus string
+
+const (
+	UserStatusPending   UserStatus = "pending"
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+	UserStatusDeleted   UserStatus = "deleted"
+)
+
+// statusTransitions lists, for every status, the statuses an account is
+// allowed to move to. Deleted is terminal. Members who verify their email
+// start active; accounts created any other way start pending until staff
+// activate them through PATCH /users/{id}/status.
+var statusTransitions = map[UserStatus][]UserStatus{
+	UserStatusPending:   {UserStatusActive, UserStatusDeleted},
+	UserStatusActive:    {UserStatusSuspended, UserStatusBanned, UserStatusDeleted},
+	UserStatusSuspended: {UserStatusActive, UserStatusBanned, UserStatusDeleted},
+	UserStatusBanned:    {UserStatusActive, UserStatusDeleted},
+	UserStatusDeleted:   {},
+}
+
+var (
+	ErrInvalidStatus           = errors.New("status is invalid")
+	ErrInvalidStatusTransition = errors.New("status transition is not allowed")
+	ErrAccountPending          = errors.New("account is pending activation")
+	ErrAccountSuspended        = errors.New("account is suspended")
+	ErrAccountBanned           = errors.New("account is banned")
+)
+
+func (s UserStatus) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+func (s UserStatus) CanTransitionTo(to UserStatus) bool {
+	return slices.Contains(statusTransitions[s], to)
+}
+
+// accountStatusError returns the error that describes why an account with the
+// given status cannot use the API, or nil if the account is active.
+func accountStatusError(status UserStatus) error {
+	switch status {
+	case UserStatusActive:
+		return nil
+	case UserStatusPending:
+		return ErrAccountPending
+	case UserStatusSuspended:
+		return ErrAccountSuspended
+	case UserStatusBanned:
+		return ErrAccountBanned
+	default:
+		return ErrUserDoesntExist
+	}
+}
+
+func (s *UserService) ChangeUserStatus(ctx context.Context, userID int32, to UserStatus, reason string) (_ *repository.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangeUserStatus")
+	defer tracing.End(span, &err)
+
+	if !to.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+
+	user, err := s.repository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserDoesntExist
+		}
+		return nil, err
+	}
+
+	from := UserStatus(user.Status)
+	if !from.CanTransitionTo(to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+	}
+
+	// The update only matches while the account is still in the status we
+	// validated against, so concurrent transitions can't skip the state machine.
+	row, err := s.repository.UpdateUserStatus(ctx, repository.UpdateUserStatusParams{
+		ID:         userID,
+		FromStatus: string(from),
+		ToStatus:   string(to),
+		Reason:     pgtype.Text{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: status changed concurrently", ErrInvalidStatusTransition)
+		}
+		return nil, fmt.Errorf("failed to update user status in db: %w", err)
+	}
+
+	updated := repository.User(row)
+	return &updated, nil
+}
+
+// CheckAccountStatus is a middleware.AccountCheck that rejects accounts that
+// are not active. The loaded account is stored in the context, see
+// UserFromContext.
+func (s *UserService) CheckAccountStatus(ctx context.Context, userID int32) (context.Context, error) {
+	user, err := s.repository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ctx, middleware.Reject(http.StatusUnauthorized, ErrUserDoesntExist)
+		}
+		return ctx, err
+	}
+
+	if err := accountStatusError(UserStatus(user.Status)); err != nil {
+		if errors.Is(err, ErrUserDoesntExist) {
+			return ctx, middleware.Reject(http.StatusUnauthorized, err)
+		}
+		return ctx, middleware.Reject(http.StatusForbidden, err)
+	}
+
+	return context.WithValue(ctx, contextAccountKey, &user), nil
+}
@@ -0,0 +1,51 @@
+package weighins
+
+import "time"
+
+type WeighInRequest struct {
+	Weight float64 `json:"weight"`
+	// Unit is kg or lb, kg if omitted
+	Unit           string     `json:"unit"`
+	BodyFatPercent float64    `json:"body_fat_percent"`
+	MeasuredAt     *time.Time `json:"measured_at"`
+	Source         string     `json:"source"`
+	Notes          string     `json:"notes"`
+}
+
+type WeighInResponse struct {
+	ID             int32     `json:"id"`
+	Weight         float64   `json:"weight"`
+	Unit           Unit      `json:"unit"`
+	BodyFatPercent *float64  `json:"body_fat_percent,omitempty"`
+	MeasuredAt     time.Time `json:"measured_at"`
+	Source         string    `json:"source"`
+	Notes          string    `json:"notes,omitempty"`
+}
+
+type TargetRequest struct {
+	Weight float64 `json:"weight"`
+	Unit   string  `json:"unit"`
+	// FightDate is formatted as 2006-01-02
+	FightDate string `json:"fight_date"`
+	// CoachID is the coach alerted about unsafe cuts
+	CoachID int32 `json:"coach_id"`
+}
+
+type TargetResponse struct {
+	Weight    float64 `json:"weight"`
+	Unit      Unit    `json:"unit"`
+	FightDate string  `json:"fight_date"`
+	CoachID   *int32  `json:"coach_id,omitempty"`
+}
+
+type TrendResponse struct {
+	Unit          Unit             `json:"unit"`
+	Latest        *WeighInResponse `json:"latest,omitempty"`
+	MovingAverage *float64         `json:"moving_average,omitempty"`
+	ChangePerWeek *float64         `json:"change_per_week,omitempty"`
+	Target        *TargetResponse  `json:"target,omitempty"`
+	DaysToFight   *int             `json:"days_to_fight,omitempty"`
+	Projected     *float64         `json:"projected,omitempty"`
+	CutPercent    *float64         `json:"cut_percent,omitempty"`
+	Unsafe        bool             `json:"unsafe"`
+}
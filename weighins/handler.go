@@ -0,0 +1,382 @@
+package weighins
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/apperror"
+	"github.com/grez-lucas/boxer66-service/internal/logging"
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/internal/validate"
+	"github.com/grez-lucas/boxer66-service/middleware"
+	"github.com/grez-lucas/boxer66-service/schedule"
+	"github.com/grez-lucas/boxer66-service/smtp"
+	"github.com/grez-lucas/boxer66-service/users"
+)
+
+const (
+	dateLayout          = "2006-01-02"
+	defaultHistoryRange = 30 * 24 * time.Hour
+)
+
+type WeighInHandlers struct {
+	wService    IWeighInService
+	smtpService smtp.ISMTPService
+}
+
+func NewWeighInHandlers(
+	wService IWeighInService,
+	smtpService smtp.ISMTPService,
+) *WeighInHandlers {
+	return &WeighInHandlers{
+		wService:    wService,
+		smtpService: smtpService,
+	}
+}
+
+func (h *WeighInHandlers) LogMyWeighIn(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.logWeighIn(w, r, userID, userID, "")
+}
+
+// LogUserWeighIn records an official weigh-in taken by a coach.
+func (h *WeighInHandlers) LogUserWeighIn(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.logWeighIn(w, r, userID, coachID, SourceCoach)
+}
+
+func (h *WeighInHandlers) GetMyWeighIns(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writeWeighIns(w, r, userID)
+}
+
+func (h *WeighInHandlers) GetUserWeighIns(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writeWeighIns(w, r, userID)
+}
+
+func (h *WeighInHandlers) DeleteMyWeighIn(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	weighInID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("Weigh-in ID is invalid")
+	}
+
+	if err := h.wService.DeleteWeighIn(r.Context(), userID, weighInID); err != nil {
+		return weighInError(err)
+	}
+
+	users.WriteSuccess(w, "Weigh-in deleted", http.StatusOK)
+	return nil
+}
+
+func (h *WeighInHandlers) GetMyTrend(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.writeTrend(w, r, userID)
+}
+
+func (h *WeighInHandlers) GetUserTrend(w http.ResponseWriter, r *http.Request) error {
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.writeTrend(w, r, userID)
+}
+
+func (h *WeighInHandlers) SetMyTarget(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	return h.setTarget(w, r, userID, 0)
+}
+
+// SetUserTarget sets a member's target weight. The coach setting it is the
+// one alerted about unsafe cuts.
+func (h *WeighInHandlers) SetUserTarget(w http.ResponseWriter, r *http.Request) error {
+	coachID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	userID, err := users.PathID(r, "id")
+	if err != nil {
+		return apperror.BadRequest("User ID is invalid")
+	}
+
+	return h.setTarget(w, r, userID, coachID)
+}
+
+func (h *WeighInHandlers) RemoveMyTarget(w http.ResponseWriter, r *http.Request) error {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := h.wService.RemoveTarget(r.Context(), userID); err != nil {
+		return weighInError(err)
+	}
+
+	users.WriteSuccess(w, "Weight target removed", http.StatusOK)
+	return nil
+}
+
+// logWeighIn records a weigh-in for userID. A non-empty source overrides the
+// one in the request.
+func (h *WeighInHandlers) logWeighIn(w http.ResponseWriter, r *http.Request, userID, recordedBy int32, source string) error {
+	var weighInRequest WeighInRequest
+	if err := validate.DecodeJSON(w, r, &weighInRequest); err != nil {
+		return err
+	}
+
+	unit, err := ParseUnit(weighInRequest.Unit)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	weighIn := NewWeighIn{
+		WeightKg:       unit.ToKg(weighInRequest.Weight),
+		BodyFatPercent: weighInRequest.BodyFatPercent,
+		Source:         weighInRequest.Source,
+		Notes:          weighInRequest.Notes,
+	}
+	if weighInRequest.MeasuredAt != nil {
+		weighIn.MeasuredAt = *weighInRequest.MeasuredAt
+	}
+	if source != "" {
+		weighIn.Source = source
+	}
+
+	created, err := h.wService.LogWeighIn(r.Context(), userID, weighIn, recordedBy)
+	if err != nil {
+		return weighInError(err)
+	}
+
+	h.alertCoach(r.Context(), userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toWeighInResponse(*created, unit), http.StatusCreated)
+	return nil
+}
+
+// alertCoach emails the member's coach if the new weigh-in puts their cut
+// over the safe limit. The weigh-in is logged either way, so failures are
+// only logged.
+func (h *WeighInHandlers) alertCoach(ctx context.Context, userID int32) {
+	alert, err := h.wService.ClaimCutAlert(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to check weight cut", slog.Any("error", err))
+		return
+	}
+	if alert == nil {
+		return
+	}
+
+	if err := h.smtpService.SendWeightCutAlertEmail(
+		ctx,
+		alert.CoachEmail, alert.FighterName, alert.FightDate, alert.TargetKg, alert.ProjectedKg, alert.CutPercent,
+	); err != nil {
+		logging.FromContext(ctx).Error("Failed to send weight cut alert email", slog.Any("error", err))
+	}
+}
+
+func (h *WeighInHandlers) writeWeighIns(w http.ResponseWriter, r *http.Request, userID int32) error {
+	query := r.URL.Query()
+
+	unit, err := ParseUnit(query.Get("unit"))
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	to := time.Now()
+	if v := query.Get("to"); v != "" {
+		if to, err = schedule.ParseTimeParam(v, time.UTC); err != nil {
+			return apperror.BadRequest("to must be an RFC 3339 time or a date")
+		}
+	}
+
+	from := to.Add(-defaultHistoryRange)
+	if v := query.Get("from"); v != "" {
+		if from, err = schedule.ParseTimeParam(v, time.UTC); err != nil {
+			return apperror.BadRequest("from must be an RFC 3339 time or a date")
+		}
+	}
+
+	weighIns, err := h.wService.GetWeighIns(r.Context(), userID, from, to)
+	if err != nil {
+		return weighInError(err)
+	}
+
+	resp := make([]WeighInResponse, 0, len(weighIns))
+	for _, weighIn := range weighIns {
+		resp = append(resp, toWeighInResponse(weighIn, unit))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *WeighInHandlers) writeTrend(w http.ResponseWriter, r *http.Request, userID int32) error {
+	unit, err := ParseUnit(r.URL.Query().Get("unit"))
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	trend, err := h.wService.GetTrend(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := TrendResponse{
+		Unit:          unit,
+		MovingAverage: convert(trend.MovingAverage, unit),
+		ChangePerWeek: convert(trend.KgPerWeek, unit),
+		Projected:     convert(trend.Projected, unit),
+		Unsafe:        trend.Unsafe,
+	}
+	if trend.Latest != nil {
+		latest := toWeighInResponse(*trend.Latest, unit)
+		resp.Latest = &latest
+	}
+	if trend.Target != nil {
+		target := toTargetResponse(*trend.Target, unit)
+		resp.Target = &target
+
+		days := int(math.Ceil(time.Until(trend.Target.FightDate).Hours() / 24))
+		if days >= 0 {
+			resp.DaysToFight = &days
+		}
+	}
+	if trend.CutPercent != nil {
+		cut := math.Round(*trend.CutPercent*10) / 10
+		resp.CutPercent = &cut
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, resp, http.StatusOK)
+	return nil
+}
+
+func (h *WeighInHandlers) setTarget(w http.ResponseWriter, r *http.Request, userID, coachID int32) error {
+	var targetRequest TargetRequest
+	if err := validate.DecodeJSON(w, r, &targetRequest); err != nil {
+		return err
+	}
+
+	unit, err := ParseUnit(targetRequest.Unit)
+	if err != nil {
+		return apperror.BadRequest(err.Error())
+	}
+
+	fightDate, err := time.Parse(dateLayout, targetRequest.FightDate)
+	if err != nil {
+		return apperror.BadRequest("Fight date must be formatted as YYYY-MM-DD")
+	}
+
+	if coachID == 0 {
+		coachID = targetRequest.CoachID
+	}
+
+	target, err := h.wService.SetTarget(r.Context(), userID, unit.ToKg(targetRequest.Weight), fightDate, coachID)
+	if err != nil {
+		return weighInError(err)
+	}
+
+	// A new target can put the current trend over the limit
+	h.alertCoach(r.Context(), userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	users.WriteJSON(w, toTargetResponse(*target, unit), http.StatusOK)
+	return nil
+}
+
+func weighInError(err error) error {
+	switch {
+	case errors.Is(err, ErrWeighInDoesntExist):
+		return apperror.New(http.StatusNotFound, "weigh_in_doesnt_exist", "Weigh-in does not exist").Wrap(err)
+	case errors.Is(err, ErrTargetDoesntExist):
+		return apperror.New(http.StatusNotFound, "target_doesnt_exist", "Weight target does not exist").Wrap(err)
+	case errors.Is(err, ErrInvalidWeighIn):
+		return apperror.New(http.StatusBadRequest, "invalid_weigh_in", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidTarget):
+		return apperror.New(http.StatusBadRequest, "invalid_target", err.Error()).Wrap(err)
+	case errors.Is(err, ErrInvalidRange):
+		return apperror.New(http.StatusBadRequest, "invalid_range", err.Error()).Wrap(err)
+	case errors.Is(err, ErrCoachDoesntExist):
+		return apperror.New(http.StatusBadRequest, "coach_doesnt_exist", err.Error()).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func toWeighInResponse(weighIn repository.WeighIn, unit Unit) WeighInResponse {
+	resp := WeighInResponse{
+		ID:         weighIn.ID,
+		Weight:     unit.FromKg(weighIn.WeightKg),
+		Unit:       unit,
+		MeasuredAt: weighIn.MeasuredAt,
+		Source:     weighIn.Source,
+		Notes:      weighIn.Notes,
+	}
+	if weighIn.BodyFatPercent.Valid {
+		resp.BodyFatPercent = &weighIn.BodyFatPercent.Float64
+	}
+	return resp
+}
+
+func toTargetResponse(target repository.WeightTarget, unit Unit) TargetResponse {
+	resp := TargetResponse{
+		Weight:    unit.FromKg(target.TargetKg),
+		Unit:      unit,
+		FightDate: target.FightDate.Format(dateLayout),
+	}
+	if target.CoachID.Valid {
+		resp.CoachID = &target.CoachID.Int32
+	}
+	return resp
+}
+
+// convert converts an optional weight in kg to unit.
+func convert(kg *float64, unit Unit) *float64 {
+	if kg == nil {
+		return nil
+	}
+	v := unit.FromKg(*kg)
+	return &v
+}
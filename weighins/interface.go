@@ -0,0 +1,18 @@
+package weighins
+
+import (
+	"context"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+type IWeighInService interface {
+	LogWeighIn(ctx context.Context, userID int32, weighIn NewWeighIn, recordedBy int32) (*repository.WeighIn, error)
+	GetWeighIns(ctx context.Context, userID int32, from, to time.Time) ([]repository.WeighIn, error)
+	DeleteWeighIn(ctx context.Context, userID, weighInID int32) error
+	SetTarget(ctx context.Context, userID int32, targetKg float64, fightDate time.Time, coachID int32) (*repository.WeightTarget, error)
+	RemoveTarget(ctx context.Context, userID int32) error
+	GetTrend(ctx context.Context, userID int32) (*Trend, error)
+	ClaimCutAlert(ctx context.Context, userID int32) (*CutAlert, error)
+}
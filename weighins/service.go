@@ -0,0 +1,237 @@
+package weighins
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+	"github.com/grez-lucas/boxer66-service/users"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	SourceManual     = "manual"
+	SourceSmartScale = "smart_scale"
+	SourceCoach      = "coach"
+
+	minWeightKg = 20
+	maxWeightKg = 300
+)
+
+type NewWeighIn struct {
+	WeightKg float64
+	// BodyFatPercent is 0 when not measured
+	BodyFatPercent float64
+	MeasuredAt     time.Time
+	Source         string
+	Notes          string
+}
+
+// CutAlert tells a coach that a member's projected weight cut is unsafe.
+type CutAlert struct {
+	CoachEmail  string
+	FighterName string
+	FightDate   time.Time
+	TargetKg    float64
+	ProjectedKg float64
+	CutPercent  float64
+}
+
+type WeighInService struct {
+	repository     *repository.Queries
+	safeCutPercent float64
+}
+
+func NewWeighInService(
+	repository *repository.Queries,
+	safeCutPercent float64,
+) *WeighInService {
+	return &WeighInService{
+		repository:     repository,
+		safeCutPercent: safeCutPercent,
+	}
+}
+
+var (
+	ErrInvalidWeighIn     = errors.New("weigh-in is invalid")
+	ErrWeighInDoesntExist = errors.New("weigh-in does not exist")
+	ErrInvalidTarget      = errors.New("weight target is invalid")
+	ErrTargetDoesntExist  = errors.New("weight target does not exist")
+	ErrCoachDoesntExist   = errors.New("coach does not exist")
+	ErrInvalidRange       = errors.New("time range is invalid")
+)
+
+func (s *WeighInService) LogWeighIn(ctx context.Context, userID int32, weighIn NewWeighIn, recordedBy int32) (*repository.WeighIn, error) {
+	if weighIn.MeasuredAt.IsZero() {
+		weighIn.MeasuredAt = time.Now()
+	}
+	if weighIn.Source == "" {
+		weighIn.Source = SourceManual
+	}
+	if err := validateWeighIn(weighIn); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repository.CreateWeighIn(ctx, repository.CreateWeighInParams{
+		UserID:         userID,
+		WeightKg:       weighIn.WeightKg,
+		BodyFatPercent: pgtype.Float8{Float64: weighIn.BodyFatPercent, Valid: weighIn.BodyFatPercent != 0},
+		MeasuredAt:     weighIn.MeasuredAt,
+		Source:         weighIn.Source,
+		Notes:          weighIn.Notes,
+		RecordedBy:     pgtype.Int4{Int32: recordedBy, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create weigh-in in db: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (s *WeighInService) GetWeighIns(ctx context.Context, userID int32, from, to time.Time) ([]repository.WeighIn, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	return s.repository.GetWeighInsBetween(ctx, repository.GetWeighInsBetweenParams{
+		UserID:   userID,
+		FromTime: from,
+		ToTime:   to,
+	})
+}
+
+func (s *WeighInService) DeleteWeighIn(ctx context.Context, userID, weighInID int32) error {
+	deleted, err := s.repository.DeleteWeighIn(ctx, repository.DeleteWeighInParams{
+		ID:     weighInID,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete weigh-in in db: %w", err)
+	}
+	if deleted == 0 {
+		return ErrWeighInDoesntExist
+	}
+	return nil
+}
+
+// SetTarget sets the weight a member has to make on fight day. coachID is the
+// coach alerted about unsafe cuts, 0 for none.
+func (s *WeighInService) SetTarget(ctx context.Context, userID int32, targetKg float64, fightDate time.Time, coachID int32) (*repository.WeightTarget, error) {
+	if targetKg < minWeightKg || targetKg > maxWeightKg {
+		return nil, fmt.Errorf("%w: target must be between %d and %d kg", ErrInvalidTarget, minWeightKg, maxWeightKg)
+	}
+	if !fightDate.After(time.Now()) {
+		return nil, fmt.Errorf("%w: fight date must be in the future", ErrInvalidTarget)
+	}
+
+	if coachID != 0 {
+		coach, err := s.repository.GetUserByID(ctx, coachID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrCoachDoesntExist
+			}
+			return nil, fmt.Errorf("failed to get coach: %w", err)
+		}
+		if !users.Role(coach.Role).Includes(users.RoleCoach) {
+			return nil, ErrCoachDoesntExist
+		}
+	}
+
+	target, err := s.repository.UpsertWeightTarget(ctx, repository.UpsertWeightTargetParams{
+		UserID:    userID,
+		TargetKg:  targetKg,
+		FightDate: fightDate,
+		CoachID:   pgtype.Int4{Int32: coachID, Valid: coachID != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert weight target in db: %w", err)
+	}
+
+	return &target, nil
+}
+
+func (s *WeighInService) RemoveTarget(ctx context.Context, userID int32) error {
+	deleted, err := s.repository.DeleteWeightTarget(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete weight target in db: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTargetDoesntExist
+	}
+	return nil
+}
+
+// GetTrend computes a member's weight trend from their recent weigh-ins.
+func (s *WeighInService) GetTrend(ctx context.Context, userID int32) (*Trend, error) {
+	now := time.Now()
+	weighIns, err := s.repository.GetWeighInsBetween(ctx, repository.GetWeighInsBetweenParams{
+		UserID:   userID,
+		FromTime: now.Add(-max(movingAverageWindow, rateWindow)),
+		ToTime:   now.Add(time.Minute),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weigh-ins: %w", err)
+	}
+
+	var target *repository.WeightTarget
+	t, err := s.repository.GetWeightTarget(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get weight target: %w", err)
+	}
+	if err == nil {
+		target = &t
+	}
+
+	trend := computeTrend(weighIns, target, s.safeCutPercent, now)
+	return &trend, nil
+}
+
+// ClaimCutAlert returns an alert for the member's coach when their projected
+// cut is unsafe. Each target alerts once, later calls return nil until the
+// target changes.
+func (s *WeighInService) ClaimCutAlert(ctx context.Context, userID int32) (*CutAlert, error) {
+	trend, err := s.GetTrend(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !trend.Unsafe || trend.Target.AlertedAt.Valid || !trend.Target.CoachID.Valid {
+		return nil, nil
+	}
+
+	recipient, err := s.repository.ClaimWeightCutAlert(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim weight cut alert: %w", err)
+	}
+
+	return &CutAlert{
+		CoachEmail:  recipient.CoachEmail,
+		FighterName: recipient.FighterName,
+		FightDate:   trend.Target.FightDate,
+		TargetKg:    trend.Target.TargetKg,
+		ProjectedKg: *trend.Projected,
+		CutPercent:  *trend.CutPercent,
+	}, nil
+}
+
+func validateWeighIn(weighIn NewWeighIn) error {
+	if weighIn.WeightKg < minWeightKg || weighIn.WeightKg > maxWeightKg {
+		return fmt.Errorf("%w: weight must be between %d and %d kg", ErrInvalidWeighIn, minWeightKg, maxWeightKg)
+	}
+	if weighIn.BodyFatPercent != 0 && (weighIn.BodyFatPercent < 2 || weighIn.BodyFatPercent > 70) {
+		return fmt.Errorf("%w: body fat must be between 2 and 70 percent", ErrInvalidWeighIn)
+	}
+	if weighIn.MeasuredAt.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("%w: it can't be measured in the future", ErrInvalidWeighIn)
+	}
+	switch weighIn.Source {
+	case SourceManual, SourceSmartScale, SourceCoach:
+	default:
+		return fmt.Errorf("%w: unknown source %q", ErrInvalidWeighIn, weighIn.Source)
+	}
+	return nil
+}
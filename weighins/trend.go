@@ -0,0 +1,138 @@
+package weighins
+
+import (
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+const (
+	// movingAverageWindow is how far back weigh-ins count towards the moving
+	// average, which smooths out day-to-day water weight
+	movingAverageWindow = 7 * 24 * time.Hour
+	// rateWindow is how far back weigh-ins count towards the rate of change
+	rateWindow = 14 * 24 * time.Hour
+	// minRateSpan is the shortest span of weigh-ins a rate is computed from
+	minRateSpan = 48 * time.Hour
+	// maxProjection is how far ahead the rate is extrapolated. A steady cut
+	// doesn't go on for months, so a fight further away is projected from the
+	// rate over this horizon only.
+	maxProjection = 4 * week
+
+	week = 7 * 24 * time.Hour
+)
+
+// Trend summarizes a member's recent weigh-ins and, with a target, where they
+// are headed on fight day. Weights are in kg.
+type Trend struct {
+	Latest        *repository.WeighIn
+	MovingAverage *float64
+	// KgPerWeek is the rate of change, negative while losing weight
+	KgPerWeek *float64
+	Target    *repository.WeightTarget
+	// Projected is the expected weight on fight day, or maxProjection ahead if
+	// the fight is further away. Without a rate the moving average is assumed
+	// to hold.
+	Projected *float64
+	// CutPercent is how much of their projected weight the member would
+	// still have to cut on fight day, 0 if they are projected at or under the
+	// target
+	CutPercent *float64
+	Unsafe     bool
+}
+
+// computeTrend computes the trend of weigh-ins ordered by time. The cut is
+// unsafe when it exceeds safeCutPercent.
+func computeTrend(
+	weighIns []repository.WeighIn,
+	target *repository.WeightTarget,
+	safeCutPercent float64,
+	now time.Time,
+) Trend {
+	trend := Trend{Target: target}
+	if len(weighIns) == 0 {
+		return trend
+	}
+
+	latest := weighIns[len(weighIns)-1]
+	trend.Latest = &latest
+
+	average := movingAverage(weighIns, latest.MeasuredAt.Add(-movingAverageWindow))
+	trend.MovingAverage = &average
+
+	fitted, slope, ok := fit(weighIns, latest.MeasuredAt.Add(-rateWindow))
+	if ok {
+		perWeek := slope * week.Hours()
+		trend.KgPerWeek = &perWeek
+	}
+
+	if target == nil || !target.FightDate.After(now) {
+		return trend
+	}
+
+	projected := average
+	if ok {
+		horizon := min(target.FightDate.Sub(latest.MeasuredAt), maxProjection)
+		projected = fitted + slope*horizon.Hours()
+	}
+	trend.Projected = &projected
+
+	var cut float64
+	if projected > target.TargetKg {
+		cut = (projected - target.TargetKg) / projected * 100
+	}
+	trend.CutPercent = &cut
+	trend.Unsafe = cut > safeCutPercent
+
+	return trend
+}
+
+// movingAverage averages the weights measured since the given time.
+func movingAverage(weighIns []repository.WeighIn, since time.Time) float64 {
+	var sum float64
+	var n int
+	for _, w := range weighIns {
+		if w.MeasuredAt.Before(since) {
+			continue
+		}
+		sum += w.WeightKg
+		n++
+	}
+	return sum / float64(n)
+}
+
+// fit fits a least-squares line through the weights measured since the given
+// time. It returns the line's weight at the last weigh-in and its slope in kg
+// per hour. It needs at least two weigh-ins spanning minRateSpan.
+func fit(weighIns []repository.WeighIn, since time.Time) (float64, float64, bool) {
+	var points []repository.WeighIn
+	for _, w := range weighIns {
+		if !w.MeasuredAt.Before(since) {
+			points = append(points, w)
+		}
+	}
+	if len(points) < 2 || points[len(points)-1].MeasuredAt.Sub(points[0].MeasuredAt) < minRateSpan {
+		return 0, 0, false
+	}
+
+	origin := points[0].MeasuredAt
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.MeasuredAt.Sub(origin).Hours()
+		sumX += x
+		sumY += p.WeightKg
+		sumXY += x * p.WeightKg
+		sumXX += x * x
+	}
+
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	last := points[len(points)-1].MeasuredAt.Sub(origin).Hours()
+	return intercept + slope*last, slope, true
+}
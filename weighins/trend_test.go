@@ -0,0 +1,242 @@
+package weighins
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grez-lucas/boxer66-service/internal/repository"
+)
+
+var firstWeighIn = time.Date(2026, 3, 2, 7, 0, 0, 0, time.UTC)
+
+// dailyWeighIns returns one weigh-in a day, starting at firstWeighIn.
+func dailyWeighIns(weights ...float64) []repository.WeighIn {
+	weighIns := make([]repository.WeighIn, len(weights))
+	for i, kg := range weights {
+		weighIns[i] = repository.WeighIn{WeightKg: kg, MeasuredAt: firstWeighIn.AddDate(0, 0, i)}
+	}
+	return weighIns
+}
+
+func day(n int) time.Time {
+	return firstWeighIn.AddDate(0, 0, n)
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func checkFloat(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", name, formatFloat(got), formatFloat(want))
+	case math.IsNaN(*got) || math.Abs(*got-*want) > 1e-9:
+		t.Errorf("%s = %v, want %v", name, *got, *want)
+	}
+}
+
+func formatFloat(v *float64) any {
+	if v == nil {
+		return "nil"
+	}
+	return *v
+}
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		weighIns []repository.WeighIn
+		since    time.Time
+		want     float64
+	}{
+		{name: "every weigh-in", weighIns: dailyWeighIns(80, 81, 82), since: day(0), want: 81},
+		{name: "older weigh-ins are left out", weighIns: dailyWeighIns(90, 80, 81), since: day(1), want: 80.5},
+		{name: "a weigh-in at the start counts", weighIns: dailyWeighIns(90, 80), since: day(1), want: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := movingAverage(tt.weighIns, tt.since); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("movingAverage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name       string
+		weighIns   []repository.WeighIn
+		since      time.Time
+		wantFitted float64
+		// wantSlope is in kg per day
+		wantSlope float64
+		wantOK    bool
+	}{
+		{
+			name:       "steady loss",
+			weighIns:   dailyWeighIns(80, 79, 78, 77, 76),
+			since:      day(0),
+			wantFitted: 76,
+			wantSlope:  -1,
+			wantOK:     true,
+		},
+		{
+			name:       "noise around a flat line",
+			weighIns:   dailyWeighIns(80, 82, 80, 82, 80),
+			since:      day(0),
+			wantFitted: 80.8,
+			wantSlope:  0,
+			wantOK:     true,
+		},
+		{
+			name:       "older weigh-ins are left out",
+			weighIns:   dailyWeighIns(95, 80, 79, 78),
+			since:      day(1),
+			wantFitted: 78,
+			wantSlope:  -1,
+			wantOK:     true,
+		},
+		{name: "a single weigh-in", weighIns: dailyWeighIns(80), since: day(0)},
+		{name: "weigh-ins spanning less than two days", weighIns: dailyWeighIns(80, 79), since: day(0)},
+		{name: "too few recent weigh-ins", weighIns: dailyWeighIns(80, 79, 78), since: day(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fitted, slope, ok := fit(tt.weighIns, tt.since)
+			if ok != tt.wantOK {
+				t.Fatalf("fit() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(fitted-tt.wantFitted) > 1e-9 {
+				t.Errorf("fit() fitted = %v, want %v", fitted, tt.wantFitted)
+			}
+			if perDay := slope * 24; math.Abs(perDay-tt.wantSlope) > 1e-9 {
+				t.Errorf("fit() slope = %v kg/day, want %v", perDay, tt.wantSlope)
+			}
+		})
+	}
+}
+
+func TestComputeTrend(t *testing.T) {
+	const safeCutPercent = 5
+
+	losing := dailyWeighIns(70, 69.5, 69, 68.5, 68)
+	gaining := dailyWeighIns(70, 70.5, 71, 71.5, 72)
+	now := day(4)
+	target := func(kg float64, daysToFight int) *repository.WeightTarget {
+		return &repository.WeightTarget{TargetKg: kg, FightDate: now.AddDate(0, 0, daysToFight)}
+	}
+
+	tests := []struct {
+		name          string
+		weighIns      []repository.WeighIn
+		target        *repository.WeightTarget
+		wantAverage   *float64
+		wantKgPerWeek *float64
+		wantProjected *float64
+		wantCut       *float64
+		wantUnsafe    bool
+	}{
+		{name: "no weigh-ins", target: target(66, 7)},
+		{
+			name:          "no target",
+			weighIns:      losing,
+			wantAverage:   ptr(69),
+			wantKgPerWeek: ptr(-3.5),
+		},
+		{
+			name:          "fight already happened",
+			weighIns:      losing,
+			target:        target(66, -1),
+			wantAverage:   ptr(69),
+			wantKgPerWeek: ptr(-3.5),
+		},
+		{
+			name:          "on track to make weight",
+			weighIns:      losing,
+			target:        target(66, 2),
+			wantAverage:   ptr(69),
+			wantKgPerWeek: ptr(-3.5),
+			wantProjected: ptr(67),
+			wantCut:       ptr(1.0 / 67 * 100),
+		},
+		{
+			name:          "projected under the target",
+			weighIns:      losing,
+			target:        target(66, 7),
+			wantAverage:   ptr(69),
+			wantKgPerWeek: ptr(-3.5),
+			wantProjected: ptr(64.5),
+			wantCut:       ptr(0),
+		},
+		{
+			// Extrapolating to fight day would project a negative weight
+			name:          "losing with the fight months away",
+			weighIns:      losing,
+			target:        target(66, 180),
+			wantAverage:   ptr(69),
+			wantKgPerWeek: ptr(-3.5),
+			wantProjected: ptr(54),
+			wantCut:       ptr(0),
+		},
+		{
+			name:          "gaining before the fight",
+			weighIns:      gaining,
+			target:        target(66, 7),
+			wantAverage:   ptr(71),
+			wantKgPerWeek: ptr(3.5),
+			wantProjected: ptr(75.5),
+			wantCut:       ptr(9.5 / 75.5 * 100),
+			wantUnsafe:    true,
+		},
+		{
+			name:          "gaining with the fight months away",
+			weighIns:      gaining,
+			target:        target(66, 180),
+			wantAverage:   ptr(71),
+			wantKgPerWeek: ptr(3.5),
+			wantProjected: ptr(86),
+			wantCut:       ptr(20.0 / 86 * 100),
+			wantUnsafe:    true,
+		},
+		{
+			name:          "projected at zero",
+			weighIns:      dailyWeighIns(70, 60, 50),
+			target:        target(66, 3),
+			wantAverage:   ptr(60),
+			wantKgPerWeek: ptr(-70),
+			wantProjected: ptr(0),
+			wantCut:       ptr(0),
+		},
+		{
+			name:          "no rate assumes the average holds",
+			weighIns:      dailyWeighIns(70),
+			target:        target(66, 7),
+			wantAverage:   ptr(70),
+			wantProjected: ptr(70),
+			wantCut:       ptr(4.0 / 70 * 100),
+			wantUnsafe:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend := computeTrend(tt.weighIns, tt.target, safeCutPercent, now)
+
+			if len(tt.weighIns) > 0 && trend.Latest == nil {
+				t.Error("Latest = nil, want the last weigh-in")
+			}
+			checkFloat(t, "MovingAverage", trend.MovingAverage, tt.wantAverage)
+			checkFloat(t, "KgPerWeek", trend.KgPerWeek, tt.wantKgPerWeek)
+			checkFloat(t, "Projected", trend.Projected, tt.wantProjected)
+			checkFloat(t, "CutPercent", trend.CutPercent, tt.wantCut)
+			if trend.Unsafe != tt.wantUnsafe {
+				t.Errorf("Unsafe = %v, want %v", trend.Unsafe, tt.wantUnsafe)
+			}
+		})
+	}
+}
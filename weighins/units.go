@@ -0,0 +1,46 @@
+package weighins
+
+import (
+	"fmt"
+	"math"
+)
+
+// Unit is a unit of body weight. Weights are stored in kg and converted at
+// the edges.
+type Unit string
+
+const (
+	UnitKg Unit = "kg"
+	UnitLb Unit = "lb"
+
+	kgPerLb = 0.45359237
+)
+
+var ErrInvalidUnit = fmt.Errorf("unit must be %s or %s", UnitKg, UnitLb)
+
+// ParseUnit parses a unit, kg if empty.
+func ParseUnit(v string) (Unit, error) {
+	switch Unit(v) {
+	case "", UnitKg:
+		return UnitKg, nil
+	case UnitLb:
+		return UnitLb, nil
+	}
+	return "", ErrInvalidUnit
+}
+
+// ToKg converts a weight in u to kg.
+func (u Unit) ToKg(weight float64) float64 {
+	if u == UnitLb {
+		return weight * kgPerLb
+	}
+	return weight
+}
+
+// FromKg converts a weight in kg to u, rounded to 0.1.
+func (u Unit) FromKg(kg float64) float64 {
+	if u == UnitLb {
+		kg /= kgPerLb
+	}
+	return math.Round(kg*10) / 10
+}
@@ -0,0 +1,76 @@
+package weighins
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Unit
+		wantErr error
+	}{
+		{value: "", want: UnitKg},
+		{value: "kg", want: UnitKg},
+		{value: "lb", want: UnitLb},
+		{value: "LB", wantErr: ErrInvalidUnit},
+		{value: "stone", wantErr: ErrInvalidUnit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseUnit(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseUnit() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUnit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitConversion(t *testing.T) {
+	tests := []struct {
+		name   string
+		unit   Unit
+		weight float64
+		wantKg float64
+	}{
+		{name: "kg", unit: UnitKg, weight: 70.4, wantKg: 70.4},
+		{name: "lb", unit: UnitLb, weight: 100, wantKg: 45.359237},
+		{name: "lb with a decimal", unit: UnitLb, weight: 154.3, wantKg: 69.9893027},
+		{name: "heavyweight lb", unit: UnitLb, weight: 265.9, wantKg: 120.6102112},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kg := tt.unit.ToKg(tt.weight)
+			if diff := kg - tt.wantKg; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("ToKg(%v) = %v, want %v", tt.weight, kg, tt.wantKg)
+			}
+			if got := tt.unit.FromKg(kg); got != tt.weight {
+				t.Errorf("FromKg(ToKg(%v)) = %v, want the weight back", tt.weight, got)
+			}
+		})
+	}
+}
+
+func TestFromKgRounds(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		kg   float64
+		want float64
+	}{
+		{unit: UnitKg, kg: 70.04, want: 70},
+		{unit: UnitKg, kg: 70.06, want: 70.1},
+		{unit: UnitLb, kg: 70, want: 154.3},
+	}
+
+	for _, tt := range tests {
+		if got := tt.unit.FromKg(tt.kg); got != tt.want {
+			t.Errorf("%s FromKg(%v) = %v, want %v", tt.unit, tt.kg, got, tt.want)
+		}
+	}
+}